@@ -0,0 +1,498 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+// invoice is a payload for billing notifications.
+type invoice struct {
+	number string
+	cents  int
+}
+
+// alert is a payload for operational notifications. Its String method
+// lets it use the Stringer-constrained email notifier.
+type alert struct {
+	service string
+	down    bool
+}
+
+func (a alert) String() string {
+	if a.down {
+		return a.service + " is down"
+	}
+	return a.service + " recovered"
+}
+
+// sampleCSV is a recipient import with one admin, two users and two rows
+// that fail validation.
+const sampleCSV = `name,email,level
+"Jones, Janet",janet@email.com,super
+Bill Smith,bill@email.com,
+Ann Lee,ann@email.com,
+Nobody,not-an-email,
+Bill Again,bill@email.com,
+`
+
+// samplePreferences holds each demo recipient's preferred channels.
+//
+//go:embed preferences.json
+var samplePreferences []byte
+
+// sampleConfig is a pipeline config for Bill with a misspelt key, which
+// LoadConfig warns about rather than rejecting.
+const sampleConfig = `{
+  "channel": "email",
+  "recipient": {"name": "Bill Smith", "email": "bill@email.com"},
+  "rate_limit": {"per_second": 10, "burst": 2},
+  "retry": {"attempts": 2, "base_dealy": "50ms"},
+  "timeout": "1s"
+}`
+
+// runDemo walks through the notify package feature by feature, printing
+// each result to standard output.
+func runDemo() {
+	ctx := context.Background()
+
+	user, err := notify.NewUser("Janet Jones", "janet@email.com")
+	if err != nil {
+		log.Fatal(err)
+	}
+	admin, err := notify.NewAdmin(user.Name, user.Email,
+		notify.WithLevel(notify.LevelSuper),
+		notify.WithCC("oncall@email.com"),
+		notify.WithLocale("en-GB"),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(admin)
+	notify.SendNotification(ctx, admin)
+	admin.User.Notify(ctx)
+	admin.Notify(ctx)
+
+	// A closure satisfies Notifier once converted to NotifierFunc, and
+	// its error comes back from SendNotification unchanged.
+	err = notify.SendNotification(ctx, notify.NotifierFunc(func(context.Context) error {
+		return errors.New("pager offline")
+	}))
+	fmt.Println("NotifierFunc returned:", err)
+
+	// A cancelled context short-circuits the notification before anything
+	// is sent.
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	err = notify.SendNotification(cancelled, admin)
+	fmt.Println("Cancelled context returned:", err)
+
+	// The same admin can render different templates, such as an urgent
+	// page versus the everyday message.
+	for _, text := range []string{
+		notify.DefaultAdminTemplate,
+		"URGENT for {{upper .Name}} ({{.Level}}): the pager is offline",
+	} {
+		msg, err := admin.Render(text)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(msg)
+	}
+
+	// An HTML template escapes what the plain-text message shows verbatim.
+	mallory, err := notify.NewUser("<b>Mallory</b>", "mallory@email.com",
+		notify.WithHTMLTemplate("<p>Hello {{.Name}}</p>"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	body, err := mallory.Body()
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("Plain: %s\nHTML: %s\n", body.Plain, body.HTML)
+
+	// A template parsed elsewhere can be set afterwards; removing it goes
+	// back to plain text only.
+	mallory.SetHTMLTemplate(htmltemplate.Must(htmltemplate.New("email").Parse(`<p>Dear {{.Name}} ({{.Email}})</p>`)))
+	if body, err = mallory.Body(); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println("HTML:", body.HTML)
+	mallory.SetHTMLTemplate(nil)
+	if body, err = mallory.Body(); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("HTML after removing the template: %q\n", body.HTML)
+
+	// The same admin can be notified in different locales. Regional tags
+	// fall back to their base language and unknown locales to English.
+	for _, tag := range []string{"es", "de-AT", "ja"} {
+		localized, err := notify.NewAdmin(admin.Name, admin.Email,
+			notify.WithLevel(admin.Level),
+			notify.WithLocale(tag),
+		)
+		if err != nil {
+			log.Fatal(err)
+		}
+		localized.Notify(ctx)
+	}
+
+	// The embedded User's fields are flattened into the admin's JSON, with
+	// the email masked.
+	data, err := json.Marshal(admin)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(string(data))
+
+	// Middleware composes around any Notifier: the logging wrapper sees the
+	// call first and records how long the measured admin took.
+	var metrics notify.Metrics
+	chained := notify.Chain(admin, notify.Logging(os.Stdout), notify.Measure(&metrics))
+	notify.SendNotification(ctx, chained)
+	snap := metrics.Snapshot()
+	fmt.Printf("Metrics: %d sent, %d failed\n", snap.Sent, snap.Failed)
+
+	// The same counters in the Prometheus text format, as a scrape of
+	// -metrics-addr would see them.
+	scrape := httptest.NewRecorder()
+	notify.MetricsHandler(metrics.Snapshot).ServeHTTP(scrape, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	for line := range strings.Lines(scrape.Body.String()) {
+		if strings.HasPrefix(line, "notify_total") {
+			fmt.Print(line)
+		}
+	}
+
+	// A rate limit of 5 a second with bursts of 2 lets the first two
+	// notifications through at once and spaces out the rest.
+	limited := notify.NewRateLimitedNotifier(admin, 5, 2)
+	start := time.Now()
+	for i := 1; i <= 10; i++ {
+		if err := notify.SendNotification(ctx, limited); err != nil {
+			fmt.Println(err)
+		}
+		fmt.Printf("Rate limited send %d at %s\n", i, time.Since(start).Round(10*time.Millisecond))
+	}
+
+	// Typed payloads ride alongside the untyped Notifier path; sending an
+	// alert to the invoice notifier would not compile.
+	invoices := &notify.EmailNotifier[invoice]{
+		To: user,
+		Format: func(inv invoice) string {
+			return fmt.Sprintf("invoice %s for $%d.%02d", inv.number, inv.cents/100, inv.cents%100)
+		},
+	}
+	alerts := notify.NewStringerEmail[alert](user)
+	notify.SendTyped(ctx, invoices, invoice{number: "INV-1001", cents: 12999})
+	notify.SendTyped(ctx, alerts, alert{service: "billing", down: true})
+
+	// The scheduler sends the admin notification every 2 seconds until it
+	// is stopped 6 seconds later. A manager owns it and a queue, stopping
+	// them in reverse order with at most a second each.
+	scheduler := notify.NewScheduler(nil)
+	queue := notify.NewQueue(3)
+	components := notify.Manager{StopTimeout: time.Second}
+	components.Register(queue)
+	components.Register(scheduler)
+	if err := components.StartAll(ctx); err != nil {
+		log.Fatal(err)
+	}
+	scheduler.Every(admin, 2*time.Second)
+	queue.Enqueue(&admin.User)
+	time.Sleep(6*time.Second + 100*time.Millisecond)
+	if err := components.StopAll(ctx); err != nil {
+		fmt.Println(err)
+	}
+
+	// A delegate adds a second level of embedding: its Notify shadows the
+	// admin's, which shadows the user's, yet the level is still promoted.
+	// With an unusable delegate address the original user is notified
+	// instead.
+	bill, err := notify.NewUser("Bill Smith", "bill@email.com")
+	if err != nil {
+		log.Fatal(err)
+	}
+	delegate, err := notify.NewDelegate(admin, bill)
+	if err != nil {
+		log.Fatal(err)
+	}
+	notify.SendNotification(ctx, delegate)
+	broken, err := notify.NewDelegate(&notify.Admin{User: notify.User{Name: "Nobody"}, Level: notify.LevelNormal}, bill)
+	if err != nil {
+		log.Fatal(err)
+	}
+	notify.SendNotification(ctx, broken)
+
+	// NotifyAsync starts a send in the background; the result is collected
+	// once there's nothing else to do.
+	pending := notify.NotifyAsync(ctx, bill)
+	if err := <-pending; err != nil {
+		fmt.Println(err)
+	}
+
+	// A request ID in the context is appended to the line and logged, so
+	// the notification can be traced back to what triggered it.
+	bill.Notify(notify.WithRequestID(ctx, "req-7f3a"))
+
+	// Promote and Demote copy the embedded user rather than changing it,
+	// so Bill is still a plain user afterwards.
+	promoted, err := bill.Promote(notify.LevelSuper)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println("Promoted:", promoted, "demoted:", promoted.Demote(), "original:", bill)
+
+	// A conditional notifier checks its condition on every call, so Bill
+	// is only notified while maintenance mode is off.
+	maintenance := true
+	guarded := notify.NewConditionalNotifier(bill, func(context.Context) bool { return !maintenance })
+	notify.SendNotification(ctx, guarded)
+	maintenance = false
+	notify.SendNotification(ctx, guarded)
+	fmt.Println("Skipped during maintenance:", guarded.Skipped())
+
+	// Hooks observe a send without wrapping the notifier.
+	var hooks notify.Hooks
+	hooks.AfterNotify(func(_ context.Context, n notify.Notifier, err error, elapsed time.Duration) {
+		recipient, _ := notify.AddressOf(n)
+		fmt.Printf("Hook: %s done after %s, error: %v\n", notify.MaskEmail(recipient), elapsed.Round(time.Millisecond), err)
+	})
+	notify.SendWithHooks(ctx, bill, &hooks)
+
+	// Five alerts in quick succession are debounced into a single email,
+	// sent once they stop for 100ms.
+	debounced := notify.NewDebounceNotifier(bill, 100*time.Millisecond, nil)
+	for range 5 {
+		debounced.Notify(ctx)
+		time.Sleep(10 * time.Millisecond)
+	}
+	debounced.Wait()
+
+	// The audit trail is one JSON line per attempt, successful or not.
+	audit := notify.NewAuditLog(os.Stdout)
+	for _, n := range []notify.Notifier{bill, &notify.SMSUser{User: *bill, Phone: "555-0101"}} {
+		(&notify.AuditNotifier{Notifier: n, Log: audit}).Notify(ctx)
+	}
+
+	// Replaying the same idempotency key returns the first result instead
+	// of emailing Bill again.
+	idempotent := notify.NewIdempotentSender(notify.NewMemoryStore(time.Hour, nil))
+	for range 2 {
+		if _, err := idempotent.Send(ctx, "welcome-bill", bill); err != nil {
+			fmt.Println(err)
+		}
+	}
+
+	// A size limit truncates the message at a character boundary, or in
+	// reject mode refuses to send it at all.
+	for _, mode := range []notify.LimitMode{notify.LimitTruncate, notify.LimitReject} {
+		limited := notify.NewLimitingNotifier(bill, notify.Limits{MaxBodyBytes: 32}, mode)
+		if err := notify.SendNotification(ctx, limited); err != nil {
+			fmt.Println(err)
+		}
+	}
+
+	// Once Janet opts out, neither her user nor her admin role is sent to.
+	var optOuts notify.OptOutRegistry
+	optOuts.OptOut("janet@EMAIL.com")
+	for _, n := range []notify.Notifier{&admin.User, admin} {
+		if err := notify.SendNotification(ctx, notify.NewOptOutNotifier(n, &optOuts)); err != nil {
+			fmt.Println(err)
+		}
+	}
+
+	// Escalation starts with Bill's phone, which is malformed, and moves
+	// on to the admin, who is notified at the second step.
+	chain := notify.EscalationChain{
+		Steps: []notify.Notifier{
+			&notify.SMSUser{User: *bill, Phone: "555-0101"},
+			admin,
+			&notify.SlackUser{User: admin.User, WebhookURL: "https://hooks.slack.com/services/T000/B000/XXXX", Channel: "#oncall"},
+		},
+		StepTimeout: time.Second,
+		Deadline:    5 * time.Second,
+	}
+	if result, err := chain.Escalate(ctx); err != nil {
+		fmt.Println(err)
+	} else {
+		fmt.Printf("Escalated to step %d after %d failure(s)\n", result.Step, len(result.Failures))
+	}
+
+	// With the webhook receiver down, delivery falls back to the console,
+	// and the result says which notifier got it through.
+	hook := &notify.WebhookNotifier{URL: "http://127.0.0.1:1/hook", To: bill, MaxAttempts: 1}
+	result, _ := notify.Send(ctx, notify.Fallback(hook, &notify.ConsoleNotifier{For: hook}))
+	fmt.Printf("Delivered by %T\n", result.DeliveredBy)
+
+	// Janet prefers SMS, but her number is malformed, so the preferring
+	// notifier falls through to email.
+	var prefs notify.Preferences
+	if err := prefs.Load(bytes.NewReader(samplePreferences)); err != nil {
+		log.Fatal(err)
+	}
+	preferring, err := notify.NewPreferringNotifier(&prefs,
+		&admin.User,
+		&notify.SMSUser{User: admin.User, Phone: "555-0100"},
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+	notify.SendNotification(ctx, preferring)
+
+	// A pipeline can come from a config file: LoadConfig fills in defaults
+	// and flags unknown keys, and BuildPipeline wraps the channel in the
+	// configured rate limit, retry and timeout.
+	cfg, err := notify.LoadConfig(strings.NewReader(sampleConfig))
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, w := range cfg.Warnings {
+		fmt.Println("config warning:", w)
+	}
+	pipeline, err := notify.BuildPipeline(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	notify.SendNotification(ctx, pipeline)
+
+	// A CSV import keeps the good rows, reports the bad ones by line, and
+	// hands everyone it could read to the batch sender.
+	imported, problems := notify.LoadUsersFromCSV(strings.NewReader(sampleCSV))
+	for _, p := range problems {
+		fmt.Println(p)
+	}
+	for _, err := range notify.SendBatch(imported, 2) {
+		if err != nil {
+			fmt.Println(err)
+		}
+	}
+
+	// Partition splits the import in one pass; the type switch sees
+	// through the Notifier interface to the concrete recipient.
+	admins, users := notify.Partition(imported, func(n notify.Notifier) bool {
+		switch n.(type) {
+		case *notify.Admin:
+			return true
+		default:
+			return false
+		}
+	})
+	fmt.Printf("Imported %d admin(s) and %d user(s)\n", len(admins), len(users))
+
+	// SendAll reports on every recipient; with dedup, Bill listed twice
+	// is only notified once, and the malformed number fails.
+	everyone := append(slices.Clone(imported), bill, &notify.SMSUser{User: *bill, Phone: "555-0101"})
+	report := notify.SendAll(ctx, everyone, notify.WithDedup(), notify.WithSendTimeout(time.Second))
+	fmt.Printf("SendAll: %d sent, %d failed, %d skipped\n", report.Sent, report.Failed, report.Skipped)
+	for _, o := range report.Outcomes {
+		if o.Status != notify.OutcomeSent {
+			fmt.Printf("  %s: %s (%v)\n", o.Recipient, o.Status, o.Err)
+		}
+	}
+
+	// A dead-letter queue keeps what a batch couldn't deliver; redelivering
+	// the malformed number fails again, so it stays queued.
+	var deadLetters notify.DeadLetterQueue
+	deadLetters.SendBatch(ctx, []notify.Notifier{bill, &notify.SMSUser{User: *bill, Phone: "555-0101"}}, 2)
+	remaining := deadLetters.Requeue(ctx, notify.SendNotification)
+	for _, letter := range deadLetters.Drain() {
+		fmt.Printf("Dead letter after %d attempts (%d remaining): %v\n", letter.Attempts, remaining, letter.Err)
+	}
+
+	// Filter works on any element type; here it picks out the super admins
+	// from a mixed population, already sorted by level.
+	var staff []*notify.Admin
+	for _, spec := range []struct {
+		name, email string
+		level       notify.Level
+	}{
+		{"Ann Lee", "ann@email.com", notify.LevelNormal},
+		{"Raj Patel", "raj@email.com", notify.LevelSuper},
+		{"Mia Chen", "mia@email.com", notify.LevelRoot},
+		{"Sam Ortiz", "sam@email.com", notify.LevelSuper},
+	} {
+		a, err := notify.NewAdmin(spec.name, spec.email, notify.WithLevel(spec.level))
+		if err != nil {
+			log.Fatal(err)
+		}
+		staff = append(staff, a)
+	}
+	notify.SortAdminsByLevel(staff)
+	supers := notify.Filter(staff, func(a *notify.Admin) bool { return a.Level == notify.LevelSuper })
+	notify.SendNotifications(ctx, notify.MapToNotifiers(supers)...)
+	fmt.Println("Most privileged:", notify.TopN(staff, 1)[0])
+
+	// Labels route a batch: only the on-call recipient is paged, whether
+	// or not it is wrapped.
+	var team []notify.Notifier
+	for _, spec := range []struct{ name, email, role string }{
+		{"Ann Lee", "ann@email.com", "on-call"},
+		{"Raj Patel", "raj@email.com", "backup"},
+	} {
+		u, err := notify.NewUser(spec.name, spec.email, notify.WithLabels(map[string]string{"role": spec.role}))
+		if err != nil {
+			log.Fatal(err)
+		}
+		team = append(team, notify.WithTimeout(u, time.Second))
+	}
+	notify.SendNotifications(ctx, notify.FilterByLabel(team, "role", "on-call")...)
+
+	// Notify has a pointer receiver, so only *Admin is a Notifier; an Admin
+	// value doesn't have the method in its method set.
+	for _, v := range []any{*admin, admin} {
+		_, why := notify.ImplementsNotifier(v)
+		fmt.Println(why)
+	}
+
+	// The method sets side by side: value receivers are in both the value
+	// and pointer method sets, pointer receivers only in the pointer's,
+	// and embedding a struct by value passes the same split on.
+	for _, v := range []any{*user, *admin, notify.Document{}, notify.SignedDocument{}} {
+		for _, line := range notify.DescribeMethodSet(v) {
+			fmt.Println(line)
+		}
+	}
+	doc := notify.SignedDocument{Document: notify.Document{Title: "Runbook"}, Signer: "Janet"}
+	doc.Write("restart the pager")
+	fmt.Println(doc.Read())
+
+	// Every kind of recipient satisfies Notifier, so one loop can deliver
+	// to all of them and each call dispatches to the right implementation.
+	recipients := []notify.Notifier{
+		&admin.User,
+		admin,
+		&notify.SMSUser{User: admin.User, Phone: "+15555550100"},
+		&notify.SlackUser{
+			User:       admin.User,
+			WebhookURL: "https://hooks.slack.com/services/T000/B000/XXXX",
+			Channel:    "#ops",
+		},
+		&notify.SMSUser{User: admin.User, Phone: "555-0100"},
+	}
+
+	// A dry run catches the malformed phone number before anything is sent.
+	for _, err := range notify.DryRun(recipients) {
+		if err != nil {
+			fmt.Println("Dry run:", err)
+		}
+	}
+	for _, n := range recipients {
+		if err := notify.SendNotification(ctx, n); err != nil {
+			fmt.Println(err)
+		}
+	}
+}
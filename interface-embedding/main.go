@@ -1,44 +1,179 @@
 package main
 
-import "fmt"
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
 
-type notifier interface {
-	notify() error
-}
+	"ultimate-golang-reference/interface-embedding/notify"
+)
 
-type user struct {
-	name  string
-	email string
-}
+// Exit codes reported by run.
+const (
+	exitOK       = 0
+	exitInvalid  = 1
+	exitDelivery = 2
+)
 
-func (u *user) notify() error {
-	fmt.Printf("Sending user email to %s<%s>\n", u.name, u.email)
-	return nil
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
 }
 
-type admin struct {
-	user
-	level string
+// config holds the parsed command line.
+type config struct {
+	name        string
+	email       string
+	level       string
+	channel     string
+	phone       string
+	webhook     string
+	slackChan   string
+	count       int
+	concurrency int
+	metricsAddr string
+	demo        bool
+	simulate    bool
+	seed        uint64
 }
 
-func (a *admin) notify() error {
-	fmt.Printf("Sending admin email to %s<%s> with level %s\n", a.name, a.email, a.level)
-	return nil
+// run parses args, builds the requested notifiers and sends them, writing
+// notifications to out and problems to errOut. It returns exitOK when every
+// notification is delivered, exitInvalid for bad flags or recipients, and
+// exitDelivery when any send fails.
+func run(args []string, out, errOut io.Writer) int {
+	fs := flag.NewFlagSet("interface-embedding", flag.ContinueOnError)
+	fs.SetOutput(errOut)
+
+	var cfg config
+	fs.StringVar(&cfg.name, "name", "", "recipient `name` (required)")
+	fs.StringVar(&cfg.email, "email", "", "recipient email `address` (required)")
+	fs.StringVar(&cfg.level, "level", "", "notify as an admin with this `level`: normal, super or root")
+	fs.StringVar(&cfg.channel, "channel", "email", "delivery `channel`: "+strings.Join(notify.Channels(), ", "))
+	fs.StringVar(&cfg.phone, "phone", "", "E.164 phone `number` for the sms channel")
+	fs.StringVar(&cfg.webhook, "webhook", "", "webhook `URL` for the slack and webhook channels")
+	fs.StringVar(&cfg.slackChan, "slack-channel", "#general", "Slack `channel` to post to")
+	fs.IntVar(&cfg.count, "count", 1, "`number` of notifications to send")
+	fs.IntVar(&cfg.concurrency, "concurrency", 1, "`number` of notifications to send at once")
+	fs.StringVar(&cfg.metricsAddr, "metrics-addr", "", "serve Prometheus metrics on `address`, such as :9090, until interrupted")
+	fs.BoolVar(&cfg.demo, "demo", false, "run the feature walkthrough instead of sending")
+	fs.BoolVar(&cfg.simulate, "simulate", false, "run a deterministic simulated scenario instead of sending")
+	fs.Uint64Var(&cfg.seed, "seed", 1, "random `seed` for -simulate")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitInvalid
+	}
+
+	if cfg.demo {
+		runDemo()
+		return exitOK
+	}
+	if cfg.simulate {
+		if err := runSimulation(out, cfg.seed); err != nil {
+			fmt.Fprintln(errOut, err)
+			return exitInvalid
+		}
+		return exitOK
+	}
+	if cfg.name == "" || cfg.email == "" {
+		fmt.Fprintln(errOut, "-name and -email are required")
+		fs.Usage()
+		return exitInvalid
+	}
+
+	ns, err := build(cfg, out)
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		if errors.Is(err, notify.ErrUnknownChannel) {
+			fs.Usage()
+		}
+		return exitInvalid
+	}
+
+	var metrics notify.Metrics
+	if cfg.metricsAddr != "" {
+		stop, err := serveMetrics(cfg.metricsAddr, &metrics, errOut)
+		if err != nil {
+			fmt.Fprintln(errOut, err)
+			return exitInvalid
+		}
+		defer stop()
+		for i, n := range ns {
+			ns[i] = &notify.MetricsNotifier{Notifier: n, Metrics: &metrics}
+		}
+	}
+
+	if err := notify.SendConcurrent(context.Background(), cfg.concurrency, ns); err != nil {
+		fmt.Fprintln(errOut, err)
+		return exitDelivery
+	}
+
+	fmt.Fprintf(out, "sent %d notification(s) via %s\n", len(ns), cfg.channel)
+	return exitOK
 }
 
-func sendNotification(n notifier) error {
-	return n.notify()
+// serveMetrics serves m at /metrics on addr in the background. The
+// returned stop function waits for an interrupt, so the final counts can
+// still be scraped once sending is done, and then shuts the server down.
+func serveMetrics(addr string, m *notify.Metrics, errOut io.Writer) (stop func(), err error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("-metrics-addr: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", notify.MetricsHandler(m.Snapshot))
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	go srv.Serve(ln)
+	fmt.Fprintf(errOut, "serving metrics on http://%s/metrics\n", ln.Addr())
+
+	return func() {
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer cancel()
+		fmt.Fprintln(errOut, "press Ctrl-C to stop serving metrics")
+		<-ctx.Done()
+
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdown)
+	}, nil
 }
 
-func main() {
-	admin := &admin{
-		user: user{
-			name:  "Janet Jones",
-			email: "janet@email.com",
-		},
-		level: "super",
-	}
-	sendNotification(admin)
-	admin.user.notify()
-	admin.notify()
+// build returns cfg.count notifiers for the configured recipient and
+// channel, each writing to out.
+func build(cfg config, out io.Writer) ([]notify.Notifier, error) {
+	if cfg.count < 1 {
+		return nil, fmt.Errorf("-count must be at least 1, got %d", cfg.count)
+	}
+	if cfg.concurrency < 1 {
+		return nil, fmt.Errorf("-concurrency must be at least 1, got %d", cfg.concurrency)
+	}
+
+	n, err := notify.NewNotifier(cfg.channel, map[string]string{
+		"name":    cfg.name,
+		"email":   cfg.email,
+		"level":   cfg.level,
+		"phone":   cfg.phone,
+		"url":     cfg.webhook,
+		"channel": cfg.slackChan,
+	}, notify.WithWriter(out))
+	if err != nil {
+		return nil, err
+	}
+
+	ns := make([]notify.Notifier, cfg.count)
+	for i := range ns {
+		ns[i] = n
+	}
+
+	return ns, nil
 }
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// quietLogs discards the default logger's output for the rest of the test.
+func quietLogs(t *testing.T) {
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.DiscardHandler))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+}
+
+func TestRun(t *testing.T) {
+	quietLogs(t)
+	rejecting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "no such hook", http.StatusNotFound)
+	}))
+	defer rejecting.Close()
+
+	tests := []struct {
+		name       string
+		args       []string
+		want       int
+		wantOut    string
+		wantErrOut string
+	}{
+		{"user", []string{"-name", "Janet Jones", "-email", "janet@email.com"}, exitOK,
+			"Sending user email to Janet Jones<janet@email.com>\nsent 1 notification(s) via email\n", ""},
+		{"admin", []string{"-name", "Lisa Smith", "-email", "lisa@email.com", "-level", "root"}, exitOK,
+			"Sending admin email to Lisa Smith<lisa@email.com> with level root\nsent 1 notification(s) via email\n", ""},
+		{"sms", []string{"-name", "Janet Jones", "-email", "janet@email.com", "-channel", "sms", "-phone", "+15555550100"}, exitOK,
+			"Sending user SMS to Janet Jones<+15555550100>\nsent 1 notification(s) via sms\n", ""},
+		{"count and concurrency", []string{"-name", "Janet Jones", "-email", "janet@email.com", "-count", "3", "-concurrency", "2"}, exitOK,
+			strings.Repeat("Sending user email to Janet Jones<janet@email.com>\n", 3) + "sent 3 notification(s) via email\n", ""},
+		{"help", []string{"-h"}, exitOK, "", "Usage of"},
+		{"missing name", []string{"-email", "janet@email.com"}, exitInvalid, "", "-name and -email are required\nUsage of"},
+		{"missing email", []string{"-name", "Janet Jones"}, exitInvalid, "", "-name and -email are required\nUsage of"},
+		{"unknown flag", []string{"-nope"}, exitInvalid, "", "flag provided but not defined"},
+		{"invalid email", []string{"-name", "Janet Jones", "-email", "janet"}, exitInvalid, "", "email"},
+		{"invalid level", []string{"-name", "Lisa Smith", "-email", "lisa@email.com", "-level", "god"}, exitInvalid, "", "level"},
+		{"unknown channel", []string{"-name", "Janet Jones", "-email", "janet@email.com", "-channel", "fax"}, exitInvalid, "", "unknown channel"},
+		{"sms without phone", []string{"-name", "Janet Jones", "-email", "janet@email.com", "-channel", "sms"}, exitInvalid, "", "phone"},
+		{"zero count", []string{"-name", "Janet Jones", "-email", "janet@email.com", "-count", "0"}, exitInvalid, "", "-count must be at least 1"},
+		{"zero concurrency", []string{"-name", "Janet Jones", "-email", "janet@email.com", "-concurrency", "0"}, exitInvalid, "", "-concurrency must be at least 1"},
+		{"bad metrics address", []string{"-name", "Janet Jones", "-email", "janet@email.com", "-metrics-addr", "256.0.0.1:http"}, exitInvalid, "", "-metrics-addr"},
+		{"delivery failure", []string{"-name", "Janet Jones", "-email", "janet@email.com", "-channel", "webhook", "-webhook", rejecting.URL}, exitDelivery, "", "404"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out, errOut bytes.Buffer
+			if got := run(tt.args, &out, &errOut); got != tt.want {
+				t.Errorf("run() = %d, want %d; stderr:\n%s", got, tt.want, errOut.String())
+			}
+			if got := out.String(); got != tt.wantOut {
+				t.Errorf("stdout = %q, want %q", got, tt.wantOut)
+			}
+			if !strings.Contains(errOut.String(), tt.wantErrOut) {
+				t.Errorf("stderr = %q, want it to contain %q", errOut.String(), tt.wantErrOut)
+			}
+		})
+	}
+}
+
+func TestRunUnknownChannelUsage(t *testing.T) {
+	quietLogs(t)
+	var out, errOut bytes.Buffer
+	if got := run([]string{"-name", "Janet Jones", "-email", "janet@email.com", "-channel", "fax"}, &out, &errOut); got != exitInvalid {
+		t.Fatalf("run() = %d, want %d", got, exitInvalid)
+	}
+
+	// The error names the registered channels, and the usage that follows
+	// documents every flag.
+	stderr := errOut.String()
+	for _, want := range []string{`unknown channel "fax"`, "email, slack, sms, webhook", "Usage of", "-channel", "-name", "-email"} {
+		if !strings.Contains(stderr, want) {
+			t.Errorf("stderr = %q, want it to contain %q", stderr, want)
+		}
+	}
+	if out.Len() != 0 {
+		t.Errorf("stdout = %q, want nothing before sending", out.String())
+	}
+}
+
+func TestRunSimulationDeterministic(t *testing.T) {
+	for _, seed := range []uint64{1, 2, 42} {
+		t.Run(fmt.Sprint("seed ", seed), func(t *testing.T) {
+			var first, second bytes.Buffer
+			if err := runSimulation(&first, seed); err != nil {
+				t.Fatalf("runSimulation() = %v", err)
+			}
+			if err := runSimulation(&second, seed); err != nil {
+				t.Fatalf("runSimulation() = %v", err)
+			}
+			if !bytes.Equal(first.Bytes(), second.Bytes()) {
+				t.Fatalf("two runs with seed %d differ:\n%s\n---\n%s", seed, first.String(), second.String())
+			}
+
+			// Every line is stamped with a simulated time that never goes
+			// backwards, and the scenario runs to the end.
+			lines := strings.Split(strings.TrimSuffix(first.String(), "\n"), "\n")
+			var last time.Time
+			for i, line := range lines {
+				at, err := time.Parse("2006-01-02 15:04:05.000", line[:min(len(line), 23)])
+				if err != nil {
+					t.Fatalf("line %d = %q, want it to start with a timestamp", i+1, line)
+				}
+				if at.Before(last) {
+					t.Errorf("line %d = %q, earlier than the line before it", i+1, line)
+				}
+				last = at
+			}
+			if want := fmt.Sprintf("seed %d", seed); !strings.HasSuffix(lines[0], want) {
+				t.Errorf("first line = %q, want it to name %s", lines[0], want)
+			}
+			if !strings.Contains(lines[len(lines)-1], "done: ") {
+				t.Errorf("last line = %q, want the totals", lines[len(lines)-1])
+			}
+			for _, phase := range []string{"burst", "gateway outage", "recovery", "duplicates", "quiet hours"} {
+				if !strings.Contains(first.String(), "phase: "+phase) {
+					t.Errorf("log has no %s phase", phase)
+				}
+			}
+		})
+	}
+}
+
+func TestRunSimulationSeeds(t *testing.T) {
+	var one, two bytes.Buffer
+	if err := runSimulation(&one, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := runSimulation(&two, 2); err != nil {
+		t.Fatal(err)
+	}
+	// Past the first line, which names the seed, the failures and
+	// timings come from the seed too.
+	_, restOne, _ := strings.Cut(one.String(), "\n")
+	_, restTwo, _ := strings.Cut(two.String(), "\n")
+	if restOne == restTwo {
+		t.Error("seeds 1 and 2 produced the same log, want the seed to drive it")
+	}
+}
+
+func TestRunSimulateFlag(t *testing.T) {
+	var want bytes.Buffer
+	if err := runSimulation(&want, 7); err != nil {
+		t.Fatal(err)
+	}
+
+	var out, errOut bytes.Buffer
+	if got := run([]string{"-simulate", "-seed", "7"}, &out, &errOut); got != exitOK {
+		t.Fatalf("run(-simulate) = %d, want %d; stderr:\n%s", got, exitOK, errOut.String())
+	}
+	if out.String() != want.String() {
+		t.Errorf("run(-simulate -seed 7) printed\n%s\nwant the log runSimulation writes for seed 7\n%s", out.String(), want.String())
+	}
+}
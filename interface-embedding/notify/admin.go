@@ -0,0 +1,208 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+)
+
+// ErrEmptyLevel is returned when an admin is given an empty level.
+var ErrEmptyLevel = errors.New("notify: level is required")
+
+// ErrSelfCC is returned when an admin is copied on their own email.
+var ErrSelfCC = invalidRecipient(errors.New("notify: admin is copied on their own email"))
+
+// Admin is a User with elevated privileges. Embedding User promotes its
+// fields and its Notify method, which Admin then overrides. Because the
+// embedded User has no JSON tag of its own, encoding/json flattens its
+// fields into the admin object rather than nesting them when decoding.
+type Admin struct {
+	User
+	Level Level    `json:"level"`
+	CC    []string `json:"cc,omitempty"`
+}
+
+// AdminOption configures an Admin in NewAdmin. Every UserOption is also an
+// AdminOption, applied to the embedded User.
+type AdminOption interface {
+	applyAdmin(*Admin) error
+}
+
+// applyAdmin lets a UserOption configure the User embedded in an Admin.
+func (o UserOption) applyAdmin(a *Admin) error {
+	return o(&a.User)
+}
+
+// adminOptionFunc is an AdminOption that only makes sense for admins.
+type adminOptionFunc func(*Admin) error
+
+func (f adminOptionFunc) applyAdmin(a *Admin) error {
+	return f(a)
+}
+
+// NewAdmin returns an Admin after validating and normalizing name and email
+// as NewUser does, then applies opts in order. Admins default to
+// LevelNormal and the en-US locale. Options that conflict once combined,
+// such as copying the admin on their own email, are rejected after all of
+// them have been applied.
+func NewAdmin(name, email string, opts ...AdminOption) (*Admin, error) {
+	u, err := NewUser(name, email)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &Admin{User: *u, Level: LevelNormal}
+	for _, opt := range opts {
+		if err := opt.applyAdmin(a); err != nil {
+			return nil, err
+		}
+	}
+	if err := a.checkSelfCC(); err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// checkSelfCC returns ErrSelfCC if the admin is copied on their own email.
+// NewAdmin and Validate both call it.
+func (a *Admin) checkSelfCC() error {
+	if slices.Contains(a.CC, a.Email) {
+		return fmt.Errorf("%w %q", ErrSelfCC, a.Email)
+	}
+
+	return nil
+}
+
+// WithLevel sets the admin's level, which must be one of the declared
+// levels.
+func WithLevel(l Level) AdminOption {
+	return adminOptionFunc(func(a *Admin) error {
+		if l == "" {
+			return ErrEmptyLevel
+		}
+		if err := l.Validate(); err != nil {
+			return err
+		}
+		a.Level = l
+
+		return nil
+	})
+}
+
+// WithCC adds addresses to copy on the admin's email. Each one is
+// normalized with NormalizeEmail.
+func WithCC(emails ...string) AdminOption {
+	return adminOptionFunc(func(a *Admin) error {
+		for _, email := range emails {
+			addr, err := NormalizeEmail(email)
+			if err != nil {
+				return fmt.Errorf("cc: %w", err)
+			}
+			a.CC = append(a.CC, addr)
+		}
+
+		return nil
+	})
+}
+
+// Notify sends the admin an email that includes their level.
+func (a *Admin) Notify(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return &NotificationError{Recipient: a.Email, Channel: "email", Err: err}
+	}
+	if _, err := NormalizeEmail(a.Email); err != nil {
+		return &NotificationError{Recipient: a.Email, Channel: "email", Err: err}
+	}
+
+	buf := lineBufs.Get().(*[]byte)
+	defer lineBufs.Put(buf)
+
+	line, err := a.appendMessage((*buf)[:0])
+	if err != nil {
+		return &NotificationError{Recipient: a.Email, Channel: "email", Err: err}
+	}
+
+	line, err = limitLine(ctx, a.appendAttachments(line))
+	*buf = line
+	if err != nil {
+		return &NotificationError{Recipient: a.Email, Channel: "email", Err: err}
+	}
+
+	if err := a.writeBuf(ctx, buf); err != nil {
+		return &NotificationError{Recipient: a.Email, Channel: "email", Retryable: true, Err: err}
+	}
+	a.logSent(ctx,
+		slog.String("name", a.Name), slog.String("email", a.Email), slog.String("channel", "email"),
+		slog.String("level", string(a.Level)))
+	return nil
+}
+
+// message renders the admin's notification from the template set with
+// WithTemplate, or from the catalog in the admin's locale. It shadows the
+// promoted User.message so the level is included.
+func (a *Admin) message() (string, error) {
+	if a.tmpl != "" {
+		return a.Render(a.tmpl)
+	}
+
+	return a.localize(KeyAdminEmail, Sanitize(a.Name), a.Email, string(a.Level))
+}
+
+// appendMessage is message, appending the notification to buf. It shadows
+// the promoted User.appendMessage.
+func (a *Admin) appendMessage(buf []byte) ([]byte, error) {
+	if a.tmpl != "" {
+		msg, err := a.Render(a.tmpl)
+		return append(buf, msg...), err
+	}
+
+	return a.appendLocalized(buf, KeyAdminEmail, Sanitize(a.Name), a.Email, string(a.Level))
+}
+
+// Clone returns an independent copy of the admin, cloning the embedded
+// User and the CC list. Shared resources are shared as in User.Clone.
+func (a *Admin) Clone() *Admin {
+	c := *a
+	c.User = *a.User.Clone()
+	c.CC = slices.Clone(a.CC)
+
+	return &c
+}
+
+// Promote returns an Admin at the given level embedding a copy of u,
+// leaving u itself unchanged. The level is checked as WithLevel does.
+func (u *User) Promote(level Level) (*Admin, error) {
+	a := &Admin{User: *u.Clone()}
+	if err := WithLevel(level).applyAdmin(a); err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// Demote returns a copy of the admin's embedded User, without the level or
+// CC list, leaving a itself unchanged.
+func (a *Admin) Demote() *User {
+	return a.User.Clone()
+}
+
+// Equal reports whether a and other have equal embedded users and the
+// same level. Nil admins compare as in User.Equal.
+func (a *Admin) Equal(other *Admin) bool {
+	if a == nil || other == nil {
+		return a == other
+	}
+
+	return a.User.Equal(&other.User) && a.Level == other.Level
+}
+
+// String describes the admin, as in
+// "admin[super] Janet Jones <j***t@email.com>". It builds on the promoted
+// Redacted rather than calling the embedded User's String, which would
+// describe a plain user.
+func (a *Admin) String() string {
+	return "admin[" + string(a.Level) + "] " + a.Redacted()
+}
@@ -0,0 +1,252 @@
+package notify_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+func TestNewAdminErrors(t *testing.T) {
+	tests := []struct {
+		name, admin, email string
+		opts               []notify.AdminOption
+		want               error
+	}{
+		{"empty name", "", "lisa@email.com", nil, notify.ErrEmptyName},
+		{"empty email", "Lisa", "", nil, notify.ErrEmptyEmail},
+		{"empty level", "Lisa", "lisa@email.com", []notify.AdminOption{notify.WithLevel("")}, notify.ErrEmptyLevel},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := notify.NewAdmin(tt.admin, tt.email, tt.opts...)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("NewAdmin(%q, %q) error = %v, want %v", tt.admin, tt.email, err, tt.want)
+			}
+			if a != nil {
+				t.Errorf("NewAdmin(%q, %q) = %+v, want nil", tt.admin, tt.email, a)
+			}
+		})
+	}
+}
+
+func TestAdminPromotion(t *testing.T) {
+	var buf bytes.Buffer
+	a, err := notify.NewAdmin("Lisa Smith", "lisa@email.com", quietAdmin(&buf, notify.WithLevel(notify.LevelSuper))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The embedded User's fields and methods are promoted to the admin.
+	if a.Name != a.User.Name || a.Email != "lisa@email.com" {
+		t.Errorf("promoted fields = %q <%s>, want Lisa Smith <lisa@email.com>", a.Name, a.Email)
+	}
+
+	tests := []struct {
+		name   string
+		notify func(context.Context) error
+		want   string
+	}{
+		{"embedded user", a.User.Notify, "Sending user email to Lisa Smith<lisa@email.com>\n"},
+		{"admin override", a.Notify, "Sending admin email to Lisa Smith<lisa@email.com> with level super\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+			if err := tt.notify(context.Background()); err != nil {
+				t.Fatalf("Notify() = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewAdminDefaults(t *testing.T) {
+	a, err := notify.NewAdmin("Lisa", "lisa@email.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Level != notify.LevelNormal || a.Locale() != notify.DefaultLocale || len(a.CC) != 0 {
+		t.Errorf("NewAdmin() = level %q, locale %q, cc %v, want normal, %s and none", a.Level, a.Locale(), a.CC, notify.DefaultLocale)
+	}
+}
+
+func TestNewAdminOptions(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       []notify.AdminOption
+		wantLevel  notify.Level
+		wantLocale string
+		wantCC     []string
+		wantErr    error
+	}{
+		{"all options", []notify.AdminOption{notify.WithLevel(notify.LevelRoot), notify.WithCC("ops@email.com"), notify.WithLocale("fr-CA")},
+			notify.LevelRoot, "fr-CA", []string{"ops@email.com"}, nil},
+		{"later option wins", []notify.AdminOption{notify.WithLevel(notify.LevelRoot), notify.WithLevel(notify.LevelSuper)},
+			notify.LevelSuper, notify.DefaultLocale, nil, nil},
+		{"cc accumulates", []notify.AdminOption{notify.WithCC("a@email.com"), notify.WithCC("b@EMAIL.com", "c@email.com")},
+			notify.LevelNormal, notify.DefaultLocale, []string{"a@email.com", "b@email.com", "c@email.com"}, nil},
+		{"unknown level", []notify.AdminOption{notify.WithLevel("intern")}, "", "", nil, notify.ErrUnknownLevel},
+		{"invalid cc", []notify.AdminOption{notify.WithCC("not an email")}, "", "", nil, notify.ErrInvalidEmail},
+		{"empty cc", []notify.AdminOption{notify.WithCC("")}, "", "", nil, notify.ErrEmptyEmail},
+		{"self cc", []notify.AdminOption{notify.WithCC("ops@email.com", "lisa@EMAIL.com")}, "", "", nil, notify.ErrSelfCC},
+		{"invalid locale", []notify.AdminOption{notify.WithLocale("english please")}, "", "", nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := notify.NewAdmin("Lisa", "lisa@email.com", tt.opts...)
+			if tt.wantLevel == "" {
+				if err == nil || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+					t.Errorf("NewAdmin() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if a.Level != tt.wantLevel || a.Locale() != tt.wantLocale || !slices.Equal(a.CC, tt.wantCC) {
+				t.Errorf("NewAdmin() = level %q, locale %q, cc %v, want %q, %q, %v",
+					a.Level, a.Locale(), a.CC, tt.wantLevel, tt.wantLocale, tt.wantCC)
+			}
+		})
+	}
+}
+
+func TestAdminClone(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(c *notify.Admin)
+	}{
+		{"embedded email", func(c *notify.Admin) { c.User.Email = "bob@email.com" }},
+		{"promoted name", func(c *notify.Admin) { c.Name = "Bob Brown" }},
+		{"level", func(c *notify.Admin) { c.Level = notify.LevelNormal }},
+		{"cc element", func(c *notify.Admin) { c.CC[0] = "eve@email.com" }},
+		{"cc append", func(c *notify.Admin) { c.CC = append(c.CC[:1], "eve@email.com") }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			a, err := notify.NewAdmin("Lisa Smith", "lisa@email.com",
+				quietAdmin(&buf, notify.WithLevel(notify.LevelRoot), notify.WithCC("ops@email.com", "sec@email.com"))...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tt.mutate(a.Clone())
+			if a.Name != "Lisa Smith" || a.Email != "lisa@email.com" || a.Level != notify.LevelRoot {
+				t.Errorf("original = %s<%s> level %q, want it unchanged", a.Name, a.Email, a.Level)
+			}
+			if want := []string{"ops@email.com", "sec@email.com"}; !slices.Equal(a.CC, want) {
+				t.Errorf("original CC = %v, want %v", a.CC, want)
+			}
+		})
+	}
+}
+
+func TestAdminEqual(t *testing.T) {
+	admin := func(email string, level notify.Level, cc ...string) *notify.Admin {
+		return &notify.Admin{User: notify.User{Name: "Lisa Smith", Email: email}, Level: level, CC: cc}
+	}
+	lisa := admin("lisa@email.com", notify.LevelRoot)
+
+	tests := []struct {
+		name string
+		a, b *notify.Admin
+		want bool
+	}{
+		{"equal", lisa, admin("lisa@email.com", notify.LevelRoot), true},
+		{"ignores cc", lisa, admin("lisa@email.com", notify.LevelRoot, "ops@email.com"), true},
+		{"differing email", lisa, admin("ls@email.com", notify.LevelRoot), false},
+		{"differing level", lisa, admin("lisa@email.com", notify.LevelSuper), false},
+		{"both nil", nil, nil, true},
+		{"nil receiver", nil, lisa, false},
+		{"nil argument", lisa, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.a.Equal(tt.b); got != tt.want {
+				t.Errorf("Equal() = %v, want %v", got, tt.want)
+			}
+			if got := tt.b.Equal(tt.a); got != tt.want {
+				t.Errorf("Equal() reversed = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPromote(t *testing.T) {
+	tests := []struct {
+		name    string
+		level   notify.Level
+		wantErr error
+		wantOut string
+	}{
+		{"root", notify.LevelRoot, nil, "Sending admin email to Janet Jones<janet@email.com> with level root\n"},
+		{"normal", notify.LevelNormal, nil, "Sending admin email to Janet Jones<janet@email.com> with level normal\n"},
+		{"empty level", "", notify.ErrEmptyLevel, ""},
+		{"unknown level", "god", notify.ErrUnknownLevel, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			u, err := notify.NewUser("Janet Jones", "janet@email.com", quiet(&buf)...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			a, err := u.Promote(tt.level)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Promote() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if a.Name != u.Name || a.Email != u.Email || a.Level != tt.level {
+				t.Errorf("Promote() = %s<%s> level %q, want %s<%s> level %q", a.Name, a.Email, a.Level, u.Name, u.Email, tt.level)
+			}
+
+			// The admin keeps the user's writer but not the user itself.
+			a.Name = "Changed"
+			if u.Name != "Janet Jones" {
+				t.Errorf("changing the promoted admin renamed the user to %q", u.Name)
+			}
+			a.Name = "Janet Jones"
+			if err := a.Notify(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.wantOut {
+				t.Errorf("output = %q, want %q", got, tt.wantOut)
+			}
+		})
+	}
+}
+
+func TestDemote(t *testing.T) {
+	var buf bytes.Buffer
+	a, err := notify.NewAdmin("Lisa Smith", "lisa@email.com",
+		quietAdmin(&buf, notify.WithLevel(notify.LevelRoot), notify.WithCC("ops@email.com"))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u := a.Demote()
+	if u.Name != "Lisa Smith" || u.Email != "lisa@email.com" {
+		t.Errorf("Demote() = %s<%s>, want Lisa Smith<lisa@email.com>", u.Name, u.Email)
+	}
+	if err := u.Notify(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), "Sending user email to Lisa Smith<lisa@email.com>\n"; got != want {
+		t.Errorf("demoted output = %q, want %q without the level", got, want)
+	}
+
+	u.Email = "changed@email.com"
+	if a.Email != "lisa@email.com" || a.Level != notify.LevelRoot || !slices.Equal(a.CC, []string{"ops@email.com"}) {
+		t.Errorf("admin after Demote = <%s> level %q cc %v, want it unchanged", a.Email, a.Level, a.CC)
+	}
+}
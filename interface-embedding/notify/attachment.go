@@ -0,0 +1,88 @@
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// DefaultMaxAttachmentSize is the largest attachment a recipient accepts
+// unless WithMaxAttachmentSize says otherwise.
+const DefaultMaxAttachmentSize = 10 << 20
+
+// ErrAttachmentTooLarge is returned when an attachment is bigger than the
+// recipient's limit.
+var ErrAttachmentTooLarge = errors.New("notify: attachment too large")
+
+// Attachment is a file sent along with a notification, such as a PDF
+// receipt. MIMEType defaults to "application/octet-stream".
+type Attachment struct {
+	Filename string
+	Content  []byte
+	MIMEType string
+}
+
+// Attacher is implemented by notifiers that can carry attachments. User
+// implements it, and types embedding User inherit it.
+type Attacher interface {
+	Attach(a Attachment) error
+}
+
+// WithMaxAttachmentSize sets the largest attachment, in bytes, that Attach
+// accepts.
+func WithMaxAttachmentSize(n int) UserOption {
+	return func(u *User) error {
+		if n <= 0 {
+			return fmt.Errorf("notify: max attachment size must be positive, got %d", n)
+		}
+		u.maxAttachment = n
+
+		return nil
+	}
+}
+
+// Attach adds a to every later notification, or returns
+// ErrAttachmentTooLarge if it is over the user's limit. It must not be
+// called while the user is being notified.
+func (u *User) Attach(a Attachment) error {
+	if a.Filename == "" {
+		return errors.New("notify: attachment filename is required")
+	}
+	limit := u.maxAttachment
+	if limit == 0 {
+		limit = DefaultMaxAttachmentSize
+	}
+	if len(a.Content) > limit {
+		return fmt.Errorf("%w: %s is %d bytes, limit is %d", ErrAttachmentTooLarge, a.Filename, len(a.Content), limit)
+	}
+	if a.MIMEType == "" {
+		a.MIMEType = "application/octet-stream"
+	}
+	u.attachments = append(u.attachments, a)
+
+	return nil
+}
+
+// Attachments returns the user's attachments in the order they were
+// added.
+func (u *User) Attachments() []Attachment {
+	return slices.Clone(u.attachments)
+}
+
+// appendAttachments appends a description of the user's attachments to
+// buf, as in "... [attached: receipt.pdf (application/pdf, 1024 bytes)]".
+func (u *User) appendAttachments(buf []byte) []byte {
+	if len(u.attachments) == 0 {
+		return buf
+	}
+
+	buf = append(buf, " [attached: "...)
+	for i, a := range u.attachments {
+		if i > 0 {
+			buf = append(buf, ", "...)
+		}
+		buf = fmt.Appendf(buf, "%s (%s, %d bytes)", Sanitize(a.Filename), Sanitize(a.MIMEType), len(a.Content))
+	}
+
+	return append(buf, ']')
+}
@@ -0,0 +1,96 @@
+package notify_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+func TestAttach(t *testing.T) {
+	receipt := notify.Attachment{Filename: "receipt.pdf", Content: make([]byte, 1024), MIMEType: "application/pdf"}
+	logo := notify.Attachment{Filename: "logo.png", Content: []byte("png"), MIMEType: "image/png"}
+
+	tests := []struct {
+		name    string
+		opts    []notify.UserOption
+		attach  []notify.Attachment
+		wantErr error
+		wantOut string
+	}{
+		{"none", nil, nil, nil,
+			"Sending user email to Janet Jones<janet@email.com>\n"},
+		{"single", nil, []notify.Attachment{receipt}, nil,
+			"Sending user email to Janet Jones<janet@email.com> [attached: receipt.pdf (application/pdf, 1024 bytes)]\n"},
+		{"multiple in order", nil, []notify.Attachment{receipt, logo}, nil,
+			"Sending user email to Janet Jones<janet@email.com> [attached: receipt.pdf (application/pdf, 1024 bytes), logo.png (image/png, 3 bytes)]\n"},
+		{"default MIME type", nil, []notify.Attachment{{Filename: "data.bin", Content: []byte{1, 2}}}, nil,
+			"Sending user email to Janet Jones<janet@email.com> [attached: data.bin (application/octet-stream, 2 bytes)]\n"},
+		{"filename sanitized", nil, []notify.Attachment{{Filename: "a\nb.txt", MIMEType: "text/plain"}}, nil,
+			`Sending user email to Janet Jones<janet@email.com> [attached: a\nb.txt (text/plain, 0 bytes)]` + "\n"},
+		{"at the limit", []notify.UserOption{notify.WithMaxAttachmentSize(1024)}, []notify.Attachment{receipt}, nil,
+			"Sending user email to Janet Jones<janet@email.com> [attached: receipt.pdf (application/pdf, 1024 bytes)]\n"},
+		{"over the limit", []notify.UserOption{notify.WithMaxAttachmentSize(1023)}, []notify.Attachment{logo, receipt},
+			notify.ErrAttachmentTooLarge,
+			"Sending user email to Janet Jones<janet@email.com> [attached: logo.png (image/png, 3 bytes)]\n"},
+		{"over the default limit", nil, []notify.Attachment{{Filename: "huge.iso", Content: make([]byte, notify.DefaultMaxAttachmentSize+1)}},
+			notify.ErrAttachmentTooLarge, "Sending user email to Janet Jones<janet@email.com>\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			u, err := notify.NewUser("Janet Jones", "janet@email.com", append(quiet(&buf), tt.opts...)...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var attachErr error
+			for _, a := range tt.attach {
+				if err := notify.Attacher(u).Attach(a); err != nil {
+					attachErr = err
+				}
+			}
+			if !errors.Is(attachErr, tt.wantErr) {
+				t.Errorf("Attach() = %v, want %v", attachErr, tt.wantErr)
+			}
+
+			if err := u.Notify(context.Background()); err != nil {
+				t.Fatalf("Notify() = %v", err)
+			}
+			if got := buf.String(); got != tt.wantOut {
+				t.Errorf("output = %q, want %q", got, tt.wantOut)
+			}
+		})
+	}
+}
+
+func TestAttachAdmin(t *testing.T) {
+	var buf bytes.Buffer
+	a, err := notify.NewAdmin("Lisa Smith", "lisa@email.com", quietAdmin(&buf, notify.WithLevel(notify.LevelRoot))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Attach(notify.Attachment{Filename: "report.csv", Content: []byte("a,b"), MIMEType: "text/csv"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Notify(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), "Sending admin email to Lisa Smith<lisa@email.com> with level root [attached: report.csv (text/csv, 3 bytes)]\n"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestAttachErrors(t *testing.T) {
+	if _, err := notify.NewUser("Janet Jones", "janet@email.com", notify.WithMaxAttachmentSize(0)); err == nil {
+		t.Error("NewUser() with a zero attachment limit = nil, want an error")
+	}
+	u := &notify.User{Name: "Janet Jones", Email: "janet@email.com"}
+	if err := u.Attach(notify.Attachment{Content: []byte("x")}); err == nil {
+		t.Error("Attach() without a filename = nil, want an error")
+	}
+	if got := u.Attachments(); len(got) != 0 {
+		t.Errorf("Attachments() = %v after rejected attachments, want none", got)
+	}
+}
@@ -0,0 +1,183 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// AuditEntry is one line of an audit log. Success is false, and Error
+// says why, for failed attempts.
+type AuditEntry struct {
+	Time      time.Time `json:"time"`
+	Recipient string    `json:"recipient"`
+	Channel   string    `json:"channel"`
+	Success   bool      `json:"success"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// AuditLog appends AuditEntry values to a writer as newline-delimited
+// JSON. It is safe for concurrent use; each entry is written with a single
+// call so lines never interleave.
+type AuditLog struct {
+	fsync bool
+	clock Clock
+
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// AuditOption configures an AuditLog.
+type AuditOption func(*AuditLog)
+
+// WithFsync syncs the underlying file after every entry, when the writer
+// supports it as *os.File does.
+func WithFsync() AuditOption {
+	return func(l *AuditLog) {
+		l.fsync = true
+	}
+}
+
+// WithAuditClock sets the clock AuditNotifier stamps entries with. It
+// defaults to SystemClock.
+func WithAuditClock(clock Clock) AuditOption {
+	return func(l *AuditLog) {
+		l.clock = clock
+	}
+}
+
+// NewAuditLog returns an AuditLog writing to w.
+func NewAuditLog(w io.Writer, opts ...AuditOption) *AuditLog {
+	l := &AuditLog{w: w, clock: SystemClock}
+	for _, opt := range opts {
+		opt(l)
+	}
+	if l.clock == nil {
+		l.clock = SystemClock
+	}
+
+	return l
+}
+
+// OpenAuditLog opens, or creates, the file at path for appending and
+// returns an AuditLog writing to it. The caller must Close it.
+func OpenAuditLog(path string, opts ...AuditOption) (*AuditLog, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("notify: open audit log: %w", err)
+	}
+
+	return NewAuditLog(f, opts...), nil
+}
+
+// Record appends e to the log.
+func (l *AuditLog) Record(e AuditEntry) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("notify: encode audit entry: %w", err)
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, err := l.w.Write(line); err != nil {
+		return fmt.Errorf("notify: write audit entry: %w", err)
+	}
+	if s, ok := l.w.(interface{ Sync() error }); ok && l.fsync {
+		if err := s.Sync(); err != nil {
+			return fmt.Errorf("notify: sync audit log: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Close closes the underlying writer if it is an io.Closer.
+func (l *AuditLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if c, ok := l.w.(io.Closer); ok {
+		return c.Close()
+	}
+
+	return nil
+}
+
+// AuditNotifier records every call to the embedded Notifier in Log,
+// keeping auditing out of the notifiers themselves. Entries are stamped
+// with the log's clock.
+type AuditNotifier struct {
+	Notifier
+	Log *AuditLog
+}
+
+// Notify calls the embedded Notifier and records the outcome. A failure to
+// write the audit entry is returned alongside any notification error.
+func (a *AuditNotifier) Notify(ctx context.Context) error {
+	err := a.Notifier.Notify(ctx)
+
+	recipient, channel := AddressOf(a.Notifier)
+	entry := AuditEntry{Time: a.Log.clock.Now().UTC(), Recipient: recipient, Channel: channel, Success: err == nil}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	return errors.Join(err, a.Log.Record(entry))
+}
+
+// Unwrap returns the notifier being audited.
+func (a *AuditNotifier) Unwrap() Notifier {
+	return a.Notifier
+}
+
+// ReplayError reports the lines of an audit log that couldn't be decoded.
+type ReplayError struct {
+	Lines []int
+}
+
+// Error implements the error interface.
+func (e *ReplayError) Error() string {
+	return fmt.Sprintf("notify: %d corrupt audit log lines: %v", len(e.Lines), e.Lines)
+}
+
+// maxAuditLine bounds the length of a single audit log line.
+const maxAuditLine = 1 << 20
+
+// ReplayAudit reads an audit log back. Lines that can't be decoded are
+// skipped and reported, by line number, in a *ReplayError returned with
+// the entries that could be read. Other read errors stop the replay.
+func ReplayAudit(r io.Reader) ([]AuditEntry, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), maxAuditLine)
+
+	var entries []AuditEntry
+	var corrupt []int
+	for line := 1; sc.Scan(); line++ {
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+
+		var e AuditEntry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			corrupt = append(corrupt, line)
+			continue
+		}
+		entries = append(entries, e)
+	}
+	if err := sc.Err(); err != nil {
+		return entries, fmt.Errorf("notify: read audit log: %w", err)
+	}
+	if len(corrupt) > 0 {
+		return entries, &ReplayError{Lines: corrupt}
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,238 @@
+package notify_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+func TestAuditNotifierReplay(t *testing.T) {
+	errDown := errors.New("server down")
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	log, err := notify.OpenAuditLog(path, notify.WithFsync())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	sends := []struct {
+		email string
+		err   error
+	}{
+		{"janet@email.com", nil},
+		{"bob@email.com", errDown},
+		{"lisa@email.com", nil},
+	}
+	for _, s := range sends {
+		u, err := notify.NewUser("Someone", s.email, quiet(&buf)...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var n notify.Notifier = u
+		if s.err != nil {
+			n = &notify.MockNotifier{Err: s.err}
+			n = &addressed{Notifier: n, to: u}
+		}
+		audited := &notify.AuditNotifier{Notifier: n, Log: log}
+		if err := audited.Notify(context.Background()); !errors.Is(err, s.err) {
+			t.Fatalf("Notify() = %v, want %v", err, s.err)
+		}
+	}
+	if err := log.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := notify.ReplayAudit(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("ReplayAudit() = %v, want a clean replay", err)
+	}
+	if len(entries) != len(sends) {
+		t.Fatalf("replayed %d entries, want %d", len(entries), len(sends))
+	}
+	for i, e := range entries {
+		if e.Recipient != sends[i].email || e.Channel != "email" || e.Success != (sends[i].err == nil) || e.Time.IsZero() {
+			t.Errorf("entry %d = %+v, want a timestamped email entry for %s", i, e, sends[i].email)
+		}
+	}
+	if entries[1].Error != errDown.Error() {
+		t.Errorf("failed entry Error = %q, want %q", entries[1].Error, errDown.Error())
+	}
+
+	// Cut the second line short, as a crash mid-write would.
+	lines := strings.SplitAfter(string(data), "\n")
+	lines[1] = lines[1][:len(lines[1])/2] + "\n"
+	entries, err = notify.ReplayAudit(strings.NewReader(strings.Join(lines, "")))
+
+	var re *notify.ReplayError
+	if !errors.As(err, &re) || !slices.Equal(re.Lines, []int{2}) {
+		t.Fatalf("ReplayAudit() of a truncated log = %v, want one corrupt line, line 2", err)
+	}
+	if len(entries) != 2 || entries[0].Recipient != "janet@email.com" || entries[1].Recipient != "lisa@email.com" {
+		t.Errorf("recovered %+v, want the entries either side of the corrupt line", entries)
+	}
+}
+
+func TestAuditNotifierClock(t *testing.T) {
+	start := time.Date(2026, 1, 1, 9, 0, 0, 0, time.FixedZone("CET", 3600))
+	clock := notify.NewSimClock(start)
+	var out bytes.Buffer
+	log := notify.NewAuditLog(&out, notify.WithAuditClock(clock))
+	audited := &notify.AuditNotifier{Notifier: &notify.MockNotifier{}, Log: log}
+
+	for range 2 {
+		if err := audited.Notify(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		<-clock.After(time.Minute)
+	}
+
+	entries, err := notify.ReplayAudit(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []time.Time{start.UTC(), start.Add(time.Minute).UTC()}
+	if len(entries) != len(want) {
+		t.Fatalf("replayed %d entries, want %d", len(entries), len(want))
+	}
+	for i, e := range entries {
+		if !e.Time.Equal(want[i]) || e.Time.Location() != time.UTC {
+			t.Errorf("entry %d stamped %v, want %v from the clock", i, e.Time, want[i])
+		}
+	}
+}
+
+// addressed gives a notifier the address of to, so the recipient and
+// channel are known even when the delivery is mocked.
+type addressed struct {
+	notify.Notifier
+	to notify.Addresser
+}
+
+func (a *addressed) Address() (recipient, channel string) {
+	return a.to.Address()
+}
+
+func TestReplayAudit(t *testing.T) {
+	valid := `{"time":"2026-01-01T09:00:00Z","recipient":"janet@email.com","channel":"email","success":true}`
+
+	tests := []struct {
+		name        string
+		log         string
+		wantEntries int
+		wantLines   []int
+	}{
+		{"empty", "", 0, nil},
+		{"one entry", valid + "\n", 1, nil},
+		{"no trailing newline", valid, 1, nil},
+		{"blank lines skipped", "\n" + valid + "\n\n" + valid + "\n", 2, nil},
+		{"garbage", "not json\n", 0, []int{1}},
+		{"mixed", valid + "\n{\"time\":\n" + valid + "\n[]\n", 2, []int{2, 4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := notify.ReplayAudit(strings.NewReader(tt.log))
+			if len(entries) != tt.wantEntries {
+				t.Errorf("replayed %d entries, want %d", len(entries), tt.wantEntries)
+			}
+
+			var re *notify.ReplayError
+			switch {
+			case tt.wantLines == nil && err != nil:
+				t.Errorf("ReplayAudit() = %v, want nil", err)
+			case tt.wantLines != nil && (!errors.As(err, &re) || !slices.Equal(re.Lines, tt.wantLines)):
+				t.Errorf("ReplayAudit() = %v, want corrupt lines %v", err, tt.wantLines)
+			}
+		})
+	}
+}
+
+func TestAuditNotifierWriteError(t *testing.T) {
+	errDisk := errors.New("disk full")
+	errDown := errors.New("server down")
+
+	tests := []struct {
+		name  string
+		err   error
+		wants []error
+	}{
+		{"success", nil, []error{errDisk}},
+		{"failure", errDown, []error{errDisk, errDown}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &notify.AuditNotifier{
+				Notifier: &notify.MockNotifier{Err: tt.err},
+				Log:      notify.NewAuditLog(failWriter{errDisk}),
+			}
+			err := a.Notify(context.Background())
+			for _, want := range tt.wants {
+				if !errors.Is(err, want) {
+					t.Errorf("Notify() = %v, want it to match %v", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestAuditNotifierConcurrent(t *testing.T) {
+	errDown := errors.New("server down")
+	// A plain buffer: the race detector fails the test if the log lets
+	// two writes overlap.
+	var buf bytes.Buffer
+	log := notify.NewAuditLog(&buf)
+
+	const goroutines, sends = 10, 20
+	var wg sync.WaitGroup
+	for i := range goroutines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			to := &notify.User{Email: fmt.Sprintf("user%d@email.com", i)}
+			var err error
+			if i%2 == 1 {
+				err = errDown
+			}
+			a := &notify.AuditNotifier{Notifier: &addressed{Notifier: &notify.MockNotifier{Err: err}, to: to}, Log: log}
+			for range sends {
+				a.Notify(context.Background())
+			}
+		}()
+	}
+	wg.Wait()
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != goroutines*sends {
+		t.Fatalf("log has %d lines, want %d", len(lines), goroutines*sends)
+	}
+	counts := make(map[string]int)
+	for i, line := range lines {
+		var e notify.AuditEntry
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			t.Fatalf("line %d = %q, not valid JSON: %v", i+1, line, err)
+		}
+		if e.Success != (e.Error == "") || (!e.Success && e.Error != errDown.Error()) {
+			t.Errorf("line %d = %+v, want success or the send's error", i+1, e)
+		}
+		counts[e.Recipient]++
+	}
+	for i := range goroutines {
+		if email := fmt.Sprintf("user%d@email.com", i); counts[email] != sends {
+			t.Errorf("%s has %d entries, want %d", email, counts[email], sends)
+		}
+	}
+}
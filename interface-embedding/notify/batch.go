@@ -0,0 +1,96 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+)
+
+// SendConcurrent delivers through every notifier using a pool of workers
+// goroutines, defaulting to GOMAXPROCS when workers is not positive. Once
+// ctx is done no further notifiers are started. Failures are joined in the
+// same order as ns regardless of which worker finished first.
+func SendConcurrent(ctx context.Context, workers int, ns []Notifier) error {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	return errors.Join(resultErrors(sendPool(ctx, ctx, ns, workers))...)
+}
+
+// SendBatch delivers through every notifier using a pool of workers
+// goroutines, defaulting to runtime.NumCPU when workers is not positive.
+// The returned slice has one entry per notifier, in the same order as
+// notifiers, holding nil for each successful send.
+func SendBatch(notifiers []Notifier, workers int) []error {
+	return SendBatchCtx(context.Background(), notifiers, workers)
+}
+
+// SendBatchCtx is SendBatch with graceful shutdown: once ctx is done the
+// workers stop starting new notifications, but those already started run
+// to completion with a context that isn't cancelled. Notifications that
+// never started report ctx.Err(), such as context.Canceled.
+func SendBatchCtx(ctx context.Context, notifiers []Notifier, workers int) []error {
+	return resultErrors(SendBatchResults(ctx, notifiers, workers))
+}
+
+// SendBatchResults is SendBatchCtx reporting a Result for each notifier
+// instead of only its error. Notifications that never started have a zero
+// Attempt.
+func SendBatchResults(ctx context.Context, notifiers []Notifier, workers int) []Result {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	return sendPool(ctx, context.WithoutCancel(ctx), notifiers, workers)
+}
+
+// sendPool hands ns out to workers goroutines and returns one result per
+// notifier. Each notifier is sent with sendCtx, but none are started once
+// ctx is done; those report ctx.Err() instead.
+func sendPool(ctx, sendCtx context.Context, ns []Notifier, workers int) []Result {
+	results := make([]Result, len(ns))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for range min(workers, len(ns)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if err := ctx.Err(); err != nil {
+					results[i] = unstarted(ns[i], err)
+					continue
+				}
+				results[i], _ = Send(sendCtx, ns[i])
+			}
+		}()
+	}
+
+	next := 0
+feed:
+	for ; next < len(ns); next++ {
+		select {
+		case jobs <- next:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	for ; next < len(ns); next++ {
+		results[next] = unstarted(ns[next], ctx.Err())
+	}
+
+	return results
+}
+
+// unstarted returns the result for a notifier that was never sent.
+func unstarted(n Notifier, err error) Result {
+	r := Result{Err: err}
+	r.Recipient, r.Channel = AddressOf(n)
+
+	return r
+}
@@ -0,0 +1,175 @@
+package notify_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+// gauge tracks how many notifiers run at once and the most seen together.
+type gauge struct {
+	running, peak atomic.Int64
+}
+
+// notifier returns a Notifier that holds a slot in g for a moment and
+// then fails with err.
+func (g *gauge) notifier(err error) notify.Notifier {
+	return notify.NotifierFunc(func(ctx context.Context) error {
+		n := g.running.Add(1)
+		defer g.running.Add(-1)
+		for {
+			peak := g.peak.Load()
+			if n <= peak || g.peak.CompareAndSwap(peak, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+
+		return err
+	})
+}
+
+func TestSendConcurrent(t *testing.T) {
+	tests := []struct {
+		name     string
+		workers  int
+		wantPeak int64
+	}{
+		{"one worker", 1, 1},
+		{"four workers", 4, 4},
+		{"more workers than notifiers", 500, 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var g gauge
+			ns := make([]notify.Notifier, 100)
+			var wantErrs []error
+			for i := range ns {
+				var err error
+				if i%10 == 0 {
+					err = fmt.Errorf("notifier %d", i)
+					wantErrs = append(wantErrs, err)
+				}
+				ns[i] = g.notifier(err)
+			}
+
+			err := notify.SendConcurrent(context.Background(), tt.workers, ns)
+			if peak := g.peak.Load(); peak > tt.wantPeak {
+				t.Errorf("peak concurrency = %d, want at most %d", peak, tt.wantPeak)
+			}
+
+			joined, ok := err.(interface{ Unwrap() []error })
+			if !ok {
+				t.Fatalf("SendConcurrent() = %v, want joined errors", err)
+			}
+			got := joined.Unwrap()
+			if len(got) != len(wantErrs) {
+				t.Fatalf("got %d errors, want %d", len(got), len(wantErrs))
+			}
+			for i := range got {
+				if got[i] != wantErrs[i] {
+					t.Errorf("error %d = %v, want %v in input order", i, got[i], wantErrs[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSendConcurrentDefaultsWorkers(t *testing.T) {
+	ns := []notify.Notifier{&notify.MockNotifier{}, &notify.MockNotifier{}}
+	for _, workers := range []int{0, -1} {
+		if err := notify.SendConcurrent(context.Background(), workers, ns); err != nil {
+			t.Errorf("SendConcurrent(workers=%d) = %v", workers, err)
+		}
+	}
+}
+
+func TestSendConcurrentCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var started atomic.Int64
+	ns := make([]notify.Notifier, 100)
+	for i := range ns {
+		ns[i] = notify.NotifierFunc(func(context.Context) error {
+			if started.Add(1) == 5 {
+				cancel()
+			}
+			return nil
+		})
+	}
+
+	err := notify.SendConcurrent(ctx, 2, ns)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("SendConcurrent() = %v, want %v for the notifiers that never ran", err, context.Canceled)
+	}
+	if n := started.Load(); n >= 100 {
+		t.Errorf("%d notifiers ran, want the batch to stop early", n)
+	}
+}
+
+func TestSendBatch(t *testing.T) {
+	mocks := make([]*notify.MockNotifier, 100)
+	ns := make([]notify.Notifier, len(mocks))
+	for i := range mocks {
+		mocks[i] = &notify.MockNotifier{}
+		if i%7 == 0 {
+			mocks[i].Err = fmt.Errorf("notifier %d", i)
+		}
+		ns[i] = mocks[i]
+	}
+
+	errs := notify.SendBatch(ns, 4)
+	if len(errs) != len(ns) {
+		t.Fatalf("SendBatch() returned %d errors, want %d", len(errs), len(ns))
+	}
+	for i, m := range mocks {
+		if m.Calls() != 1 {
+			t.Errorf("notifier %d called %d times, want 1", i, m.Calls())
+		}
+		if errs[i] != m.Err {
+			t.Errorf("errs[%d] = %v, want %v", i, errs[i], m.Err)
+		}
+	}
+}
+
+func TestSendBatchCtxCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var started atomic.Int64
+	var inFlightErr atomic.Value
+	ns := make([]notify.Notifier, 20)
+	for i := range ns {
+		ns[i] = notify.NotifierFunc(func(ctx context.Context) error {
+			if started.Add(1) == 3 {
+				cancel()
+				// Sends already started run to completion uncancelled.
+				time.Sleep(5 * time.Millisecond)
+				inFlightErr.Store(fmt.Sprint(ctx.Err()))
+			}
+			return nil
+		})
+	}
+
+	errs := notify.SendBatchCtx(ctx, ns, 1)
+	if len(errs) != len(ns) {
+		t.Fatalf("SendBatchCtx() returned %d errors, want %d", len(errs), len(ns))
+	}
+	for i := range 3 {
+		if errs[i] != nil {
+			t.Errorf("errs[%d] = %v, want nil for a send that started", i, errs[i])
+		}
+	}
+	for i := 3; i < len(errs); i++ {
+		if !errors.Is(errs[i], context.Canceled) {
+			t.Errorf("errs[%d] = %v, want %v", i, errs[i], context.Canceled)
+		}
+	}
+	if got := inFlightErr.Load(); got != "<nil>" {
+		t.Errorf("in-flight send saw ctx error %v, want it uncancelled", got)
+	}
+}
@@ -0,0 +1,210 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without calling the wrapped notifier while a
+// circuit breaker is open.
+var ErrCircuitOpen = errors.New("notify: circuit open")
+
+// BreakerState is the state of a CircuitBreakerNotifier.
+type BreakerState int
+
+// The states a circuit breaker moves through. A closed breaker lets calls
+// through, an open one fails them fast, and a half-open one lets calls
+// probe whether the notifier has recovered.
+const (
+	StateClosed BreakerState = iota
+	StateOpen
+	StateHalfOpen
+)
+
+// String returns "closed", "open", or "half-open".
+func (s BreakerState) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	}
+
+	return "unknown"
+}
+
+// CircuitBreakerNotifier stops calling the embedded Notifier after it
+// fails several times in a row, giving a struggling downstream time to
+// recover. After the cooldown the breaker goes half-open and lets a single
+// trial call through, failing other calls fast until it finishes: success
+// closes the breaker again and failure reopens it. Errors that IsRetryable
+// rejects, such as an invalid recipient, say nothing about the
+// downstream's health and count as successes. Context errors are neutral:
+// a call that was cancelled or ran out of time leaves the breaker as it
+// was.
+//
+// Only calls let through in the breaker's current state count: a slow call
+// that started before the breaker opened can't close it when it finishes,
+// and only the probe's own result moves a half-open breaker on.
+type CircuitBreakerNotifier struct {
+	Notifier
+
+	threshold int
+	cooldown  time.Duration
+	clock     Clock
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+
+	// gen counts state changes, so record can tell a call let through in
+	// the current state from a stale one.
+	gen uint64
+}
+
+// BreakerOption configures a CircuitBreakerNotifier.
+type BreakerOption func(*CircuitBreakerNotifier)
+
+// WithFailureThreshold sets how many consecutive failures open the
+// breaker. The default is 5.
+func WithFailureThreshold(n int) BreakerOption {
+	return func(c *CircuitBreakerNotifier) {
+		c.threshold = max(n, 1)
+	}
+}
+
+// WithCooldown sets how long the breaker stays open before letting a
+// probe through. The default is 30 seconds.
+func WithCooldown(d time.Duration) BreakerOption {
+	return func(c *CircuitBreakerNotifier) {
+		c.cooldown = d
+	}
+}
+
+// WithBreakerClock sets the clock used to time the cooldown.
+func WithBreakerClock(clock Clock) BreakerOption {
+	return func(c *CircuitBreakerNotifier) {
+		c.clock = clock
+	}
+}
+
+// NewCircuitBreaker wraps n in a closed circuit breaker.
+func NewCircuitBreaker(n Notifier, opts ...BreakerOption) *CircuitBreakerNotifier {
+	c := &CircuitBreakerNotifier{
+		Notifier:  n,
+		threshold: 5,
+		cooldown:  30 * time.Second,
+		clock:     SystemClock,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// Notify calls the embedded Notifier unless the breaker is open, or is
+// half-open with a trial call already in flight.
+func (c *CircuitBreakerNotifier) Notify(ctx context.Context) error {
+	gen, ok := c.allow()
+	if !ok {
+		return ErrCircuitOpen
+	}
+
+	err := c.Notifier.Notify(ctx)
+	c.record(ctx, gen, err)
+
+	return err
+}
+
+// State reports the breaker's current state. An open breaker whose
+// cooldown has passed is reported as half-open.
+func (c *CircuitBreakerNotifier) State() BreakerState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.refresh()
+	return c.state
+}
+
+// allow reports whether a call may go through, claiming the trial call
+// when the breaker is half-open, and returns the generation to pass to
+// record.
+func (c *CircuitBreakerNotifier) allow() (uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.refresh()
+	switch c.state {
+	case StateOpen:
+		return 0, false
+	case StateHalfOpen:
+		if c.probing {
+			return 0, false
+		}
+		c.probing = true
+	}
+
+	return c.gen, true
+}
+
+// refresh moves an open breaker to half-open once its cooldown has passed.
+func (c *CircuitBreakerNotifier) refresh() {
+	if c.state == StateOpen && c.clock.Now().Sub(c.openedAt) >= c.cooldown {
+		c.setState(StateHalfOpen)
+	}
+}
+
+// setState moves the breaker to state, starting a new generation.
+func (c *CircuitBreakerNotifier) setState(state BreakerState) {
+	c.state = state
+	c.probing = false
+	c.gen++
+}
+
+// Unwrap returns the notifier behind the breaker.
+func (c *CircuitBreakerNotifier) Unwrap() Notifier {
+	return c.Notifier
+}
+
+// record updates the breaker with the outcome of a call made with ctx and
+// let through in generation gen. Outcomes from an earlier generation are
+// ignored, as are failures caused by the caller giving up on ctx, which
+// say nothing about the notifier. A call that timed out on its own, as
+// through a TimeoutNotifier, counts as a failure: a notifier that hangs is
+// what the breaker is for.
+func (c *CircuitBreakerNotifier) record(ctx context.Context, gen uint64, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if gen != c.gen {
+		return
+	}
+	if err != nil && ctx.Err() != nil {
+		// Free the probe slot so the next call can try instead.
+		c.probing = false
+		return
+	}
+
+	timedOut := errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+	if !timedOut && !IsRetryable(err) {
+		c.failures = 0
+		if c.state != StateClosed {
+			c.setState(StateClosed)
+		}
+		return
+	}
+
+	c.failures++
+	if c.state == StateHalfOpen || c.failures >= c.threshold {
+		c.failures = 0
+		c.setState(StateOpen)
+		c.openedAt = c.clock.Now()
+	}
+}
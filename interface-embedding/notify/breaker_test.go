@@ -0,0 +1,359 @@
+package notify_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+// errFlaky is a retryable failure, the kind that counts against a breaker.
+var errFlaky = &notify.NotificationError{Recipient: "janet@email.com", Channel: "email", Retryable: true, Err: errors.New("gateway timeout")}
+
+func TestCircuitBreakerStateMachine(t *testing.T) {
+	permanent := notify.Permanent(errors.New("bad address"))
+
+	// Each step optionally moves the clock, makes one call returning err
+	// if it gets through, and checks the result.
+	type step struct {
+		advance   time.Duration
+		done      bool // the caller's ctx is done before the call
+		err       error
+		wantErr   error
+		wantState notify.BreakerState
+		wantCalls int
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{"stays closed below the threshold", []step{
+			{err: errFlaky, wantErr: errFlaky, wantState: notify.StateClosed, wantCalls: 1},
+			{err: errFlaky, wantErr: errFlaky, wantState: notify.StateClosed, wantCalls: 2},
+			{wantState: notify.StateClosed, wantCalls: 3},
+			{err: errFlaky, wantErr: errFlaky, wantState: notify.StateClosed, wantCalls: 4},
+			{err: errFlaky, wantErr: errFlaky, wantState: notify.StateClosed, wantCalls: 5},
+		}},
+		{"opens at the threshold and fails fast", []step{
+			{err: errFlaky, wantErr: errFlaky, wantState: notify.StateClosed, wantCalls: 1},
+			{err: errFlaky, wantErr: errFlaky, wantState: notify.StateClosed, wantCalls: 2},
+			{err: errFlaky, wantErr: errFlaky, wantState: notify.StateOpen, wantCalls: 3},
+			{wantErr: notify.ErrCircuitOpen, wantState: notify.StateOpen, wantCalls: 3},
+			{advance: 59 * time.Second, wantErr: notify.ErrCircuitOpen, wantState: notify.StateOpen, wantCalls: 3},
+		}},
+		{"successful probe closes", []step{
+			{err: errFlaky, wantErr: errFlaky, wantCalls: 1},
+			{err: errFlaky, wantErr: errFlaky, wantCalls: 2},
+			{err: errFlaky, wantErr: errFlaky, wantState: notify.StateOpen, wantCalls: 3},
+			{advance: time.Minute, wantState: notify.StateClosed, wantCalls: 4},
+			{err: errFlaky, wantErr: errFlaky, wantState: notify.StateClosed, wantCalls: 5},
+		}},
+		{"failed probe reopens", []step{
+			{err: errFlaky, wantErr: errFlaky, wantCalls: 1},
+			{err: errFlaky, wantErr: errFlaky, wantCalls: 2},
+			{err: errFlaky, wantErr: errFlaky, wantState: notify.StateOpen, wantCalls: 3},
+			{advance: time.Minute, err: errFlaky, wantErr: errFlaky, wantState: notify.StateOpen, wantCalls: 4},
+			{advance: 30 * time.Second, wantErr: notify.ErrCircuitOpen, wantState: notify.StateOpen, wantCalls: 4},
+			{advance: 30 * time.Second, wantState: notify.StateClosed, wantCalls: 5},
+		}},
+		{"permanent errors count as successes", []step{
+			{err: errFlaky, wantErr: errFlaky, wantCalls: 1},
+			{err: errFlaky, wantErr: errFlaky, wantCalls: 2},
+			{err: permanent, wantErr: permanent, wantState: notify.StateClosed, wantCalls: 3},
+			{err: errFlaky, wantErr: errFlaky, wantState: notify.StateClosed, wantCalls: 4},
+		}},
+		{"the caller's context errors are neutral", []step{
+			{err: errFlaky, wantErr: errFlaky, wantCalls: 1},
+			{err: errFlaky, wantErr: errFlaky, wantCalls: 2},
+			{done: true, err: context.Canceled, wantErr: context.Canceled, wantState: notify.StateClosed, wantCalls: 3},
+			{done: true, err: notify.ErrNotifyTimeout, wantErr: notify.ErrNotifyTimeout, wantState: notify.StateClosed, wantCalls: 4},
+			{err: errFlaky, wantErr: errFlaky, wantState: notify.StateOpen, wantCalls: 5},
+			{advance: time.Minute, done: true, err: context.Canceled, wantErr: context.Canceled, wantState: notify.StateHalfOpen, wantCalls: 6},
+			{wantState: notify.StateClosed, wantCalls: 7},
+		}},
+		{"per-call timeouts are failures", []step{
+			{err: errFlaky, wantErr: errFlaky, wantCalls: 1},
+			{err: notify.ErrNotifyTimeout, wantErr: notify.ErrNotifyTimeout, wantState: notify.StateClosed, wantCalls: 2},
+			{err: context.DeadlineExceeded, wantErr: context.DeadlineExceeded, wantState: notify.StateOpen, wantCalls: 3},
+			{advance: time.Minute, err: notify.ErrNotifyTimeout, wantErr: notify.ErrNotifyTimeout, wantState: notify.StateOpen, wantCalls: 4},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var errs []error
+			for _, s := range tt.steps {
+				if !errors.Is(s.wantErr, notify.ErrCircuitOpen) {
+					errs = append(errs, s.err)
+				}
+			}
+			mock := &notify.MockNotifier{Errs: errs}
+			clock := newFakeClock()
+			cb := notify.NewCircuitBreaker(mock,
+				notify.WithFailureThreshold(3),
+				notify.WithCooldown(time.Minute),
+				notify.WithBreakerClock(clock))
+
+			done, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			for i, s := range tt.steps {
+				clock.Advance(s.advance)
+				ctx := context.Background()
+				if s.done {
+					ctx = done
+				}
+				if err := cb.Notify(ctx); !errors.Is(err, s.wantErr) {
+					t.Fatalf("step %d: Notify() = %v, want %v", i, err, s.wantErr)
+				}
+				if got := cb.State(); got != s.wantState {
+					t.Fatalf("step %d: State() = %v, want %v", i, got, s.wantState)
+				}
+				if got := mock.Calls(); got != s.wantCalls {
+					t.Fatalf("step %d: notifier called %d times, want %d", i, got, s.wantCalls)
+				}
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerOpensOnHangingNotifier(t *testing.T) {
+	var calls atomic.Int32
+	hanging := notify.NotifierFunc(func(ctx context.Context) error {
+		calls.Add(1)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	cb := notify.NewCircuitBreaker(notify.WithTimeout(hanging, 5*time.Millisecond),
+		notify.WithFailureThreshold(3),
+		notify.WithCooldown(time.Minute),
+		notify.WithBreakerClock(newFakeClock()))
+
+	for i := range 3 {
+		if err := cb.Notify(context.Background()); !errors.Is(err, notify.ErrNotifyTimeout) {
+			t.Fatalf("call %d: Notify() = %v, want %v", i+1, err, notify.ErrNotifyTimeout)
+		}
+	}
+	if got := cb.State(); got != notify.StateOpen {
+		t.Fatalf("State() after 3 timeouts = %v, want %v", got, notify.StateOpen)
+	}
+	if err := cb.Notify(context.Background()); !errors.Is(err, notify.ErrCircuitOpen) {
+		t.Errorf("Notify() once open = %v, want %v", err, notify.ErrCircuitOpen)
+	}
+	if got := calls.Load(); got != 3 {
+		t.Errorf("hanging notifier called %d times, want 3", got)
+	}
+}
+
+func TestCircuitBreakerOptions(t *testing.T) {
+	tests := []struct {
+		name         string
+		opts         []notify.BreakerOption
+		wantOpenedBy int
+		wantCooldown time.Duration
+	}{
+		{"defaults", nil, 5, 30 * time.Second},
+		{"configured", []notify.BreakerOption{notify.WithFailureThreshold(2), notify.WithCooldown(time.Hour)}, 2, time.Hour},
+		{"threshold at least one", []notify.BreakerOption{notify.WithFailureThreshold(0)}, 1, 30 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := newFakeClock()
+			cb := notify.NewCircuitBreaker(&notify.MockNotifier{Err: errFlaky},
+				append(tt.opts, notify.WithBreakerClock(clock))...)
+
+			for i := 1; i <= tt.wantOpenedBy; i++ {
+				if got := cb.State(); got != notify.StateClosed {
+					t.Fatalf("State() before failure %d = %v, want %v", i, got, notify.StateClosed)
+				}
+				cb.Notify(context.Background())
+			}
+			if got := cb.State(); got != notify.StateOpen {
+				t.Fatalf("State() after %d failures = %v, want %v", tt.wantOpenedBy, got, notify.StateOpen)
+			}
+
+			clock.Advance(tt.wantCooldown - time.Millisecond)
+			if got := cb.State(); got != notify.StateOpen {
+				t.Errorf("State() just before the cooldown = %v, want %v", got, notify.StateOpen)
+			}
+			clock.Advance(time.Millisecond)
+			if got := cb.State(); got != notify.StateHalfOpen {
+				t.Errorf("State() after the cooldown = %v, want %v", got, notify.StateHalfOpen)
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerUnwrap(t *testing.T) {
+	mock := &notify.MockNotifier{}
+	if got := notify.NewCircuitBreaker(mock).Unwrap(); got != mock {
+		t.Errorf("Unwrap() = %v, want the wrapped notifier", got)
+	}
+}
+
+// gated is a Notifier whose calls each block until the test releases them:
+// every call hands the test a channel on calls and returns the error sent
+// on it.
+type gated struct {
+	calls chan chan error
+}
+
+func newGated() *gated {
+	return &gated{calls: make(chan chan error)}
+}
+
+func (g *gated) Notify(ctx context.Context) error {
+	release := make(chan error)
+	g.calls <- release
+	return <-release
+}
+
+// start runs cb.Notify in its own goroutine and waits until it reaches the
+// gated notifier, returning the call's release channel and the channel its
+// result arrives on.
+func (g *gated) start(cb notify.Notifier) (release chan<- error, result <-chan error) {
+	done := make(chan error, 1)
+	go func() { done <- cb.Notify(context.Background()) }()
+
+	return <-g.calls, done
+}
+
+func TestCircuitBreakerIgnoresStaleResults(t *testing.T) {
+	tests := []struct {
+		name      string
+		stale     error
+		probe     error
+		wantState notify.BreakerState
+	}{
+		{"stale success can't close", nil, errFlaky, notify.StateOpen},
+		{"stale failure can't reopen", errFlaky, nil, notify.StateClosed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newGated()
+			clock := newFakeClock()
+			cb := notify.NewCircuitBreaker(g,
+				notify.WithFailureThreshold(1),
+				notify.WithCooldown(time.Minute),
+				notify.WithBreakerClock(clock))
+
+			// A slow call starts while the breaker is closed, then a quick
+			// failure opens it and the cooldown lets a probe start.
+			releaseStale, stale := g.start(cb)
+			release, result := g.start(cb)
+			release <- errFlaky
+			if err := <-result; !errors.Is(err, errFlaky) {
+				t.Fatalf("Notify() = %v, want %v", err, errFlaky)
+			}
+			clock.Advance(time.Minute)
+			releaseProbe, probe := g.start(cb)
+
+			// The slow call finishing must leave the probe in charge.
+			releaseStale <- tt.stale
+			<-stale
+			if got := cb.State(); got != notify.StateHalfOpen {
+				t.Fatalf("State() after the stale result = %v, want %v", got, notify.StateHalfOpen)
+			}
+			if err := cb.Notify(context.Background()); !errors.Is(err, notify.ErrCircuitOpen) {
+				t.Fatalf("Notify() during the probe = %v, want %v", err, notify.ErrCircuitOpen)
+			}
+
+			releaseProbe <- tt.probe
+			<-probe
+			if got := cb.State(); got != tt.wantState {
+				t.Errorf("State() after the probe = %v, want %v", got, tt.wantState)
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerConcurrentCalls(t *testing.T) {
+	const callers = 50
+
+	t.Run("open", func(t *testing.T) {
+		mock := &notify.MockNotifier{Err: errFlaky}
+		cb := notify.NewCircuitBreaker(mock, notify.WithFailureThreshold(1), notify.WithBreakerClock(newFakeClock()))
+		cb.Notify(context.Background())
+
+		var wg sync.WaitGroup
+		errs := make(chan error, callers)
+		for range callers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs <- cb.Notify(context.Background())
+			}()
+		}
+		wg.Wait()
+		close(errs)
+
+		for err := range errs {
+			if !errors.Is(err, notify.ErrCircuitOpen) {
+				t.Errorf("Notify() while open = %v, want %v", err, notify.ErrCircuitOpen)
+			}
+		}
+		if mock.Calls() != 1 {
+			t.Errorf("notifier called %d times, want only the call that opened it", mock.Calls())
+		}
+	})
+
+	t.Run("half-open", func(t *testing.T) {
+		g := newGated()
+		clock := newFakeClock()
+		cb := notify.NewCircuitBreaker(g,
+			notify.WithFailureThreshold(1),
+			notify.WithCooldown(time.Minute),
+			notify.WithBreakerClock(clock))
+		release, result := g.start(cb)
+		release <- errFlaky
+		<-result
+		clock.Advance(time.Minute)
+
+		var wg sync.WaitGroup
+		errs := make(chan error, callers)
+		for range callers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs <- cb.Notify(context.Background())
+			}()
+		}
+
+		// Exactly one caller becomes the probe; the rest fail fast.
+		releaseProbe := <-g.calls
+		for range callers - 1 {
+			if err := <-errs; !errors.Is(err, notify.ErrCircuitOpen) {
+				t.Errorf("Notify() beside the probe = %v, want %v", err, notify.ErrCircuitOpen)
+			}
+		}
+		releaseProbe <- nil
+		wg.Wait()
+
+		if err := <-errs; err != nil {
+			t.Errorf("probe Notify() = %v, want nil", err)
+		}
+		if got := cb.State(); got != notify.StateClosed {
+			t.Errorf("State() after the probe = %v, want %v", got, notify.StateClosed)
+		}
+	})
+}
+
+func TestBreakerStateString(t *testing.T) {
+	tests := []struct {
+		state notify.BreakerState
+		want  string
+	}{
+		{notify.StateClosed, "closed"},
+		{notify.StateOpen, "open"},
+		{notify.StateHalfOpen, "half-open"},
+		{notify.BreakerState(42), "unknown"},
+	}
+	for _, tt := range tests {
+		if got := tt.state.String(); got != tt.want {
+			t.Errorf("BreakerState(%d).String() = %q, want %q", tt.state, got, tt.want)
+		}
+	}
+}
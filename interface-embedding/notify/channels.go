@@ -0,0 +1,92 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+)
+
+// e164 matches phone numbers in E.164 format: a leading plus sign followed
+// by up to fifteen digits, the first of which is not zero.
+var e164 = regexp.MustCompile(`^\+[1-9][0-9]{1,14}$`)
+
+// SMSUser is a User who is notified by text message instead of email.
+type SMSUser struct {
+	User
+	Phone string
+}
+
+// Notify texts the user, or reports why the phone number can't be used.
+func (s *SMSUser) Notify(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return &NotificationError{Recipient: s.Phone, Channel: "sms", Err: err}
+	}
+	if err := s.Validate(); err != nil {
+		return err
+	}
+
+	msg, err := limitBody(ctx, fmt.Sprintf("Sending user SMS to %s<%s>", Sanitize(s.Name), s.Phone))
+	if err != nil {
+		return &NotificationError{Recipient: s.Phone, Channel: "sms", Err: err}
+	}
+
+	if err := s.writeLine(ctx, msg); err != nil {
+		return &NotificationError{Recipient: s.Phone, Channel: "sms", Retryable: true, Err: err}
+	}
+
+	return nil
+}
+
+// Address reports the user's phone number and the SMS channel.
+func (s *SMSUser) Address() (recipient, channel string) {
+	return s.Phone, "sms"
+}
+
+// NotificationKey identifies the text message by phone number, replacing
+// the email key promoted from User.
+func (s *SMSUser) NotificationKey() string {
+	return "sms:" + s.Phone
+}
+
+// SlackUser is a User who is notified through a Slack incoming webhook.
+type SlackUser struct {
+	User
+	WebhookURL string
+	Channel    string
+}
+
+// Notify posts to the user's Slack channel, or reports why the webhook
+// configuration can't be used.
+func (s *SlackUser) Notify(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return &NotificationError{Recipient: s.Channel, Channel: "slack", Err: err}
+	}
+	if err := s.Validate(); err != nil {
+		return err
+	}
+
+	// Validate has already checked that the webhook URL parses.
+	u, _ := url.Parse(s.WebhookURL)
+	msg, err := limitBody(ctx, fmt.Sprintf("Sending user Slack message to %s<%s> via %s", Sanitize(s.Name), Sanitize(s.Channel), u.Host))
+	if err != nil {
+		return &NotificationError{Recipient: s.Channel, Channel: "slack", Err: err}
+	}
+
+	if err := s.writeLine(ctx, msg); err != nil {
+		return &NotificationError{Recipient: s.Channel, Channel: "slack", Retryable: true, Err: err}
+	}
+
+	return nil
+}
+
+// Address reports the user's Slack channel and the Slack channel kind.
+func (s *SlackUser) Address() (recipient, channel string) {
+	return s.Channel, "slack"
+}
+
+// NotificationKey identifies the Slack message by channel, replacing the
+// email key promoted from User.
+func (s *SlackUser) NotificationKey() string {
+	return "slack:" + s.Channel
+}
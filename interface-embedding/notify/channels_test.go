@@ -0,0 +1,63 @@
+package notify_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+func TestChannels(t *testing.T) {
+	var buf bytes.Buffer
+	user := func() notify.User {
+		return notify.User{Name: "Janet Jones", Email: "janet@email.com"}
+	}
+	sms := func(phone string) *notify.SMSUser {
+		s := &notify.SMSUser{User: user(), Phone: phone}
+		s.SetOutput(&buf)
+		return s
+	}
+	slack := func(webhook, channel string) *notify.SlackUser {
+		s := &notify.SlackUser{User: user(), WebhookURL: webhook, Channel: channel}
+		s.SetOutput(&buf)
+		return s
+	}
+
+	tests := []struct {
+		name    string
+		n       notify.Notifier
+		want    string
+		wantErr bool
+	}{
+		{"sms", sms("+14155552671"), "Sending user SMS to Janet Jones<+14155552671>\n", false},
+		{"sms without plus", sms("14155552671"), "", true},
+		{"sms leading zero", sms("+04155552671"), "", true},
+		{"sms too long", sms("+1234567890123456"), "", true},
+		{"sms letters", sms("+1415555CALL"), "", true},
+		{"slack", slack("https://hooks.slack.com/services/T0/B0/X", "#ops"),
+			"Sending user Slack message to Janet Jones<#ops> via hooks.slack.com\n", false},
+		{"slack http", slack("http://hooks.slack.com/services/T0/B0/X", "#ops"), "", true},
+		{"slack no host", slack("https:///services", "#ops"), "", true},
+		{"slack bad url", slack("://", "#ops"), "", true},
+		{"slack no channel", slack("https://hooks.slack.com/services/T0/B0/X", ""), "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+			err := tt.n.Notify(context.Background())
+			if tt.wantErr {
+				var ne *notify.NotificationError
+				if !errors.As(err, &ne) || !errors.Is(err, notify.ErrInvalidRecipient) {
+					t.Errorf("Notify() = %v, want an invalid recipient *NotificationError", err)
+				}
+			} else if err != nil {
+				t.Errorf("Notify() = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package notify
+
+import (
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// Clock tells the time and waits for it to pass. Components that schedule
+// work take a Clock so tests can substitute one they control.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// SystemClock is the Clock backed by the time package.
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// Rand is a source of random numbers. Components that randomize, such as
+// jittered backoff, take a Rand so simulations can make them repeatable.
+type Rand interface {
+	// Int64N returns a number in [0, n). It panics if n <= 0.
+	Int64N(n int64) int64
+}
+
+// SystemRand is the Rand backed by math/rand/v2's global source.
+var SystemRand Rand = systemRand{}
+
+type systemRand struct{}
+
+func (systemRand) Int64N(n int64) int64 { return rand.Int64N(n) }
+
+// seededRand is a Rand with a fixed seed, safe for concurrent use.
+type seededRand struct {
+	mu sync.Mutex
+	r  *rand.Rand
+}
+
+// NewSeededRand returns a Rand that produces the same sequence every time
+// for the same seed.
+func NewSeededRand(seed uint64) Rand {
+	return &seededRand{r: rand.New(rand.NewPCG(seed, seed))}
+}
+
+func (s *seededRand) Int64N(n int64) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.r.Int64N(n)
+}
+
+// SimClock is a Clock for simulations, where time only passes when
+// something waits on it. After moves the clock forward by the wait and
+// returns a channel that is already ready, so code that waits in sequence
+// runs instantly and the times it sees are the same on every run. The
+// zero value starts at the zero time; it is safe for concurrent use, but
+// concurrent waits each move the clock on.
+type SimClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewSimClock returns a SimClock reading start.
+func NewSimClock(start time.Time) *SimClock {
+	return &SimClock{now: start}
+}
+
+// Now returns the simulated time.
+func (c *SimClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+// After advances the clock by d, if it is positive, and returns a channel
+// holding the new time.
+func (c *SimClock) After(d time.Duration) <-chan time.Time {
+	ch := make(chan time.Time, 1)
+	ch <- c.Advance(d)
+
+	return ch
+}
+
+// Advance moves the clock forward by d, if it is positive, and returns the
+// new time.
+func (c *SimClock) Advance(d time.Duration) time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if d > 0 {
+		c.now = c.now.Add(d)
+	}
+
+	return c.now
+}
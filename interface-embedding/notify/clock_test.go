@@ -0,0 +1,243 @@
+package notify_test
+
+import (
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+// fakeClock is a Clock whose time only moves when the test calls Advance,
+// firing any waits that have fallen due.
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []fakeWaiter
+	changed chan struct{}
+}
+
+type fakeWaiter struct {
+	at time.Time
+	ch chan time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{
+		now:     time.Date(2026, time.January, 1, 9, 0, 0, 0, time.UTC),
+		changed: make(chan struct{}),
+	}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+	c.waiters = append(c.waiters, fakeWaiter{at: c.now.Add(d), ch: ch})
+	c.notifyLocked()
+
+	return ch
+}
+
+// Advance moves the clock forward by d and fires the waits due by then.
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+	kept := c.waiters[:0]
+	for _, w := range c.waiters {
+		if w.at.After(c.now) {
+			kept = append(kept, w)
+			continue
+		}
+		w.ch <- c.now
+	}
+	c.waiters = kept
+	c.notifyLocked()
+}
+
+// BlockUntil waits until n waits are pending, or gives up after a few
+// seconds and reports false.
+func (c *fakeClock) BlockUntil(n int) bool {
+	timeout := time.After(5 * time.Second)
+	for {
+		c.mu.Lock()
+		pending, changed := len(c.waiters), c.changed
+		c.mu.Unlock()
+		if pending >= n {
+			return true
+		}
+
+		select {
+		case <-changed:
+		case <-timeout:
+			return false
+		}
+	}
+}
+
+// pending returns how many waits haven't fired yet.
+func (c *fakeClock) pending() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.waiters)
+}
+
+func (c *fakeClock) notifyLocked() {
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
+
+func TestSimClock(t *testing.T) {
+	start := time.Date(2026, time.January, 1, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		waits []time.Duration
+		want  time.Duration
+	}{
+		{"no waits", nil, 0},
+		{"waits add up", []time.Duration{time.Second, time.Minute, time.Millisecond}, time.Minute + time.Second + time.Millisecond},
+		{"non-positive waits don't move it", []time.Duration{-time.Hour, 0, time.Second}, time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := notify.NewSimClock(start)
+			for _, d := range tt.waits {
+				// After is ready at once, holding the time it moved to.
+				select {
+				case got := <-c.After(d):
+					if got != c.Now() {
+						t.Errorf("After(%v) fired with %v, want the clock's new time %v", d, got, c.Now())
+					}
+				default:
+					t.Fatalf("After(%v) isn't ready straight away", d)
+				}
+			}
+			if got := c.Now().Sub(start); got != tt.want {
+				t.Errorf("clock moved %v, want %v", got, tt.want)
+			}
+			if got := c.Advance(time.Hour); got != start.Add(tt.want+time.Hour) {
+				t.Errorf("Advance(1h) = %v, want %v", got, start.Add(tt.want+time.Hour))
+			}
+		})
+	}
+
+	var zero notify.SimClock
+	if !zero.Now().IsZero() {
+		t.Errorf("zero SimClock reads %v, want the zero time", zero.Now())
+	}
+}
+
+func TestSimClockConcurrent(t *testing.T) {
+	c := notify.NewSimClock(time.Time{})
+	const goroutines, waits = 8, 100
+	var wg sync.WaitGroup
+	for range goroutines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range waits {
+				<-c.After(time.Millisecond)
+				c.Now()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Concurrent waits each move the clock on.
+	if got, want := c.Now().Sub(time.Time{}), goroutines*waits*time.Millisecond; got != want {
+		t.Errorf("clock moved %v, want %v", got, want)
+	}
+}
+
+func TestNewSeededRand(t *testing.T) {
+	draw := func(r notify.Rand) []int64 {
+		var out []int64
+		for i := range 50 {
+			out = append(out, r.Int64N(int64(i)+1))
+		}
+		return out
+	}
+
+	tests := []struct {
+		name   string
+		a, b   notify.Rand
+		wantEq bool
+	}{
+		{"same seed", notify.NewSeededRand(42), notify.NewSeededRand(42), true},
+		{"different seeds", notify.NewSeededRand(1), notify.NewSeededRand(2), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := draw(tt.a), draw(tt.b)
+			if slices.Equal(a, b) != tt.wantEq {
+				t.Errorf("sequences %v and %v, want equal %v", a, b, tt.wantEq)
+			}
+			for i, v := range a {
+				if v < 0 || v > int64(i) {
+					t.Errorf("Int64N(%d) = %d, want it in [0, %d)", i+1, v, i+1)
+				}
+			}
+		})
+	}
+}
+
+func TestRandInRange(t *testing.T) {
+	for _, r := range []notify.Rand{notify.SystemRand, notify.NewSeededRand(7)} {
+		for range 1000 {
+			if v := r.Int64N(10); v < 0 || v >= 10 {
+				t.Fatalf("%T.Int64N(10) = %d, want it in [0, 10)", r, v)
+			}
+		}
+	}
+}
+
+func TestSeededRandConcurrent(t *testing.T) {
+	// The draws interleave differently from run to run, but together they
+	// are the seed's sequence, so the same values come out.
+	const goroutines, draws = 8, 100
+	want := notify.NewSeededRand(3)
+	var wantSum int64
+	for range goroutines * draws {
+		wantSum += want.Int64N(1000)
+	}
+
+	r := notify.NewSeededRand(3)
+	var (
+		mu  sync.Mutex
+		sum int64
+		wg  sync.WaitGroup
+	)
+	for range goroutines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range draws {
+				v := r.Int64N(1000)
+				mu.Lock()
+				sum += v
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if sum != wantSum {
+		t.Errorf("concurrent draws sum to %d, want %d as drawn in sequence", sum, wantSum)
+	}
+}
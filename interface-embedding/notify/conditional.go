@@ -0,0 +1,43 @@
+package notify
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// ConditionalNotifier sends through the embedded Notifier only when its
+// condition holds at the time of the call, such as outside a maintenance
+// window or for users who haven't opted out.
+type ConditionalNotifier struct {
+	Notifier
+
+	when    func(ctx context.Context) bool
+	skipped atomic.Int64
+}
+
+// NewConditionalNotifier wraps n so that each Notify first asks when
+// whether to send.
+func NewConditionalNotifier(n Notifier, when func(ctx context.Context) bool) *ConditionalNotifier {
+	return &ConditionalNotifier{Notifier: n, when: when}
+}
+
+// Notify calls the embedded Notifier if the condition holds, and otherwise
+// counts the notification as skipped and returns nil.
+func (c *ConditionalNotifier) Notify(ctx context.Context) error {
+	if !c.when(ctx) {
+		c.skipped.Add(1)
+		return nil
+	}
+
+	return c.Notifier.Notify(ctx)
+}
+
+// Skipped returns how many notifications the condition has suppressed.
+func (c *ConditionalNotifier) Skipped() int {
+	return int(c.skipped.Load())
+}
+
+// Unwrap returns the notifier being guarded.
+func (c *ConditionalNotifier) Unwrap() Notifier {
+	return c.Notifier
+}
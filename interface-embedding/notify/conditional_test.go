@@ -0,0 +1,93 @@
+package notify_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+func TestConditionalNotifier(t *testing.T) {
+	tests := []struct {
+		name        string
+		when        func() func(context.Context) bool
+		calls       int
+		wantSent    int
+		wantSkipped int
+	}{
+		{"always true", func() func(context.Context) bool {
+			return func(context.Context) bool { return true }
+		}, 3, 3, 0},
+		{"always false", func() func(context.Context) bool {
+			return func(context.Context) bool { return false }
+		}, 3, 0, 3},
+		{"flips between calls", func() func(context.Context) bool {
+			send := false
+			return func(context.Context) bool {
+				send = !send
+				return send
+			}
+		}, 5, 3, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &notify.MockNotifier{}
+			c := notify.NewConditionalNotifier(mock, tt.when())
+			for range tt.calls {
+				if err := c.Notify(context.Background()); err != nil {
+					t.Fatalf("Notify() = %v", err)
+				}
+			}
+			if mock.Calls() != tt.wantSent || c.Skipped() != tt.wantSkipped {
+				t.Errorf("sent %d and skipped %d, want %d and %d", mock.Calls(), c.Skipped(), tt.wantSent, tt.wantSkipped)
+			}
+		})
+	}
+}
+
+func TestConditionalNotifierContext(t *testing.T) {
+	type optOutKey struct{}
+	errDown := errors.New("server down")
+	c := notify.NewConditionalNotifier(&notify.MockNotifier{Err: errDown}, func(ctx context.Context) bool {
+		return ctx.Value(optOutKey{}) == nil
+	})
+
+	if err := c.Notify(context.WithValue(context.Background(), optOutKey{}, true)); err != nil {
+		t.Errorf("Notify() of an opted-out send = %v, want nil", err)
+	}
+	if err := c.Notify(context.Background()); !errors.Is(err, errDown) {
+		t.Errorf("Notify() = %v, want the inner error %v", err, errDown)
+	}
+	if got := (notify.Wrapper)(c).Unwrap(); got == nil {
+		t.Error("Unwrap() = nil, want the guarded notifier")
+	}
+}
+
+func TestConditionalNotifierConcurrent(t *testing.T) {
+	mock := &notify.MockNotifier{}
+	var mu sync.Mutex
+	n := 0
+	c := notify.NewConditionalNotifier(mock, func(context.Context) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		n++
+		return n%2 == 0
+	})
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 50 {
+				c.Notify(context.Background())
+			}
+		}()
+	}
+	wg.Wait()
+	if mock.Calls() != 200 || c.Skipped() != 200 {
+		t.Errorf("sent %d and skipped %d, want 200 each", mock.Calls(), c.Skipped())
+	}
+}
@@ -0,0 +1,403 @@
+package notify
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config describes a notification pipeline: the channel that delivers and
+// the wrappers around it. LoadConfig reads one from JSON, such as
+//
+//	{
+//	  "channel": "smtp",
+//	  "recipient": {"name": "Janet Jones", "email": "janet@email.com"},
+//	  "smtp": {"host": "smtp.email.com", "from": "alerts@email.com"},
+//	  "retry": {"attempts": 5},
+//	  "timeout": "10s"
+//	}
+//
+// Sections that are left out are not used.
+type Config struct {
+	// Channel is "smtp" or the name of a registered channel, such as
+	// "email", "sms", "slack" or "webhook". It defaults to "email".
+	Channel   string          `json:"channel"`
+	Recipient RecipientConfig `json:"recipient"`
+
+	SMTP    *SMTPConfig    `json:"smtp"`
+	Webhook *WebhookConfig `json:"webhook"`
+
+	RateLimit  *RateLimitConfig  `json:"rate_limit"`
+	Retry      *RetryConfig      `json:"retry"`
+	Timeout    Duration          `json:"timeout"`
+	QuietHours *QuietHoursConfig `json:"quiet_hours"`
+
+	// Warnings lists problems LoadConfig found that don't stop the config
+	// being used, such as unknown keys.
+	Warnings []string `json:"-"`
+}
+
+// RecipientConfig is who the pipeline notifies. Level makes an email
+// recipient an admin, and is only supported by the email and webhook
+// channels; Phone and SlackChannel are for the sms and slack channels.
+type RecipientConfig struct {
+	Name         string `json:"name"`
+	Email        string `json:"email"`
+	Level        string `json:"level"`
+	Phone        string `json:"phone"`
+	SlackChannel string `json:"slack_channel"`
+}
+
+// SMTPConfig configures the smtp channel. Port defaults to 587.
+type SMTPConfig struct {
+	Host string `json:"host"`
+	Port int    `json:"port"`
+	From string `json:"from"`
+}
+
+// WebhookConfig configures the webhook and slack channels. Secret only
+// applies to webhooks.
+type WebhookConfig struct {
+	URL    string `json:"url"`
+	Secret string `json:"secret"`
+}
+
+// RateLimitConfig caps the pipeline's send rate. Burst defaults to 1.
+type RateLimitConfig struct {
+	PerSecond float64 `json:"per_second"`
+	Burst     int     `json:"burst"`
+}
+
+// RetryConfig retries failed sends. Attempts defaults to 3 and BaseDelay
+// to 100ms.
+type RetryConfig struct {
+	Attempts  int      `json:"attempts"`
+	BaseDelay Duration `json:"base_delay"`
+}
+
+// QuietHoursConfig holds notifications during the recipient's quiet
+// hours. Window is written as "22:00-07:00"; Location is an IANA time zone
+// and defaults to UTC; Mode is "defer", the default, or "reject".
+type QuietHoursConfig struct {
+	Window   string `json:"window"`
+	Location string `json:"location"`
+	Mode     string `json:"mode"`
+}
+
+// Duration is a time.Duration written in JSON as a string such as "5s".
+type Duration time.Duration
+
+// UnmarshalJSON parses a duration string with time.ParseDuration.
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string such as \"5s\"")
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+
+	return nil
+}
+
+// MarshalJSON formats the duration as time.Duration.String does.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// ConfigError is a problem with one setting of a Config, named by its
+// path, such as "smtp.host".
+type ConfigError struct {
+	Path   string
+	Reason string
+}
+
+// Error implements the error interface.
+func (e ConfigError) Error() string {
+	return "notify: config " + e.Path + ": " + e.Reason
+}
+
+// maxConfigBytes bounds how much LoadConfig reads; real configs are a few
+// hundred bytes.
+const maxConfigBytes = 1 << 20
+
+// LoadConfig reads a Config from JSON, fills in defaults and validates it.
+// Keys it doesn't recognize are reported in Warnings rather than failing
+// the load. Validation problems are returned together, each as a
+// ConfigError.
+func LoadConfig(r io.Reader) (Config, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxConfigBytes+1))
+	if err != nil {
+		return Config{}, fmt.Errorf("notify: read config: %w", err)
+	}
+	if len(data) > maxConfigBytes {
+		return Config{}, fmt.Errorf("notify: read config: larger than %d bytes", maxConfigBytes)
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return Config{}, fmt.Errorf("notify: parse config: %w", err)
+	}
+	var raw any
+	json.Unmarshal(data, &raw)
+	cfg.Warnings = unknownKeys("", raw, reflect.TypeFor[Config]())
+
+	cfg.setDefaults()
+	if err := cfg.Validate(); err != nil {
+		return cfg, err
+	}
+
+	return cfg, nil
+}
+
+// setDefaults fills in the defaults documented on each section.
+func (c *Config) setDefaults() {
+	if c.Channel == "" {
+		c.Channel = "email"
+	}
+	if c.SMTP != nil && c.SMTP.Port == 0 {
+		c.SMTP.Port = 587
+	}
+	if c.RateLimit != nil && c.RateLimit.Burst == 0 {
+		c.RateLimit.Burst = 1
+	}
+	if c.Retry != nil {
+		if c.Retry.Attempts == 0 {
+			c.Retry.Attempts = 3
+		}
+		if c.Retry.BaseDelay == 0 {
+			c.Retry.BaseDelay = Duration(100 * time.Millisecond)
+		}
+	}
+	if c.QuietHours != nil {
+		if c.QuietHours.Location == "" {
+			c.QuietHours.Location = "UTC"
+		}
+		if c.QuietHours.Mode == "" {
+			c.QuietHours.Mode = "defer"
+		}
+	}
+}
+
+// Validate checks that the settings the channel and wrappers need are
+// present and usable, returning every problem found.
+func (c *Config) Validate() error {
+	var errs []error
+	problem := func(path, reason string) {
+		errs = append(errs, ConfigError{Path: path, Reason: reason})
+	}
+
+	if c.Recipient.Name == "" {
+		problem("recipient.name", "required")
+	}
+	if c.Recipient.Email == "" {
+		problem("recipient.email", "required")
+	}
+	if c.Recipient.Level != "" {
+		if _, err := ParseLevel(c.Recipient.Level); err != nil {
+			problem("recipient.level", reason(err))
+		}
+		if c.Channel == "smtp" || c.Channel == "sms" || c.Channel == "slack" {
+			problem("recipient.level", "not supported by the "+c.Channel+" channel")
+		}
+	}
+
+	switch c.Channel {
+	case "smtp":
+		switch {
+		case c.SMTP == nil:
+			problem("smtp", "required for the smtp channel")
+		default:
+			if c.SMTP.Host == "" {
+				problem("smtp.host", "required")
+			}
+			if c.SMTP.Port < 1 || c.SMTP.Port > 65535 {
+				problem("smtp.port", fmt.Sprintf("%d is not a valid port", c.SMTP.Port))
+			}
+			if c.SMTP.From == "" {
+				problem("smtp.from", "required")
+			}
+		}
+	case "webhook", "slack":
+		if c.Webhook == nil || c.Webhook.URL == "" {
+			problem("webhook.url", "required for the "+c.Channel+" channel")
+		}
+		if c.Channel == "slack" && c.Recipient.SlackChannel == "" {
+			problem("recipient.slack_channel", "required for the slack channel")
+		}
+	case "sms":
+		if c.Recipient.Phone == "" {
+			problem("recipient.phone", "required for the sms channel")
+		}
+	default:
+		if !slices.Contains(Channels(), c.Channel) {
+			problem("channel", fmt.Sprintf("unknown channel %q", c.Channel))
+		}
+	}
+
+	if c.RateLimit != nil {
+		if c.RateLimit.PerSecond <= 0 {
+			problem("rate_limit.per_second", "must be positive")
+		}
+		if c.RateLimit.Burst < 1 {
+			problem("rate_limit.burst", "must be at least 1")
+		}
+	}
+	if c.Retry != nil {
+		if c.Retry.Attempts < 1 {
+			problem("retry.attempts", "must be at least 1")
+		}
+		if c.Retry.BaseDelay < 0 {
+			problem("retry.base_delay", "must not be negative")
+		}
+	}
+	if c.Timeout < 0 {
+		problem("timeout", "must not be negative")
+	}
+	if q := c.QuietHours; q != nil {
+		if _, err := ParseQuietHours(q.Window); err != nil {
+			problem("quiet_hours.window", reason(err))
+		}
+		if _, err := time.LoadLocation(q.Location); err != nil {
+			problem("quiet_hours.location", reason(err))
+		}
+		if q.Mode != "defer" && q.Mode != "reject" {
+			problem("quiet_hours.mode", fmt.Sprintf("%q is not defer or reject", q.Mode))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// reason is err's message without the package prefix, which ConfigError
+// already adds.
+func reason(err error) string {
+	return strings.TrimPrefix(err.Error(), "notify: ")
+}
+
+// BuildPipeline validates cfg and builds the notifier it describes, with
+// its wrappers in a fixed order from the outside in:
+//
+//	quiet hours → rate limit → retry → timeout → channel
+//
+// so that a notification held for quiet hours doesn't spend a rate limit
+// token, every retry is rate limited, and the timeout bounds each attempt
+// rather than all of them together. The options configure the recipient.
+func BuildPipeline(cfg Config, opts ...UserOption) (Notifier, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	var loc *time.Location
+	if cfg.QuietHours != nil {
+		loc, _ = time.LoadLocation(cfg.QuietHours.Location)
+		opts = append(opts, WithLocation(loc))
+	}
+
+	n, err := cfg.channel(opts)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Timeout > 0 {
+		n = WithTimeout(n, time.Duration(cfg.Timeout))
+	}
+	if r := cfg.Retry; r != nil {
+		n = WithRetry(n, r.Attempts, time.Duration(r.BaseDelay))
+	}
+	if r := cfg.RateLimit; r != nil {
+		n = NewRateLimitedNotifier(n, r.PerSecond, r.Burst)
+	}
+	if q := cfg.QuietHours; q != nil {
+		hours, _ := ParseQuietHours(q.Window)
+		var policy Policy
+		recipient, _ := AddressOf(n)
+		policy.SetQuietHours(recipient, hours)
+
+		mode := QuietDefer
+		if q.Mode == "reject" {
+			mode = QuietReject
+		}
+		n = NewPolicyNotifier(n, &policy, mode, nil)
+	}
+
+	return n, nil
+}
+
+// channel builds the notifier that delivers, before any wrappers.
+func (c *Config) channel(opts []UserOption) (Notifier, error) {
+	if c.Channel == "smtp" {
+		u, err := NewUser(c.Recipient.Name, c.Recipient.Email, opts...)
+		if err != nil {
+			return nil, err
+		}
+
+		return &SMTPNotifier{
+			Addr: net.JoinHostPort(c.SMTP.Host, strconv.Itoa(c.SMTP.Port)),
+			From: c.SMTP.From,
+			To:   u,
+		}, nil
+	}
+
+	settings := map[string]string{
+		"name":    c.Recipient.Name,
+		"email":   c.Recipient.Email,
+		"level":   c.Recipient.Level,
+		"phone":   c.Recipient.Phone,
+		"channel": c.Recipient.SlackChannel,
+	}
+	if c.Webhook != nil {
+		settings["url"] = c.Webhook.URL
+		settings["secret"] = c.Webhook.Secret
+	}
+
+	return NewNotifier(c.Channel, settings, opts...)
+}
+
+// unknownKeys returns a warning for each key in the decoded JSON value v
+// that has no matching field in the struct type t, looking into nested
+// sections. Keys match field names case-insensitively, as encoding/json
+// does.
+func unknownKeys(path string, v any, t reflect.Type) []string {
+	obj, ok := v.(map[string]any)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if !ok || t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	fields := make(map[string]reflect.Type)
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			fields[strings.ToLower(name)] = f.Type
+		}
+	}
+
+	var warnings []string
+	for _, key := range slices.Sorted(maps.Keys(obj)) {
+		keyPath := key
+		if path != "" {
+			keyPath = path + "." + key
+		}
+		ft, ok := fields[strings.ToLower(key)]
+		if !ok {
+			warnings = append(warnings, keyPath+": unknown key")
+			continue
+		}
+		warnings = append(warnings, unknownKeys(keyPath, obj[key], ft)...)
+	}
+
+	return warnings
+}
@@ -0,0 +1,208 @@
+package notify_test
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+// configPaths returns the paths of the ConfigErrors joined in err.
+func configPaths(err error) []string {
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return nil
+	}
+	var paths []string
+	for _, err := range joined.Unwrap() {
+		var ce notify.ConfigError
+		if errors.As(err, &ce) {
+			paths = append(paths, ce.Path)
+		}
+	}
+
+	return paths
+}
+
+func TestBuildPipeline(t *testing.T) {
+	cfg, err := notify.LoadConfig(strings.NewReader(`{
+	  "channel": "smtp",
+	  "recipient": {"name": "Janet Jones", "email": "janet@email.com"},
+	  "smtp": {"host": "smtp.email.com", "port": 2525, "from": "alerts@email.com"},
+	  "rate_limit": {"per_second": 5, "burst": 2},
+	  "retry": {"attempts": 4, "base_delay": "250ms"},
+	  "timeout": "10s",
+	  "quiet_hours": {"window": "22:00-07:00", "location": "Europe/London", "mode": "reject"}
+	}`))
+	if err != nil {
+		t.Fatalf("LoadConfig() = %v", err)
+	}
+	if len(cfg.Warnings) != 0 {
+		t.Errorf("Warnings = %q, want none", cfg.Warnings)
+	}
+
+	n, err := notify.BuildPipeline(cfg)
+	if err != nil {
+		t.Fatalf("BuildPipeline() = %v", err)
+	}
+	policy, ok := n.(*notify.PolicyNotifier)
+	if !ok {
+		t.Fatalf("outermost wrapper is %T, want *notify.PolicyNotifier", n)
+	}
+	limited, ok := policy.Unwrap().(*notify.RateLimitedNotifier)
+	if !ok {
+		t.Fatalf("inside quiet hours is %T, want *notify.RateLimitedNotifier", policy.Unwrap())
+	}
+	retry, ok := limited.Unwrap().(*notify.RetryNotifier)
+	if !ok || retry.MaxAttempts != 4 || retry.BaseDelay != 250*time.Millisecond {
+		t.Fatalf("inside the rate limit is %#v, want a RetryNotifier of 4 attempts from 250ms", limited.Unwrap())
+	}
+	timeout, ok := retry.Notifier.(*notify.TimeoutNotifier)
+	if !ok || timeout.Timeout != 10*time.Second {
+		t.Fatalf("inside the retry is %#v, want a 10s TimeoutNotifier", retry.Notifier)
+	}
+	smtpN, ok := timeout.Notifier.(*notify.SMTPNotifier)
+	if !ok {
+		t.Fatalf("the channel is %T, want *notify.SMTPNotifier", timeout.Notifier)
+	}
+	if smtpN.Addr != "smtp.email.com:2525" || smtpN.From != "alerts@email.com" || smtpN.To.Email != "janet@email.com" {
+		t.Errorf("SMTPNotifier = %+v, want the configured server and recipient", smtpN)
+	}
+	if loc := smtpN.To.Location(); loc.String() != "Europe/London" {
+		t.Errorf("recipient location = %v, want Europe/London", loc)
+	}
+}
+
+func TestLoadConfigDefaults(t *testing.T) {
+	cfg, err := notify.LoadConfig(strings.NewReader(`{
+	  "recipient": {"name": "Janet Jones", "email": "janet@email.com"},
+	  "rate_limit": {"per_second": 2},
+	  "retry": {},
+	  "quiet_hours": {"window": "22:00-07:00"}
+	}`))
+	if err != nil {
+		t.Fatalf("LoadConfig() = %v", err)
+	}
+
+	if cfg.Channel != "email" {
+		t.Errorf("Channel = %q, want email", cfg.Channel)
+	}
+	if cfg.RateLimit.Burst != 1 {
+		t.Errorf("RateLimit.Burst = %d, want 1", cfg.RateLimit.Burst)
+	}
+	if cfg.Retry.Attempts != 3 || cfg.Retry.BaseDelay != notify.Duration(100*time.Millisecond) {
+		t.Errorf("Retry = %+v, want 3 attempts from 100ms", cfg.Retry)
+	}
+	if cfg.QuietHours.Location != "UTC" || cfg.QuietHours.Mode != "defer" {
+		t.Errorf("QuietHours = %+v, want UTC and defer", cfg.QuietHours)
+	}
+	if cfg.SMTP != nil || cfg.Webhook != nil || cfg.Timeout != 0 {
+		t.Errorf("config = %+v, want unused sections left out", cfg)
+	}
+
+	cfg, err = notify.LoadConfig(strings.NewReader(`{
+	  "channel": "smtp",
+	  "recipient": {"name": "Janet Jones", "email": "janet@email.com"},
+	  "smtp": {"host": "smtp.email.com", "from": "alerts@email.com"}
+	}`))
+	if err != nil {
+		t.Fatalf("LoadConfig() = %v", err)
+	}
+	if cfg.SMTP.Port != 587 {
+		t.Errorf("SMTP.Port = %d, want 587", cfg.SMTP.Port)
+	}
+
+	// With no wrappers configured the pipeline is just the channel.
+	cfg, err = notify.LoadConfig(strings.NewReader(`{"recipient": {"name": "Janet Jones", "email": "janet@email.com"}}`))
+	if err != nil {
+		t.Fatalf("LoadConfig() = %v", err)
+	}
+	if n, err := notify.BuildPipeline(cfg); err != nil {
+		t.Errorf("BuildPipeline() = %v", err)
+	} else if _, ok := n.(*notify.User); !ok {
+		t.Errorf("BuildPipeline() = %T, want a bare *notify.User", n)
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	const janet = `"recipient": {"name": "Janet Jones", "email": "janet@email.com"}`
+
+	tests := []struct {
+		name      string
+		config    string
+		wantPaths []string
+		wantErr   string
+	}{
+		{"empty recipient", `{}`, []string{"recipient.name", "recipient.email"}, "notify: config recipient.name: required"},
+		{"smtp missing", `{"channel": "smtp", ` + janet + `}`, []string{"smtp"}, "notify: config smtp: required for the smtp channel"},
+		{"smtp fields missing", `{"channel": "smtp", ` + janet + `, "smtp": {"port": 70000}}`,
+			[]string{"smtp.host", "smtp.port", "smtp.from"}, "notify: config smtp.host: required"},
+		{"webhook url", `{"channel": "webhook", ` + janet + `}`, []string{"webhook.url"}, "required for the webhook channel"},
+		{"slack channel", `{"channel": "slack", ` + janet + `, "webhook": {"url": "https://hooks.slack.com/x"}}`,
+			[]string{"recipient.slack_channel"}, "required for the slack channel"},
+		{"sms phone", `{"channel": "sms", ` + janet + `}`, []string{"recipient.phone"}, "required for the sms channel"},
+		{"unknown channel", `{"channel": "fax", ` + janet + `}`, []string{"channel"}, `unknown channel "fax"`},
+		{"level", `{"channel": "sms", "recipient": {"name": "Janet", "email": "janet@email.com", "phone": "+15555550100", "level": "god"}}`,
+			[]string{"recipient.level", "recipient.level"}, "not supported by the sms channel"},
+		{"wrappers", `{` + janet + `, "rate_limit": {"per_second": -1, "burst": -1}, "retry": {"attempts": -1, "base_delay": "-1s"}, "timeout": "-1s"}`,
+			[]string{"rate_limit.per_second", "rate_limit.burst", "retry.attempts", "retry.base_delay", "timeout"}, "must be positive"},
+		{"quiet hours", `{` + janet + `, "quiet_hours": {"window": "22:00", "location": "Mars/Base", "mode": "snooze"}}`,
+			[]string{"quiet_hours.window", "quiet_hours.location", "quiet_hours.mode"}, `"snooze" is not defer or reject`},
+		{"invalid JSON", `{"channel":`, nil, "notify: parse config"},
+		{"bad duration", `{` + janet + `, "timeout": 5}`, nil, `duration must be a string such as "5s"`},
+		{"too large", `{"pad": "` + strings.Repeat("x", 1<<20) + `"}`, nil, "larger than"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := notify.LoadConfig(strings.NewReader(tt.config))
+			if err == nil {
+				t.Fatal("LoadConfig() = nil, want an error")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadConfig() = %v, want it to mention %q", err, tt.wantErr)
+			}
+			if got := configPaths(err); !slices.Equal(got, tt.wantPaths) {
+				t.Errorf("problems at %q, want %q", got, tt.wantPaths)
+			}
+		})
+	}
+}
+
+func TestBuildPipelineValidates(t *testing.T) {
+	if _, err := notify.BuildPipeline(notify.Config{Channel: "email"}); !slices.Equal(configPaths(err), []string{"recipient.name", "recipient.email"}) {
+		t.Errorf("BuildPipeline() = %v, want the recipient reported missing", err)
+	}
+}
+
+func TestLoadConfigWarnings(t *testing.T) {
+	cfg, err := notify.LoadConfig(strings.NewReader(`{
+	  "Recipient": {"name": "Janet Jones", "EMAIL": "janet@email.com", "nickname": "JJ"},
+	  "retry": {"attempts": 2, "base_dealy": "50ms"},
+	  "extra": true
+	}`))
+	if err != nil {
+		t.Fatalf("LoadConfig() = %v", err)
+	}
+	want := []string{"Recipient.nickname: unknown key", "extra: unknown key", "retry.base_dealy: unknown key"}
+	if !slices.Equal(cfg.Warnings, want) {
+		t.Errorf("Warnings = %q, want %q", cfg.Warnings, want)
+	}
+}
+
+func TestDurationJSON(t *testing.T) {
+	data, err := json.Marshal(notify.Duration(1500 * time.Millisecond))
+	if err != nil || string(data) != `"1.5s"` {
+		t.Fatalf("Marshal() = %s, %v, want \"1.5s\"", data, err)
+	}
+	var d notify.Duration
+	if err := json.Unmarshal(data, &d); err != nil || d != notify.Duration(1500*time.Millisecond) {
+		t.Errorf("Unmarshal(%s) = %v, %v, want 1.5s", data, time.Duration(d), err)
+	}
+	if err := json.Unmarshal([]byte(`"soon"`), &d); err == nil {
+		t.Error(`Unmarshal("soon") = nil, want an error`)
+	}
+}
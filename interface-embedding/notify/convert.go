@@ -0,0 +1,47 @@
+package notify
+
+// Map returns a new slice holding f applied to each element of s, in
+// order.
+func Map[T, U any](s []T, f func(T) U) []U {
+	out := make([]U, len(s))
+	for i, v := range s {
+		out[i] = f(v)
+	}
+
+	return out
+}
+
+// MapToNotifiers converts a slice of concrete notifiers, such as []*User
+// or []*Admin, into the []Notifier that SendBatch and friends accept. Go
+// has no implicit conversion between the two slice types, so each element
+// is assigned to the interface in turn.
+func MapToNotifiers[T Notifier](items []T) []Notifier {
+	return Map(items, func(n T) Notifier { return n })
+}
+
+// Filter returns a new slice holding the elements of s for which keep
+// returns true, in order.
+func Filter[T any](s []T, keep func(T) bool) []T {
+	var out []T
+	for _, v := range s {
+		if keep(v) {
+			out = append(out, v)
+		}
+	}
+
+	return out
+}
+
+// Partition splits s in one pass into the elements for which pred returns
+// true and those for which it returns false, each in their original order.
+func Partition[T any](s []T, pred func(T) bool) (yes, no []T) {
+	for _, v := range s {
+		if pred(v) {
+			yes = append(yes, v)
+		} else {
+			no = append(no, v)
+		}
+	}
+
+	return yes, no
+}
@@ -0,0 +1,165 @@
+package notify_test
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+func TestMap(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []int
+		want []string
+	}{
+		{"nil", nil, []string{}},
+		{"empty", []int{}, []string{}},
+		{"in order", []int{3, 1, 2}, []string{"3", "1", "2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := notify.Map(tt.in, strconv.Itoa); !slices.Equal(got, tt.want) {
+				t.Errorf("Map() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMapToNotifiers(t *testing.T) {
+	var buf bytes.Buffer
+	var users []*notify.User
+	var admins []*notify.Admin
+	for _, name := range []string{"janet", "bill", "lisa"} {
+		u, err := notify.NewUser(name, name+"@email.com", quiet(&buf)...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		users = append(users, u)
+		a, err := notify.NewAdmin(name, name+"@email.com", quietAdmin(&buf)...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		admins = append(admins, a)
+	}
+
+	tests := []struct {
+		name      string
+		notifiers []notify.Notifier
+		wantLine  string
+	}{
+		{"users", notify.MapToNotifiers(users), "Sending user email"},
+		{"admins", notify.MapToNotifiers(admins), "Sending admin email"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+			if len(tt.notifiers) != 3 {
+				t.Fatalf("MapToNotifiers() has %d notifiers, want 3", len(tt.notifiers))
+			}
+			for _, n := range tt.notifiers {
+				if err := n.Notify(context.Background()); err != nil {
+					t.Fatalf("Notify() = %v", err)
+				}
+			}
+			if got := strings.Count(buf.String(), tt.wantLine); got != 3 {
+				t.Errorf("output has %d %q lines, want 3:\n%s", got, tt.wantLine, buf.String())
+			}
+		})
+	}
+}
+
+func TestFilter(t *testing.T) {
+	users := []*notify.User{{Name: "Janet", Email: "janet@email.com"}, {Name: "Bob", Email: "bob@work.com"}, {Name: "Lisa", Email: "lisa@email.com"}}
+	admins := []*notify.Admin{
+		{User: notify.User{Email: "r@email.com"}, Level: notify.LevelRoot},
+		{User: notify.User{Email: "s1@email.com"}, Level: notify.LevelSuper},
+		{User: notify.User{Email: "n@email.com"}, Level: notify.LevelNormal},
+		{User: notify.User{Email: "s2@email.com"}, Level: notify.LevelSuper},
+	}
+	userEmails := func(us []*notify.User) []string {
+		return notify.Map(us, func(u *notify.User) string { return u.Email })
+	}
+	adminEmails := func(as []*notify.Admin) []string {
+		return notify.Map(as, func(a *notify.Admin) string { return a.Email })
+	}
+
+	t.Run("users", func(t *testing.T) {
+		got := notify.Filter(users, func(u *notify.User) bool { return strings.HasSuffix(u.Email, "@email.com") })
+		if want := []string{"janet@email.com", "lisa@email.com"}; !slices.Equal(userEmails(got), want) {
+			t.Errorf("Filter() = %q, want %q", userEmails(got), want)
+		}
+	})
+	t.Run("admins", func(t *testing.T) {
+		got := notify.Filter(admins, func(a *notify.Admin) bool { return a.Level == notify.LevelSuper })
+		if want := []string{"s1@email.com", "s2@email.com"}; !slices.Equal(adminEmails(got), want) {
+			t.Errorf("Filter() = %q, want %q", adminEmails(got), want)
+		}
+	})
+	t.Run("none kept", func(t *testing.T) {
+		if got := notify.Filter(users, func(*notify.User) bool { return false }); len(got) != 0 {
+			t.Errorf("Filter() = %v, want none", got)
+		}
+	})
+	t.Run("empty", func(t *testing.T) {
+		if got := notify.Filter([]int(nil), func(int) bool { return true }); len(got) != 0 {
+			t.Errorf("Filter(nil) = %v, want none", got)
+		}
+	})
+	t.Run("input unchanged", func(t *testing.T) {
+		in := []int{1, 2, 3, 4}
+		notify.Filter(in, func(v int) bool { return v%2 == 0 })
+		if !slices.Equal(in, []int{1, 2, 3, 4}) {
+			t.Errorf("Filter() changed its input to %v", in)
+		}
+	})
+}
+
+func TestPartition(t *testing.T) {
+	even := func(v int) bool { return v%2 == 0 }
+
+	tests := []struct {
+		name    string
+		in      []int
+		yes, no []int
+	}{
+		{"mixed", []int{1, 2, 3, 4, 5, 6}, []int{2, 4, 6}, []int{1, 3, 5}},
+		{"all match", []int{4, 2, 8}, []int{4, 2, 8}, nil},
+		{"none match", []int{3, 1, 5}, nil, []int{3, 1, 5}},
+		{"empty", nil, nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			yes, no := notify.Partition(tt.in, even)
+			if !slices.Equal(yes, tt.yes) || !slices.Equal(no, tt.no) {
+				t.Errorf("Partition(%v) = %v, %v, want %v, %v", tt.in, yes, no, tt.yes, tt.no)
+			}
+		})
+	}
+}
+
+func TestPartitionNotifiers(t *testing.T) {
+	janet := &notify.User{Name: "Janet", Email: "janet@email.com"}
+	lisa := &notify.Admin{User: notify.User{Name: "Lisa", Email: "lisa@email.com"}, Level: notify.LevelRoot}
+	bob := &notify.SMSUser{User: notify.User{Name: "Bob", Email: "bob@email.com"}, Phone: "+15555550100"}
+	bill := &notify.Admin{User: notify.User{Name: "Bill", Email: "bill@email.com"}, Level: notify.LevelSuper}
+
+	admins, others := notify.Partition([]notify.Notifier{janet, lisa, bob, bill}, func(n notify.Notifier) bool {
+		switch n.(type) {
+		case *notify.Admin:
+			return true
+		default:
+			return false
+		}
+	})
+	if !slices.Equal(admins, []notify.Notifier{lisa, bill}) {
+		t.Errorf("admins = %v, want Lisa then Bill", admins)
+	}
+	if !slices.Equal(others, []notify.Notifier{janet, bob}) {
+		t.Errorf("others = %v, want Janet then Bob", others)
+	}
+}
@@ -0,0 +1,246 @@
+package notify
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DefaultMaxCSVRows is how many data rows LoadUsersFromCSV reads unless
+// WithMaxRows says otherwise.
+const DefaultMaxCSVRows = 10000
+
+// DefaultMaxCSVBytes is how much input LoadUsersFromCSV reads unless
+// WithMaxBytes says otherwise.
+const DefaultMaxCSVBytes = 32 << 20
+
+// RowError describes why one CSV row was skipped. Line counts from 1, the
+// header line, and is 0 for problems reading the input rather than with a
+// row. Field is empty for problems with the row as a whole.
+type RowError struct {
+	Line   int
+	Field  string
+	Reason string
+}
+
+// Error implements the error interface.
+func (e RowError) Error() string {
+	if e.Line == 0 {
+		return "notify: csv: " + e.Reason
+	}
+	if e.Field == "" {
+		return fmt.Sprintf("notify: csv line %d: %s", e.Line, e.Reason)
+	}
+
+	return fmt.Sprintf("notify: csv line %d: %s: %s", e.Line, e.Field, e.Reason)
+}
+
+// CSVOption configures LoadUsersFromCSV.
+type CSVOption func(*csvConfig)
+
+type csvConfig struct {
+	maxRows  int
+	maxBytes int64
+}
+
+// WithMaxRows stops LoadUsersFromCSV after n data rows, reporting any
+// further rows as a single RowError, so a huge file can't exhaust memory.
+func WithMaxRows(n int) CSVOption {
+	return func(c *csvConfig) {
+		c.maxRows = n
+	}
+}
+
+// WithMaxBytes stops LoadUsersFromCSV after n bytes of input, reporting
+// the rest as a single RowError. Unlike WithMaxRows, it also bounds a
+// single huge quoted field, which would otherwise be read into memory
+// whole.
+func WithMaxBytes(n int64) CSVOption {
+	return func(c *csvConfig) {
+		c.maxBytes = n
+	}
+}
+
+// errCSVTooLarge is returned by csvLimitReader once the limit is reached.
+var errCSVTooLarge = errors.New("input too large")
+
+// csvLimitReader reads from r until n bytes have been read, then fails
+// with errCSVTooLarge rather than the io.EOF io.LimitReader would return,
+// so a cut-off file isn't mistaken for a complete one.
+type csvLimitReader struct {
+	r io.Reader
+	n int64
+}
+
+func (l *csvLimitReader) Read(p []byte) (int, error) {
+	if l.n <= 0 {
+		return 0, errCSVTooLarge
+	}
+	if int64(len(p)) > l.n {
+		p = p[:l.n]
+	}
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+
+	return n, err
+}
+
+// utf8BOM is the byte order mark some spreadsheet tools write at the
+// start of CSV files.
+var utf8BOM = []byte("\ufeff")
+
+// LoadUsersFromCSV reads recipients from CSV with a header row naming the
+// columns name, email, and optionally level, in any order. Rows with a
+// level become an *Admin and the rest a *User. Each row is validated as
+// NewUser and NewAdmin would; bad rows, and rows repeating an earlier
+// email, are skipped and reported as RowErrors while the rest of the file
+// is still read, up to the limits set by WithMaxRows and WithMaxBytes. A
+// leading byte order mark is ignored.
+func LoadUsersFromCSV(r io.Reader, opts ...CSVOption) ([]Notifier, []RowError) {
+	cfg := csvConfig{maxRows: DefaultMaxCSVRows, maxBytes: DefaultMaxCSVBytes}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	br := bufio.NewReader(&csvLimitReader{r: r, n: cfg.maxBytes})
+	if b, err := br.Peek(len(utf8BOM)); err == nil && bytes.Equal(b, utf8BOM) {
+		br.Discard(len(utf8BOM))
+	}
+
+	cr := csv.NewReader(br)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, []RowError{{Line: 1, Reason: csvReadError(err, cfg.maxBytes)}}
+	}
+	cols, rerr := csvColumns(header)
+	if rerr != nil {
+		return nil, []RowError{*rerr}
+	}
+
+	var (
+		recipients []Notifier
+		problems   []RowError
+		seen       = make(map[string]int)
+	)
+	for rows := 0; ; rows++ {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			// Only a malformed row can be skipped; a failing reader would
+			// fail again on every later row.
+			var perr *csv.ParseError
+			if !errors.As(err, &perr) {
+				problems = append(problems, RowError{Reason: csvReadError(err, cfg.maxBytes)})
+				break
+			}
+			problems = append(problems, RowError{Line: perr.StartLine, Reason: err.Error()})
+			continue
+		}
+		line, _ := cr.FieldPos(0)
+		if rows == cfg.maxRows {
+			problems = append(problems, RowError{Line: line, Reason: fmt.Sprintf("more than %d rows; stopped reading", cfg.maxRows)})
+			break
+		}
+		if len(record) != len(header) {
+			problems = append(problems, RowError{Line: line, Reason: fmt.Sprintf("has %d fields, want %d", len(record), len(header))})
+			continue
+		}
+
+		n, email, rerr := csvRecipient(record, cols)
+		if rerr != nil {
+			rerr.Line = line
+			problems = append(problems, *rerr)
+			continue
+		}
+		if first, ok := seen[email]; ok {
+			problems = append(problems, RowError{Line: line, Field: "email", Reason: fmt.Sprintf("duplicate of line %d", first)})
+			continue
+		}
+		seen[email] = line
+		recipients = append(recipients, n)
+	}
+
+	return recipients, problems
+}
+
+// csvReadError describes a failure to read the CSV input.
+func csvReadError(err error, maxBytes int64) string {
+	if errors.Is(err, errCSVTooLarge) {
+		return fmt.Sprintf("input is larger than %d bytes; stopped reading", maxBytes)
+	}
+
+	return err.Error()
+}
+
+// csvColumns maps the header's column names to their positions. Columns
+// other than name, email and level are ignored.
+func csvColumns(header []string) (map[string]int, *RowError) {
+	cols := make(map[string]int)
+	for i, h := range header {
+		h = strings.ToLower(strings.TrimSpace(h))
+		if _, dup := cols[h]; dup {
+			return nil, &RowError{Line: 1, Field: h, Reason: "column appears more than once"}
+		}
+		cols[h] = i
+	}
+	for _, required := range []string{"name", "email"} {
+		if _, ok := cols[required]; !ok {
+			return nil, &RowError{Line: 1, Field: required, Reason: "column is missing"}
+		}
+	}
+
+	return cols, nil
+}
+
+// csvRecipient builds the recipient described by record, returning it with
+// its normalized email for duplicate detection.
+func csvRecipient(record []string, cols map[string]int) (Notifier, string, *RowError) {
+	name, email := record[cols["name"]], record[cols["email"]]
+	var level string
+	if i, ok := cols["level"]; ok {
+		level = strings.TrimSpace(record[i])
+	}
+
+	u, err := NewUser(name, email)
+	if err != nil {
+		return nil, "", &RowError{Field: csvField(err), Reason: err.Error()}
+	}
+	if level == "" {
+		return u, u.Email, nil
+	}
+
+	l, err := ParseLevel(level)
+	if err != nil {
+		return nil, "", &RowError{Field: "level", Reason: err.Error()}
+	}
+	a, err := NewAdmin(name, email, WithLevel(l))
+	if err != nil {
+		return nil, "", &RowError{Field: csvField(err), Reason: err.Error()}
+	}
+
+	return a, a.Email, nil
+}
+
+// csvField names the column a NewUser or NewAdmin error is about.
+func csvField(err error) string {
+	switch {
+	case errors.Is(err, ErrEmptyName):
+		return "name"
+	case errors.Is(err, ErrEmptyEmail), errors.Is(err, ErrInvalidEmail):
+		return "email"
+	default:
+		return "level"
+	}
+}
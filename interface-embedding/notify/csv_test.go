@@ -0,0 +1,207 @@
+package notify_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+// describeRecipients summarises loaded recipients as "user janet@email.com"
+// or "admin lisa@email.com root".
+func describeRecipients(ns []notify.Notifier) []string {
+	var out []string
+	for _, n := range ns {
+		switch r := n.(type) {
+		case *notify.Admin:
+			out = append(out, fmt.Sprintf("admin %s %s", r.Email, r.Level))
+		case *notify.User:
+			out = append(out, "user "+r.Email)
+		default:
+			out = append(out, fmt.Sprintf("%T", n))
+		}
+	}
+
+	return out
+}
+
+func TestLoadUsersFromCSV(t *testing.T) {
+	tests := []struct {
+		name     string
+		csv      string
+		opts     []notify.CSVOption
+		want     []string
+		wantErrs []notify.RowError
+	}{
+		{name: "empty file"},
+		{name: "header only", csv: "name,email\n"},
+		{
+			name: "users and admins",
+			csv:  "name,email,level\nJanet Jones,janet@email.com,\nLisa Smith,lisa@EMAIL.com,root\n",
+			want: []string{"user janet@email.com", "admin lisa@email.com root"},
+		},
+		{
+			name: "columns in any order, extras ignored",
+			csv:  "Email, team ,NAME\njanet@email.com,billing,Janet Jones\n",
+			want: []string{"user janet@email.com"},
+		},
+		{
+			name: "quoted fields and byte order mark",
+			csv:  "\ufeffname,email\n\"Jones, Janet\",janet@email.com\n\"Bob \"\"The Builder\"\" Brown\",bob@email.com\n",
+			want: []string{"user janet@email.com", "user bob@email.com"},
+		},
+		{
+			name: "mixed valid and invalid rows",
+			csv: "name,email,level\n" +
+				"Janet Jones,janet@email.com,\n" +
+				",nobody@email.com,\n" +
+				"Bob Brown,bob,\n" +
+				"Lisa Smith,lisa@email.com,god\n" +
+				"Too,many,fields,here\n" +
+				"Eve Adams,eve@email.com,super\n",
+			want: []string{"user janet@email.com", "admin eve@email.com super"},
+			wantErrs: []notify.RowError{
+				{Line: 3, Field: "name"},
+				{Line: 4, Field: "email"},
+				{Line: 5, Field: "level"},
+				{Line: 6},
+			},
+		},
+		{
+			name: "duplicate emails flagged",
+			csv:  "name,email\nJanet Jones,janet@email.com\nJ. Jones, janet@EMAIL.com\nBob Brown,bob@email.com\n",
+			want: []string{"user janet@email.com", "user bob@email.com"},
+			wantErrs: []notify.RowError{
+				{Line: 3, Field: "email", Reason: "duplicate of line 2"},
+			},
+		},
+		{
+			name:     "malformed quoting skipped",
+			csv:      "name,email\n\"Janet,janet@email.com\n",
+			wantErrs: []notify.RowError{{Line: 2}},
+		},
+		{
+			name:     "missing column",
+			csv:      "name,address\nJanet Jones,janet@email.com\n",
+			wantErrs: []notify.RowError{{Line: 1, Field: "email", Reason: "column is missing"}},
+		},
+		{
+			name:     "repeated column",
+			csv:      "name,email,Email\nJanet Jones,janet@email.com,x\n",
+			wantErrs: []notify.RowError{{Line: 1, Field: "email", Reason: "column appears more than once"}},
+		},
+		{
+			name:     "row limit",
+			csv:      "name,email\nA A,a@email.com\nB B,b@email.com\nC C,c@email.com\n",
+			opts:     []notify.CSVOption{notify.WithMaxRows(2)},
+			want:     []string{"user a@email.com", "user b@email.com"},
+			wantErrs: []notify.RowError{{Line: 4, Reason: "more than 2 rows; stopped reading"}},
+		},
+		{
+			name:     "byte limit",
+			csv:      "name,email\nJanet Jones,janet@email.com\n\"" + strings.Repeat("x", 100) + "\",bob@email.com\n",
+			opts:     []notify.CSVOption{notify.WithMaxBytes(64)},
+			want:     []string{"user janet@email.com"},
+			wantErrs: []notify.RowError{{Reason: "input is larger than 64 bytes; stopped reading"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ns, errs := notify.LoadUsersFromCSV(strings.NewReader(tt.csv), tt.opts...)
+			if got := describeRecipients(ns); !slices.Equal(got, tt.want) {
+				t.Errorf("recipients = %q, want %q", got, tt.want)
+			}
+			if len(errs) != len(tt.wantErrs) {
+				t.Fatalf("row errors = %v, want %d", errs, len(tt.wantErrs))
+			}
+			for i, want := range tt.wantErrs {
+				got := errs[i]
+				if got.Line != want.Line || got.Field != want.Field || (want.Reason != "" && got.Reason != want.Reason) {
+					t.Errorf("row error %d = %+v, want %+v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadUsersFromCSVReadError(t *testing.T) {
+	errDisk := errors.New("disk error")
+	r := io.MultiReader(strings.NewReader("name,email\nJanet Jones,janet@email.com\n"), failReader{errDisk})
+
+	ns, errs := notify.LoadUsersFromCSV(r)
+	if len(ns) != 1 {
+		t.Errorf("recipients = %v, want the row read before the failure", describeRecipients(ns))
+	}
+	if len(errs) != 1 || errs[0].Line != 0 || errs[0].Reason != errDisk.Error() {
+		t.Errorf("row errors = %+v, want a single read error", errs)
+	}
+}
+
+// failReader fails every read with err.
+type failReader struct{ err error }
+
+func (r failReader) Read([]byte) (int, error) { return 0, r.err }
+
+func TestRowErrorError(t *testing.T) {
+	tests := []struct {
+		err  notify.RowError
+		want string
+	}{
+		{notify.RowError{Reason: "input too large"}, "notify: csv: input too large"},
+		{notify.RowError{Line: 3, Reason: "has 4 fields, want 3"}, "notify: csv line 3: has 4 fields, want 3"},
+		{notify.RowError{Line: 3, Field: "email", Reason: "duplicate of line 2"}, "notify: csv line 3: email: duplicate of line 2"},
+	}
+	for _, tt := range tests {
+		if got := tt.err.Error(); got != tt.want {
+			t.Errorf("Error() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func FuzzLoadUsersFromCSV(f *testing.F) {
+	for _, seed := range []string{
+		"name,email,level\nJanet Jones,janet@email.com,\nLisa Smith,lisa@email.com,root\n",
+		"\ufeffemail,name\njanet@email.com,Janet\n",
+		"name,email\nJanet\x00,janet\x00@email.com\n",
+		"name,email\n\"" + strings.Repeat("x", 1<<17) + "\",janet@email.com\n",
+		"name,email\n\"unterminated,janet@email.com\n",
+		"name,email\nJanet,janet@email.com\nJanet,janet@email.com\n",
+		"name,name,email\n",
+		"\n\n\n",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, data string) {
+		const maxBytes = 1 << 16
+		ns, errs := notify.LoadUsersFromCSV(strings.NewReader(data), notify.WithMaxBytes(maxBytes), notify.WithMaxRows(100))
+		if len(ns) > 100 {
+			t.Errorf("loaded %d recipients, more than the 100 row limit", len(ns))
+		}
+
+		seen := make(map[string]bool)
+		for _, n := range ns {
+			v, ok := n.(notify.Validatable)
+			if !ok {
+				t.Fatalf("loaded a %T, want a *User or *Admin", n)
+			}
+			if err := v.Validate(); err != nil {
+				t.Errorf("loaded %+v, invalid: %v", n, err)
+			}
+			recipient, _ := notify.AddressOf(n)
+			if seen[recipient] {
+				t.Errorf("loaded %s twice", recipient)
+			}
+			seen[recipient] = true
+		}
+		for _, e := range errs {
+			if e.Line < 0 || e.Reason == "" {
+				t.Errorf("row error %+v, want a line and a reason", e)
+			}
+		}
+	})
+}
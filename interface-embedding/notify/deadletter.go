@@ -0,0 +1,75 @@
+package notify
+
+import (
+	"context"
+	"sync"
+)
+
+// DeadLetterQueue keeps notifications that failed for good, so they can
+// be inspected or redelivered later instead of being lost. The zero value
+// is empty, ready to use and safe for concurrent use.
+type DeadLetterQueue struct {
+	mu      sync.Mutex
+	letters []DeadLetter
+}
+
+// Push adds a failed notification to the end of the queue.
+func (q *DeadLetterQueue) Push(letter DeadLetter) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.letters = append(q.letters, letter)
+}
+
+// Len returns the number of notifications in the queue.
+func (q *DeadLetterQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return len(q.letters)
+}
+
+// Drain removes and returns everything in the queue, oldest first.
+func (q *DeadLetterQueue) Drain() []DeadLetter {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	letters := q.letters
+	q.letters = nil
+
+	return letters
+}
+
+// Requeue tries to redeliver everything in the queue with send, such as
+// SendNotification, and returns how many failed again. Those are pushed
+// back with their new error and one more attempt; notifications pushed
+// while Requeue runs are left for the next call.
+func (q *DeadLetterQueue) Requeue(ctx context.Context, send func(context.Context, Notifier) error) (remaining int) {
+	for _, letter := range q.Drain() {
+		err := ctx.Err()
+		if err == nil {
+			err = send(ctx, letter.Notifier)
+			letter.Attempts++
+		}
+		if err != nil {
+			letter.Err = err
+			q.Push(letter)
+			remaining++
+		}
+	}
+
+	return remaining
+}
+
+// SendBatch is SendBatchCtx that also pushes every failed notification
+// onto the queue, including those never started because ctx was done.
+func (q *DeadLetterQueue) SendBatch(ctx context.Context, notifiers []Notifier, workers int) []error {
+	results := SendBatchResults(ctx, notifiers, workers)
+	for i, r := range results {
+		if r.Err != nil {
+			q.Push(DeadLetter{Notifier: notifiers[i], Err: r.Err, Attempts: r.Attempt})
+		}
+	}
+
+	return resultErrors(results)
+}
@@ -0,0 +1,135 @@
+package notify_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+func TestDeadLetterQueueSendBatch(t *testing.T) {
+	errDown := errors.New("server down")
+	ok := &notify.MockNotifier{}
+	failing := &notify.MockNotifier{Err: errDown}
+	alsoFailing := &notify.MockNotifier{Err: errDown}
+
+	var q notify.DeadLetterQueue
+	errs := q.SendBatch(context.Background(), []notify.Notifier{ok, failing, ok, alsoFailing}, 2)
+	if errs[0] != nil || !errors.Is(errs[1], errDown) || errs[2] != nil || !errors.Is(errs[3], errDown) {
+		t.Fatalf("SendBatch() = %v, want failures at 1 and 3", errs)
+	}
+	if q.Len() != 2 {
+		t.Fatalf("Len() = %d, want the 2 failures", q.Len())
+	}
+
+	letters := q.Drain()
+	if len(letters) != 2 || letters[0].Notifier != failing || letters[1].Notifier != alsoFailing {
+		t.Fatalf("Drain() = %+v, want the failed notifiers in order", letters)
+	}
+	for _, l := range letters {
+		if !errors.Is(l.Err, errDown) || l.Attempts != 1 {
+			t.Errorf("letter = %+v, want %v after 1 attempt", l, errDown)
+		}
+	}
+	if q.Len() != 0 || q.Drain() != nil {
+		t.Errorf("Len() = %d after Drain, want an empty queue", q.Len())
+	}
+}
+
+func TestDeadLetterQueueSendBatchCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var q notify.DeadLetterQueue
+	q.SendBatch(ctx, []notify.Notifier{&notify.MockNotifier{}, &notify.MockNotifier{}}, 1)
+	letters := q.Drain()
+	if len(letters) != 2 {
+		t.Fatalf("Drain() = %+v, want both unsent notifications", letters)
+	}
+	for _, l := range letters {
+		if !errors.Is(l.Err, context.Canceled) || l.Attempts != 0 {
+			t.Errorf("letter = %+v, want context.Canceled with no attempts", l)
+		}
+	}
+}
+
+func TestDeadLetterQueueRequeue(t *testing.T) {
+	errDown := errors.New("server down")
+	errStill := errors.New("still down")
+	recovered := &notify.MockNotifier{}
+	stuck := &notify.MockNotifier{Err: errStill}
+
+	tests := []struct {
+		name          string
+		cancel        bool
+		wantRemaining int
+		wantAttempts  []int
+		wantErrs      []error
+	}{
+		{"redelivers", false, 1, []int{2}, []error{errStill}},
+		{"cancelled", true, 2, []int{1, 1}, []error{context.Canceled, context.Canceled}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var q notify.DeadLetterQueue
+			q.Push(notify.DeadLetter{Notifier: recovered, Err: errDown, Attempts: 1})
+			q.Push(notify.DeadLetter{Notifier: stuck, Err: errDown, Attempts: 1})
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancel {
+				cancel()
+			}
+			if got := q.Requeue(ctx, notify.SendNotification); got != tt.wantRemaining {
+				t.Errorf("Requeue() = %d, want %d", got, tt.wantRemaining)
+			}
+
+			letters := q.Drain()
+			if len(letters) != tt.wantRemaining {
+				t.Fatalf("queue holds %+v, want %d letters", letters, tt.wantRemaining)
+			}
+			for i, l := range letters {
+				if l.Attempts != tt.wantAttempts[i] || !errors.Is(l.Err, tt.wantErrs[i]) {
+					t.Errorf("letter %d = %+v, want %v after %d attempts", i, l, tt.wantErrs[i], tt.wantAttempts[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDeadLetterQueueRequeueEmpties(t *testing.T) {
+	var q notify.DeadLetterQueue
+	for range 3 {
+		q.Push(notify.DeadLetter{Notifier: &notify.MockNotifier{}, Err: errors.New("server down")})
+	}
+	if got := q.Requeue(context.Background(), notify.SendNotification); got != 0 || q.Len() != 0 {
+		t.Errorf("Requeue() = %d leaving %d, want an empty queue", got, q.Len())
+	}
+}
+
+func TestDeadLetterQueueConcurrentPush(t *testing.T) {
+	var q notify.DeadLetterQueue
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 50 {
+				q.Push(notify.DeadLetter{Notifier: &notify.MockNotifier{}})
+				q.Len()
+			}
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		q.SendBatch(context.Background(), []notify.Notifier{&notify.MockNotifier{Err: errors.New("server down")}}, 1)
+	}()
+	wg.Wait()
+
+	if got := q.Len(); got != 401 {
+		t.Errorf("Len() = %d, want 401", got)
+	}
+}
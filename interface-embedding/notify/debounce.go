@@ -0,0 +1,92 @@
+package notify
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DebounceNotifier coalesces bursts of notifications: each Notify restarts
+// the wait, and the embedded Notifier is sent once, only after a whole
+// wait has passed with no further calls.
+type DebounceNotifier struct {
+	Notifier
+
+	// OnError, when set before the first Notify, receives the errors from
+	// debounced sends, which have no caller to return them to.
+	OnError func(error)
+
+	wait  time.Duration
+	clock Clock
+
+	mu       sync.Mutex
+	pending  bool            // a send is scheduled and its goroutine is running
+	deadline time.Time       // when the scheduled send is due
+	ctx      context.Context // the last call's ctx, for the send
+	wg       sync.WaitGroup
+}
+
+// NewDebounceNotifier wraps n so that calls less than wait apart are sent
+// as one. Time is measured with clock, or with SystemClock when clock is
+// nil.
+func NewDebounceNotifier(n Notifier, wait time.Duration, clock Clock) *DebounceNotifier {
+	if clock == nil {
+		clock = SystemClock
+	}
+
+	return &DebounceNotifier{Notifier: n, wait: wait, clock: clock}
+}
+
+// Notify returns nil straight away and schedules the send for one wait
+// from now, pushing back any send scheduled by an earlier call. The send
+// isn't cancelled with ctx, but keeps the values of the last call's ctx.
+// However many calls arrive, at most one send is scheduled at a time.
+func (d *DebounceNotifier) Notify(ctx context.Context) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.deadline = d.clock.Now().Add(d.wait)
+	d.ctx = context.WithoutCancel(ctx)
+	if !d.pending {
+		d.pending = true
+		d.wg.Add(1)
+		go d.run()
+	}
+
+	return nil
+}
+
+// run waits for the scheduled send to fall due and sends it. Like a timer
+// being reset, it waits again for whatever time the calls made meanwhile
+// have added, rather than each call starting a wait of its own.
+func (d *DebounceNotifier) run() {
+	defer d.wg.Done()
+
+	for {
+		d.mu.Lock()
+		wait := d.deadline.Sub(d.clock.Now())
+		if wait <= 0 {
+			ctx := d.ctx
+			d.pending, d.ctx = false, nil
+			d.mu.Unlock()
+
+			if err := d.Notifier.Notify(ctx); err != nil && d.OnError != nil {
+				d.OnError(err)
+			}
+			return
+		}
+		d.mu.Unlock()
+
+		<-d.clock.After(wait)
+	}
+}
+
+// Wait blocks until the scheduled send, if any, has happened.
+func (d *DebounceNotifier) Wait() {
+	d.wg.Wait()
+}
+
+// Unwrap returns the notifier being debounced.
+func (d *DebounceNotifier) Unwrap() Notifier {
+	return d.Notifier
+}
@@ -0,0 +1,145 @@
+package notify_test
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+func TestDebounceNotifier(t *testing.T) {
+	const wait = time.Second
+
+	type step struct {
+		notifies  int
+		advance   time.Duration
+		wantSends int
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{"five rapid calls", []step{{5, wait, 1}}},
+		{"not before the wait", []step{{5, wait - time.Millisecond, 0}, {0, time.Millisecond, 1}}},
+		{"each call restarts the wait", []step{
+			{1, wait / 2, 0}, {1, wait / 2, 0}, {1, wait / 2, 0}, {0, wait / 2, 1}}},
+		{"separate bursts", []step{{3, wait, 1}, {0, 10 * wait, 1}, {2, wait, 2}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkNoLeaks(t)
+			clock := newFakeClock()
+			mock := &notify.MockNotifier{}
+			d := notify.NewDebounceNotifier(mock, wait, clock)
+
+			scheduled := false
+			for i, s := range tt.steps {
+				for range s.notifies {
+					if err := d.Notify(context.Background()); err != nil {
+						t.Fatalf("Notify() = %v, want nil", err)
+					}
+					scheduled = true
+				}
+				if scheduled && !clock.BlockUntil(1) {
+					t.Fatalf("step %d: no send was scheduled", i)
+				}
+				if got := clock.pending(); got > 1 {
+					t.Fatalf("step %d: %d waits pending, want at most one", i, got)
+				}
+
+				clock.Advance(s.advance)
+				switch {
+				case s.wantSends > mock.Calls():
+					waitFor(t, "the debounced send", func() bool { return mock.Calls() == s.wantSends })
+					scheduled = false
+				case scheduled && !clock.BlockUntil(1):
+					t.Fatalf("step %d: the send wasn't rescheduled", i)
+				}
+				if got := mock.Calls(); got != s.wantSends {
+					t.Fatalf("step %d: sent %d times, want %d", i, got, s.wantSends)
+				}
+			}
+			d.Wait()
+		})
+	}
+}
+
+func TestDebounceNotifierKeepsLastContext(t *testing.T) {
+	type key struct{}
+	clock := newFakeClock()
+	var got any
+	var gotErr error
+	n := notify.NotifierFunc(func(ctx context.Context) error {
+		got, gotErr = ctx.Value(key{}), ctx.Err()
+		return nil
+	})
+	d := notify.NewDebounceNotifier(n, time.Second, clock)
+
+	for _, v := range []string{"first", "last"} {
+		ctx, cancel := context.WithCancel(context.WithValue(context.Background(), key{}, v))
+		d.Notify(ctx)
+		cancel()
+	}
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+	d.Wait()
+
+	if got != "last" || gotErr != nil {
+		t.Errorf("send saw value %v and error %v, want the last call's value and no cancellation", got, gotErr)
+	}
+}
+
+func TestDebounceNotifierOnError(t *testing.T) {
+	errDown := errors.New("server down")
+	clock := newFakeClock()
+	d := notify.NewDebounceNotifier(&notify.MockNotifier{Err: errDown}, time.Second, clock)
+	var got error
+	d.OnError = func(err error) { got = err }
+
+	d.Notify(context.Background())
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+	d.Wait()
+
+	if !errors.Is(got, errDown) {
+		t.Errorf("OnError got %v, want %v", got, errDown)
+	}
+}
+
+func TestDebounceNotifierOneGoroutine(t *testing.T) {
+	checkNoLeaks(t)
+	mock := &notify.MockNotifier{}
+	d := notify.NewDebounceNotifier(mock, 100*time.Millisecond, nil)
+
+	before := runtime.NumGoroutine()
+	for range 1000 {
+		d.Notify(context.Background())
+	}
+	if extra := runtime.NumGoroutine() - before; extra != 1 {
+		t.Errorf("%d goroutines waiting after 1000 calls, want one", extra)
+	}
+	d.Wait()
+	if got := mock.Calls(); got != 1 {
+		t.Fatalf("sent %d times, want 1", got)
+	}
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				d.Notify(context.Background())
+			}
+		}()
+	}
+	wg.Wait()
+	d.Wait()
+	if got := mock.Calls(); got != 2 {
+		t.Errorf("sent %d times, want 2 after the concurrent burst", got)
+	}
+}
@@ -0,0 +1,176 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrDuplicateSuppressed is returned by a DedupNotifier created with
+// ReportDuplicates when it skips a repeat notification.
+var ErrDuplicateSuppressed = errors.New("notify: duplicate notification suppressed")
+
+// Keyer is implemented by notifiers that can identify the notification
+// they send, so that repeats can be recognized.
+type Keyer interface {
+	NotificationKey() string
+}
+
+// DedupNotifier suppresses repeat notifications: a call whose key was
+// already sent within the TTL is skipped and counted instead of reaching
+// the embedded Notifier. Notifiers created with Wrap share the same
+// window, so a batch can be deduplicated across its members.
+type DedupNotifier struct {
+	Notifier
+
+	key    func(Notifier) string
+	ttl    time.Duration
+	report bool
+	clock  Clock
+	window *dedupWindow
+}
+
+// DedupOption configures a DedupNotifier.
+type DedupOption func(*DedupNotifier)
+
+// ReportDuplicates makes a suppressed call return ErrDuplicateSuppressed
+// instead of nil.
+func ReportDuplicates() DedupOption {
+	return func(d *DedupNotifier) {
+		d.report = true
+	}
+}
+
+// WithDedupClock sets the clock the TTL is measured with. It defaults to
+// SystemClock.
+func WithDedupClock(clock Clock) DedupOption {
+	return func(d *DedupNotifier) {
+		d.clock = clock
+	}
+}
+
+// dedupWindow is the state shared by DedupNotifiers created from one
+// another.
+type dedupWindow struct {
+	mu      sync.Mutex
+	sent    map[string]time.Time
+	skipped int
+}
+
+// NewDedupNotifier wraps n so that calls with the same key within ttl are
+// sent only once. A nil key uses DedupKey.
+func NewDedupNotifier(n Notifier, key func(Notifier) string, ttl time.Duration, opts ...DedupOption) *DedupNotifier {
+	if key == nil {
+		key = DedupKey
+	}
+
+	d := &DedupNotifier{
+		Notifier: n,
+		key:      key,
+		ttl:      ttl,
+		clock:    SystemClock,
+		window:   &dedupWindow{sent: make(map[string]time.Time)},
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	if d.clock == nil {
+		d.clock = SystemClock
+	}
+
+	return d
+}
+
+// Wrap returns a DedupNotifier for n that shares d's configuration and
+// record of what has been sent.
+func (d *DedupNotifier) Wrap(n Notifier) *DedupNotifier {
+	return &DedupNotifier{Notifier: n, key: d.key, ttl: d.ttl, report: d.report, clock: d.clock, window: d.window}
+}
+
+// Notify calls the embedded Notifier unless its key was sent within the
+// TTL, in which case it returns nil, or ErrDuplicateSuppressed with
+// ReportDuplicates. Failed sends aren't recorded, so they can be tried
+// again straight away.
+func (d *DedupNotifier) Notify(ctx context.Context) error {
+	key := d.key(d.Notifier)
+	if !d.window.claim(key, d.ttl, d.clock.Now()) {
+		if d.report {
+			return ErrDuplicateSuppressed
+		}
+		return nil
+	}
+
+	err := d.Notifier.Notify(ctx)
+	if err != nil {
+		d.window.release(key)
+	}
+
+	return err
+}
+
+// Skipped returns how many notifications have been suppressed across the
+// shared window.
+func (d *DedupNotifier) Skipped() int {
+	d.window.mu.Lock()
+	defer d.window.mu.Unlock()
+
+	return d.window.skipped
+}
+
+// Unwrap returns the notifier being deduplicated.
+func (d *DedupNotifier) Unwrap() Notifier {
+	return d.Notifier
+}
+
+// DedupKey identifies what n sends: its NotificationKey when it is a
+// Keyer, looking through wrappers, or otherwise a hash of how it prints
+// with fmt.
+func DedupKey(n Notifier) string {
+	for inner := n; inner != nil; {
+		if k, ok := inner.(Keyer); ok {
+			return k.NotificationKey()
+		}
+		w, ok := inner.(Wrapper)
+		if !ok {
+			break
+		}
+		inner = w.Unwrap()
+	}
+
+	h := fnv.New64a()
+	fmt.Fprint(h, n)
+	return strconv.FormatUint(h.Sum64(), 16)
+}
+
+// claim prunes keys expired by now and reports whether key may be sent,
+// recording it as sent at now if so.
+func (w *dedupWindow) claim(key string, ttl time.Duration, now time.Time) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for k, at := range w.sent {
+		if now.Sub(at) >= ttl {
+			delete(w.sent, k)
+		}
+	}
+
+	if _, ok := w.sent[key]; ok {
+		w.skipped++
+		return false
+	}
+	w.sent[key] = now
+
+	return true
+}
+
+// release forgets key so that it can be sent again.
+func (w *dedupWindow) release(key string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	delete(w.sent, key)
+}
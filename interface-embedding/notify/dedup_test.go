@@ -0,0 +1,192 @@
+package notify_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+func TestDedupNotifierWindow(t *testing.T) {
+	clock := notify.NewSimClock(time.Date(2026, time.January, 1, 9, 0, 0, 0, time.UTC))
+	mock := &notify.MockNotifier{}
+	key := func(notify.Notifier) string { return "janet" }
+	d := notify.NewDedupNotifier(mock, key, time.Minute, notify.WithDedupClock(clock))
+
+	for range 3 {
+		if err := d.Notify(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		clock.Advance(10 * time.Second)
+	}
+	if mock.Calls() != 1 || d.Skipped() != 2 {
+		t.Errorf("calls, skipped = %d, %d after three sends in the window, want 1, 2", mock.Calls(), d.Skipped())
+	}
+
+	clock.Advance(time.Minute)
+	if err := d.Notify(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if mock.Calls() != 2 {
+		t.Errorf("calls = %d after the TTL expired, want 2", mock.Calls())
+	}
+}
+
+func TestDedupNotifierOptions(t *testing.T) {
+	clock := notify.NewSimClock(time.Date(2026, time.January, 1, 9, 0, 0, 0, time.UTC))
+	tests := []struct {
+		name    string
+		errs    []error
+		opts    []notify.DedupOption
+		want    []error
+		wantRun int
+	}{
+		{"silent", nil, nil, []error{nil, nil}, 1},
+		{"reported", nil, []notify.DedupOption{notify.ReportDuplicates()}, []error{nil, notify.ErrDuplicateSuppressed}, 1},
+		{"failures aren't recorded", []error{errors.New("down"), nil}, nil, []error{errors.New("down"), nil, nil}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &notify.MockNotifier{Errs: tt.errs}
+			d := notify.NewDedupNotifier(mock, func(notify.Notifier) string { return "k" }, time.Hour,
+				append(tt.opts, notify.WithDedupClock(clock))...)
+
+			for i, want := range tt.want {
+				err := d.Notify(context.Background())
+				if (want == nil) != (err == nil) || (want != nil && err.Error() != want.Error()) {
+					t.Errorf("call %d = %v, want %v", i, err, want)
+				}
+			}
+			if mock.Calls() != tt.wantRun {
+				t.Errorf("calls = %d, want %d", mock.Calls(), tt.wantRun)
+			}
+		})
+	}
+}
+
+func TestDedupNotifierWrapSharesWindow(t *testing.T) {
+	janet := &notify.User{Name: "Janet", Email: "janet@email.com"}
+	again := &notify.User{Name: "Janet J", Email: "janet@email.com"}
+	lisa := &notify.User{Name: "Lisa", Email: "lisa@email.com"}
+	var sent []string
+	send := func(u *notify.User) notify.Notifier {
+		return &keyed{User: u, sent: &sent}
+	}
+
+	d := notify.NewDedupNotifier(send(janet), nil, time.Hour)
+	for _, n := range []notify.Notifier{d, d.Wrap(send(again)), d.Wrap(send(lisa))} {
+		if err := n.Notify(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(sent) != 2 || sent[0] != "janet@email.com" || sent[1] != "lisa@email.com" {
+		t.Errorf("sent to %v, want janet once and lisa", sent)
+	}
+	if d.Skipped() != 1 {
+		t.Errorf("Skipped() = %d, want 1", d.Skipped())
+	}
+}
+
+// keyed notifies by recording the user's email, keeping the user's
+// NotificationKey so it can be deduplicated.
+type keyed struct {
+	*notify.User
+	sent *[]string
+}
+
+func (k *keyed) Notify(context.Context) error {
+	*k.sent = append(*k.sent, k.Email)
+	return nil
+}
+
+func TestDedupKey(t *testing.T) {
+	janet := &notify.User{Name: "Janet", Email: "janet@email.com"}
+	sms := &notify.SMSUser{User: *janet, Phone: "+15555550100"}
+	slack := &notify.SlackUser{User: *janet, Channel: "#alerts"}
+	plain := notify.NotifierFunc(func(context.Context) error { return nil })
+
+	tests := []struct {
+		name string
+		n    notify.Notifier
+		want string
+	}{
+		{"user", janet, "email:janet@email.com"},
+		{"sms", sms, "sms:+15555550100"},
+		{"slack", slack, "slack:#alerts"},
+		{"through wrappers", notify.WithTimeout(notify.WithRetry(janet, 2, time.Millisecond), time.Second), "email:janet@email.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := notify.DedupKey(tt.n); got != tt.want {
+				t.Errorf("DedupKey() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("fallback hash", func(t *testing.T) {
+		a, b := &numbered{i: 1}, &numbered{i: 2}
+		if notify.DedupKey(a) != notify.DedupKey(&numbered{i: 1}) {
+			t.Error("DedupKey() differs for notifiers that print the same")
+		}
+		if notify.DedupKey(a) == notify.DedupKey(b) {
+			t.Error("DedupKey() is the same for notifiers that print differently")
+		}
+		if notify.DedupKey(plain) == "" {
+			t.Error("DedupKey() of a NotifierFunc is empty")
+		}
+	})
+}
+
+func TestDedupNotifierConcurrent(t *testing.T) {
+	const callers = 50
+
+	tests := []struct {
+		name      string
+		key       func(i int) string
+		wantCalls int
+	}{
+		{"same key", func(int) string { return "janet" }, 1},
+		{"distinct keys", func(i int) string { return strconv.Itoa(i) }, callers},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &notify.MockNotifier{}
+			var ns []notify.Notifier
+			for i := range callers {
+				ns = append(ns, &numbered{MockNotifier: mock, i: i})
+			}
+			d := notify.NewDedupNotifier(ns[0], func(n notify.Notifier) string { return tt.key(n.(*numbered).i) }, time.Hour)
+
+			var wg sync.WaitGroup
+			for _, n := range ns {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					if err := d.Wrap(n).Notify(context.Background()); err != nil {
+						t.Error(err)
+					}
+				}()
+			}
+			wg.Wait()
+
+			if mock.Calls() != tt.wantCalls || d.Skipped() != callers-tt.wantCalls {
+				t.Errorf("calls, skipped = %d, %d, want %d, %d", mock.Calls(), d.Skipped(), tt.wantCalls, callers-tt.wantCalls)
+			}
+		})
+	}
+}
+
+// numbered is a mock notifier told apart by i, which is also how it
+// prints.
+type numbered struct {
+	*notify.MockNotifier
+	i int
+}
+
+func (n *numbered) String() string {
+	return "notification " + strconv.Itoa(n.i)
+}
@@ -0,0 +1,87 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+)
+
+// ErrNilRecipient is returned when a delegate is built without both an
+// admin and the user they act for.
+var ErrNilRecipient = errors.New("notify: delegate needs an admin and a user")
+
+// Delegate is an Admin who is notified on behalf of another user. It
+// embeds *Admin, which in turn embeds User, so Delegate's Notify shadows
+// Admin.Notify just as Admin.Notify shadows User.Notify, while the name,
+// email and level are promoted through both levels.
+type Delegate struct {
+	*Admin
+	OnBehalfOf *User
+}
+
+// NewDelegate returns a Delegate through which a acts for u.
+func NewDelegate(a *Admin, u *User) (*Delegate, error) {
+	if a == nil || u == nil {
+		return nil, ErrNilRecipient
+	}
+
+	return &Delegate{Admin: a, OnBehalfOf: u}, nil
+}
+
+// Notify emails the delegate their admin message along with the identity
+// of the user they act for. If that fails the user is notified directly,
+// and only when both sends fail is an error returned, joining the two.
+func (d *Delegate) Notify(ctx context.Context) error {
+	err := d.notifyDelegate(ctx)
+	if err == nil {
+		return nil
+	}
+
+	if ferr := d.OnBehalfOf.Notify(ctx); ferr != nil {
+		return errors.Join(err, ferr)
+	}
+
+	return nil
+}
+
+// notifyDelegate sends the delegate's own email, following the same steps
+// as Admin.Notify.
+func (d *Delegate) notifyDelegate(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return &NotificationError{Recipient: d.Email, Channel: "email", Err: err}
+	}
+	if _, err := NormalizeEmail(d.Email); err != nil {
+		return &NotificationError{Recipient: d.Email, Channel: "email", Err: err}
+	}
+
+	buf := lineBufs.Get().(*[]byte)
+	defer lineBufs.Put(buf)
+
+	line, err := d.appendMessage((*buf)[:0])
+	if err != nil {
+		return &NotificationError{Recipient: d.Email, Channel: "email", Err: err}
+	}
+	line = append(line, " on behalf of "...)
+	line = append(append(line, Sanitize(d.OnBehalfOf.Name)...), '<')
+	line = append(append(line, d.OnBehalfOf.Email...), '>')
+
+	line, err = limitLine(ctx, d.appendAttachments(line))
+	*buf = line
+	if err != nil {
+		return &NotificationError{Recipient: d.Email, Channel: "email", Err: err}
+	}
+
+	if err := d.writeBuf(ctx, buf); err != nil {
+		return &NotificationError{Recipient: d.Email, Channel: "email", Retryable: true, Err: err}
+	}
+	d.logSent(ctx,
+		slog.String("name", d.Name), slog.String("email", d.Email), slog.String("channel", "email"),
+		slog.String("level", string(d.Level)), slog.String("on_behalf_of", d.OnBehalfOf.Email))
+	return nil
+}
+
+// String describes the delegate, as in
+// "admin[super] Janet Jones <j***t@email.com> for user Bill <b***l@email.com>".
+func (d *Delegate) String() string {
+	return d.Admin.String() + " for " + d.OnBehalfOf.String()
+}
@@ -0,0 +1,121 @@
+package notify_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+func TestNewDelegate(t *testing.T) {
+	a := &notify.Admin{}
+	u := &notify.User{}
+
+	tests := []struct {
+		name  string
+		admin *notify.Admin
+		user  *notify.User
+		want  error
+	}{
+		{"both", a, u, nil},
+		{"nil admin", nil, u, notify.ErrNilRecipient},
+		{"nil user", a, nil, notify.ErrNilRecipient},
+		{"neither", nil, nil, notify.ErrNilRecipient},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := notify.NewDelegate(tt.admin, tt.user)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("NewDelegate() error = %v, want %v", err, tt.want)
+			}
+			if (d == nil) != (tt.want != nil) {
+				t.Errorf("NewDelegate() = %v, want a delegate only on success", d)
+			}
+		})
+	}
+}
+
+func TestDelegateNotify(t *testing.T) {
+	errAdmin := errors.New("admin mailbox full")
+	errUser := errors.New("user mailbox full")
+
+	tests := []struct {
+		name      string
+		adminOut  func(*bytes.Buffer) io.Writer
+		userOut   func(*bytes.Buffer) io.Writer
+		wantErrs  []error
+		wantAdmin string
+		wantUser  string
+	}{
+		{
+			name:      "delegate",
+			adminOut:  func(b *bytes.Buffer) io.Writer { return b },
+			userOut:   func(b *bytes.Buffer) io.Writer { return b },
+			wantAdmin: "Sending admin email to Lisa Smith<lisa@email.com> with level super on behalf of Bill Smith<bill@email.com>\n",
+		},
+		{
+			name:     "falls back to the user",
+			adminOut: func(*bytes.Buffer) io.Writer { return failWriter{errAdmin} },
+			userOut:  func(b *bytes.Buffer) io.Writer { return b },
+			wantUser: "Sending user email to Bill Smith<bill@email.com>\n",
+		},
+		{
+			name:     "both fail",
+			adminOut: func(*bytes.Buffer) io.Writer { return failWriter{errAdmin} },
+			userOut:  func(*bytes.Buffer) io.Writer { return failWriter{errUser} },
+			wantErrs: []error{errAdmin, errUser},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var adminBuf, userBuf bytes.Buffer
+			discard := notify.WithLogger(slog.New(slog.DiscardHandler))
+			a, err := notify.NewAdmin("Lisa Smith", "lisa@email.com",
+				notify.WithLevel(notify.LevelSuper), notify.WithWriter(tt.adminOut(&adminBuf)), discard)
+			if err != nil {
+				t.Fatal(err)
+			}
+			u, err := notify.NewUser("Bill Smith", "bill@email.com", notify.WithWriter(tt.userOut(&userBuf)), discard)
+			if err != nil {
+				t.Fatal(err)
+			}
+			d, err := notify.NewDelegate(a, u)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = d.Notify(context.Background())
+			if len(tt.wantErrs) == 0 && err != nil {
+				t.Errorf("Notify() = %v, want nil", err)
+			}
+			for _, want := range tt.wantErrs {
+				if !errors.Is(err, want) {
+					t.Errorf("Notify() = %v, want it to join %v", err, want)
+				}
+			}
+			if got := adminBuf.String(); got != tt.wantAdmin {
+				t.Errorf("delegate output = %q, want %q", got, tt.wantAdmin)
+			}
+			if got := userBuf.String(); got != tt.wantUser {
+				t.Errorf("user output = %q, want %q", got, tt.wantUser)
+			}
+		})
+	}
+}
+
+func TestDelegateString(t *testing.T) {
+	a := &notify.Admin{User: notify.User{Name: "Lisa Smith", Email: "lisa@email.com"}, Level: notify.LevelRoot}
+	u := &notify.User{Name: "Bill", Email: "bill@email.com"}
+	d, err := notify.NewDelegate(a, u)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := d.String(), "admin[root] Lisa Smith <l***a@email.com> for user Bill <b***l@email.com>"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,168 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrDigestClosed is returned when a message is added to a closed digest.
+var ErrDigestClosed = errors.New("notify: digest is closed")
+
+// DigestNotifier collects messages for one user and emails them together
+// as a single digest, flushing when maxItems messages are waiting or the
+// oldest has waited maxAge, whichever comes first.
+type DigestNotifier struct {
+	// OnError, when set before the first Add, receives the errors from
+	// digests flushed by the maxAge timer, which have no caller to return
+	// them to.
+	OnError func(error)
+
+	to       *User
+	maxItems int
+	maxAge   time.Duration
+	clock    Clock
+
+	mu      sync.Mutex
+	items   []string
+	expired chan struct{} // closed when the pending batch is taken
+	closed  bool
+	wg      sync.WaitGroup
+}
+
+// NewDigestNotifier returns a DigestNotifier for to. A maxItems below one
+// disables the size trigger and a maxAge of zero or less disables the
+// timer. The timer is driven by clock, or by SystemClock when clock is nil.
+func NewDigestNotifier(to *User, maxItems int, maxAge time.Duration, clock Clock) *DigestNotifier {
+	if clock == nil {
+		clock = SystemClock
+	}
+
+	return &DigestNotifier{to: to, maxItems: maxItems, maxAge: maxAge, clock: clock}
+}
+
+// Add queues msg for the next digest, sending the digest straight away if
+// it is now full.
+func (d *DigestNotifier) Add(msg string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.closed {
+		return ErrDigestClosed
+	}
+
+	d.items = append(d.items, msg)
+	if len(d.items) == 1 && d.maxAge > 0 {
+		d.expired = make(chan struct{})
+		d.wg.Add(1)
+		go d.expire(d.expired)
+	}
+	if d.maxItems > 0 && len(d.items) >= d.maxItems {
+		return d.flushLocked(context.Background())
+	}
+
+	return nil
+}
+
+// Flush sends any queued messages as a digest now.
+func (d *DigestNotifier) Flush() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.flushLocked(context.Background())
+}
+
+// Notify flushes the digest, so a DigestNotifier can be driven by a
+// Scheduler or anything else that sends notifications.
+func (d *DigestNotifier) Notify(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return &NotificationError{Recipient: d.to.Email, Channel: "email", Err: err}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.flushLocked(ctx)
+}
+
+// Close sends any queued messages and stops the timer. Messages added
+// afterwards are rejected with ErrDigestClosed. Closing twice is a no-op.
+func (d *DigestNotifier) Close() error {
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return nil
+	}
+	d.closed = true
+	err := d.flushLocked(context.Background())
+	d.mu.Unlock()
+
+	d.wg.Wait()
+	return err
+}
+
+// Address reports the digest's recipient and the email channel.
+func (d *DigestNotifier) Address() (recipient, channel string) {
+	return d.to.Address()
+}
+
+// expire flushes the batch that started the timer once maxAge has passed,
+// unless that batch has already been sent.
+func (d *DigestNotifier) expire(expired <-chan struct{}) {
+	defer d.wg.Done()
+
+	select {
+	case <-d.clock.After(d.maxAge):
+	case <-expired:
+		return
+	}
+
+	d.mu.Lock()
+	var err error
+	select {
+	case <-expired:
+	default:
+		err = d.flushLocked(context.Background())
+	}
+	d.mu.Unlock()
+
+	if err != nil && d.OnError != nil {
+		d.OnError(err)
+	}
+}
+
+// flushLocked sends the queued messages as one digest headed by their
+// count, within any limits in ctx. Delivering under d.mu keeps digests in
+// the order they filled.
+func (d *DigestNotifier) flushLocked(ctx context.Context) error {
+	if len(d.items) == 0 {
+		return nil
+	}
+	items := d.items
+	d.items = nil
+	if d.expired != nil {
+		close(d.expired)
+		d.expired = nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Sending digest of %d notifications to %s<%s>", len(items), Sanitize(d.to.Name), d.to.Email)
+	for _, item := range items {
+		b.WriteString("\n- ")
+		b.WriteString(Sanitize(item))
+	}
+
+	msg, err := limitBody(ctx, b.String())
+	if err != nil {
+		return &NotificationError{Recipient: d.to.Email, Channel: "email", Err: err}
+	}
+
+	if err := d.to.writeLine(ctx, msg); err != nil {
+		return &NotificationError{Recipient: d.to.Email, Channel: "email", Retryable: true, Err: err}
+	}
+
+	return nil
+}
@@ -0,0 +1,191 @@
+package notify_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+// lockedBuffer is a bytes.Buffer that can be read while a background
+// goroutine writes to it.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.String()
+}
+
+// digestFor returns a user whose digests are written to out.
+func digestFor(t *testing.T, out *lockedBuffer) *notify.User {
+	t.Helper()
+	u, err := notify.NewUser("Janet Jones", "janet@email.com",
+		notify.WithWriter(out), notify.WithLogger(slog.New(slog.DiscardHandler)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return u
+}
+
+func TestDigestNotifierFlushes(t *testing.T) {
+	tests := []struct {
+		name     string
+		maxItems int
+		add      []string
+		flush    func(*notify.DigestNotifier) error
+		want     string
+	}{
+		{"when full", 2, []string{"disk full", "cpu hot"}, nil,
+			"Sending digest of 2 notifications to Janet Jones<janet@email.com>\n- disk full\n- cpu hot\n"},
+		{"not before full", 3, []string{"disk full", "cpu hot"}, nil, ""},
+		{"on Flush", 0, []string{"disk full"}, (*notify.DigestNotifier).Flush,
+			"Sending digest of 1 notifications to Janet Jones<janet@email.com>\n- disk full\n"},
+		{"on Close", 5, []string{"disk full", "cpu hot"}, (*notify.DigestNotifier).Close,
+			"Sending digest of 2 notifications to Janet Jones<janet@email.com>\n- disk full\n- cpu hot\n"},
+		{"nothing to flush", 5, nil, (*notify.DigestNotifier).Flush, ""},
+		{"sanitized", 1, []string{"line\nbreak"}, nil,
+			"Sending digest of 1 notifications to Janet Jones<janet@email.com>\n- line\\nbreak\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out lockedBuffer
+			d := notify.NewDigestNotifier(digestFor(t, &out), tt.maxItems, 0, nil)
+			defer d.Close()
+
+			for _, msg := range tt.add {
+				if err := d.Add(msg); err != nil {
+					t.Fatalf("Add(%q) = %v", msg, err)
+				}
+			}
+			if tt.flush != nil {
+				if err := tt.flush(d); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := out.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDigestNotifierMaxAge(t *testing.T) {
+	checkNoLeaks(t)
+	var out lockedBuffer
+	clock := newFakeClock()
+	d := notify.NewDigestNotifier(digestFor(t, &out), 0, time.Minute, clock)
+	defer d.Close()
+
+	d.Add("disk full")
+	if !clock.BlockUntil(1) {
+		t.Fatal("the digest never started its timer")
+	}
+	clock.Advance(30 * time.Second)
+	d.Add("cpu hot")
+	if out.String() != "" {
+		t.Fatalf("digest sent before maxAge: %q", out.String())
+	}
+
+	clock.Advance(30 * time.Second)
+	want := "Sending digest of 2 notifications to Janet Jones<janet@email.com>\n- disk full\n- cpu hot\n"
+	waitFor(t, "the timed digest", func() bool { return out.String() == want })
+
+	// The next message starts a new timer measured from when it arrives.
+	d.Add("back to normal")
+	if !clock.BlockUntil(1) {
+		t.Fatal("the digest never restarted its timer")
+	}
+	clock.Advance(time.Minute)
+	waitFor(t, "the second timed digest", func() bool { return strings.Count(out.String(), "Sending digest") == 2 })
+}
+
+func TestDigestNotifierTimerErrors(t *testing.T) {
+	errDisk := errors.New("disk full")
+	clock := newFakeClock()
+	u, err := notify.NewUser("Janet Jones", "janet@email.com",
+		notify.WithWriter(failWriter{errDisk}), notify.WithLogger(slog.New(slog.DiscardHandler)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := notify.NewDigestNotifier(u, 0, time.Minute, clock)
+	defer d.Close()
+	errs := make(chan error, 1)
+	d.OnError = func(err error) { errs <- err }
+
+	d.Add("cpu hot")
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	if err := <-errs; !errors.Is(err, errDisk) {
+		t.Errorf("OnError got %v, want %v", err, errDisk)
+	}
+}
+
+func TestDigestNotifierClose(t *testing.T) {
+	checkNoLeaks(t)
+	var out lockedBuffer
+	d := notify.NewDigestNotifier(digestFor(t, &out), 0, time.Hour, newFakeClock())
+
+	d.Add("disk full")
+	if err := d.Close(); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+	if !strings.Contains(out.String(), "- disk full") {
+		t.Errorf("Close() didn't flush: %q", out.String())
+	}
+	if err := d.Add("cpu hot"); !errors.Is(err, notify.ErrDigestClosed) {
+		t.Errorf("Add() after Close = %v, want %v", err, notify.ErrDigestClosed)
+	}
+	if err := d.Close(); err != nil {
+		t.Errorf("second Close() = %v, want nil", err)
+	}
+}
+
+func TestDigestNotifierConcurrentAdds(t *testing.T) {
+	const adders, each = 10, 20
+	var out lockedBuffer
+	d := notify.NewDigestNotifier(digestFor(t, &out), 7, time.Hour, newFakeClock())
+
+	var wg sync.WaitGroup
+	for i := range adders {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range each {
+				if err := d.Add(fmt.Sprintf("event %d-%d", i, j)); err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if err := d.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	got := out.String()
+	if n := strings.Count(got, "\n- event "); n != adders*each {
+		t.Errorf("digests list %d events, want %d", n, adders*each)
+	}
+	if n := strings.Count(got, "Sending digest of 7 notifications"); n != adders*each/7 {
+		t.Errorf("sent %d full digests, want %d", n, adders*each/7)
+	}
+}
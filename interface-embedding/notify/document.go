@@ -0,0 +1,45 @@
+package notify
+
+// DocumentReader is implemented by anything whose contents can be read.
+type DocumentReader interface {
+	Read() string
+}
+
+// DocumentWriter is implemented by anything whose contents can be
+// replaced.
+type DocumentWriter interface {
+	Write(body string)
+}
+
+// Document sits alongside the notifiers to show both kinds of receiver on
+// one type. Read has a value receiver, so Document and *Document are both
+// DocumentReaders; Write has a pointer receiver, because it changes the
+// document, so only *Document is a DocumentWriter.
+type Document struct {
+	Title string
+	Body  string
+}
+
+// Read returns the document's title and body.
+func (d Document) Read() string {
+	return d.Title + ": " + d.Body
+}
+
+// Write replaces the document's body.
+func (d *Document) Write(body string) {
+	d.Body = body
+}
+
+// SignedDocument embeds a Document by value. Its method set follows the
+// embedded type's: a SignedDocument value gets Read, while Write, with
+// its pointer receiver, is only promoted to *SignedDocument. Read is
+// overridden to add the signature.
+type SignedDocument struct {
+	Document
+	Signer string
+}
+
+// Read returns the embedded document's contents followed by the signer.
+func (s SignedDocument) Read() string {
+	return s.Document.Read() + " -- " + s.Signer
+}
@@ -0,0 +1,45 @@
+package notify_test
+
+import (
+	"testing"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+func TestDocument(t *testing.T) {
+	doc := notify.Document{Title: "Runbook", Body: "page the on-call"}
+	var r notify.DocumentReader = doc
+	var w notify.DocumentWriter = &doc
+
+	w.Write("restart the pager")
+	if got, want := doc.Read(), "Runbook: restart the pager"; got != want {
+		t.Errorf("Read() = %q, want %q", got, want)
+	}
+	// r holds a copy made before the write.
+	if got, want := r.Read(), "Runbook: page the on-call"; got != want {
+		t.Errorf("Read() of the earlier copy = %q, want %q", got, want)
+	}
+}
+
+func TestSignedDocument(t *testing.T) {
+	doc := notify.SignedDocument{Document: notify.Document{Title: "Runbook"}, Signer: "Janet"}
+	var w notify.DocumentWriter = &doc
+	w.Write("restart the pager")
+
+	tests := []struct {
+		name string
+		r    notify.DocumentReader
+		want string
+	}{
+		{"value", doc, "Runbook: restart the pager -- Janet"},
+		{"pointer", &doc, "Runbook: restart the pager -- Janet"},
+		{"embedded document", doc.Document, "Runbook: restart the pager"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.r.Read(); got != tt.want {
+				t.Errorf("Read() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
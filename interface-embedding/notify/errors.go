@@ -0,0 +1,153 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Sentinels for the broad kinds of notification failure. Match them with
+// errors.Is; the errors returned carry more detail.
+var (
+	// ErrInvalidRecipient matches failures caused by the recipient itself,
+	// such as a malformed address, which retrying can't fix.
+	ErrInvalidRecipient = errors.New("notify: invalid recipient")
+
+	// ErrDeliveryFailed matches every *NotificationError, telling failed
+	// notifications apart from other errors such as a cancelled batch.
+	ErrDeliveryFailed = errors.New("notify: delivery failed")
+)
+
+// NotificationError records which recipient and channel a notification
+// failed for, along with the underlying cause.
+type NotificationError struct {
+	Recipient string
+	Channel   string
+
+	// Retryable reports that trying again may succeed, as after a failed
+	// write or a server error. IsRetryable consults it.
+	Retryable bool
+
+	Err error
+}
+
+// Error implements the error interface.
+func (e *NotificationError) Error() string {
+	return "notify: " + e.Channel + " to " + e.Recipient + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying cause so errors.Is and errors.As can see
+// through a NotificationError.
+func (e *NotificationError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is ErrDeliveryFailed.
+func (e *NotificationError) Is(target error) bool {
+	return target == ErrDeliveryFailed
+}
+
+// invalidRecipientError wraps an error so it matches ErrInvalidRecipient
+// and ErrPermanent while keeping its own message and chain.
+type invalidRecipientError struct {
+	err error
+}
+
+func (e *invalidRecipientError) Error() string { return e.err.Error() }
+func (e *invalidRecipientError) Unwrap() error { return e.err }
+func (e *invalidRecipientError) Is(target error) bool {
+	return target == ErrInvalidRecipient || target == ErrPermanent
+}
+
+// invalidRecipient marks err as a problem with the recipient.
+func invalidRecipient(err error) error {
+	return &invalidRecipientError{err: err}
+}
+
+// ErrPermanent marks failures that will not succeed on a later attempt.
+// Wrap it, or use Permanent, to stop a RetryNotifier from trying again.
+var ErrPermanent = errors.New("notify: permanent failure")
+
+// permanentError wraps an error so it matches ErrPermanent while keeping
+// its own message and chain.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string        { return e.err.Error() }
+func (e *permanentError) Unwrap() error        { return e.err }
+func (e *permanentError) Is(target error) bool { return target == ErrPermanent }
+
+// Permanent marks err as a failure that retrying won't fix. It returns nil
+// when err is nil.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether err, or any error it wraps, was marked
+// permanent.
+func IsPermanent(err error) bool {
+	return errors.Is(err, ErrPermanent)
+}
+
+// IsRetryable reports whether trying again might succeed where err failed.
+// Permanent errors, invalid recipients and cancellations are never
+// retryable. Otherwise the outermost *NotificationError decides through
+// its Retryable field, and errors that don't say either way, such as
+// ErrRateLimited, are assumed to be transient.
+func IsRetryable(err error) bool {
+	if err == nil || IsPermanent(err) || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var ne *NotificationError
+	if errors.As(err, &ne) {
+		return ne.Retryable
+	}
+
+	return true
+}
+
+// RecipientError is one recipient's failure within a BatchError.
+type RecipientError struct {
+	Recipient string
+	Err       error
+}
+
+// BatchError collects the failures from notifying several recipients.
+type BatchError struct {
+	errs []RecipientError
+}
+
+// Error lists each failed recipient on its own line.
+func (e *BatchError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "notify: %d notifications failed:", len(e.errs))
+	for _, re := range e.errs {
+		fmt.Fprintf(&b, "\n\t%s: %v", re.Recipient, re.Err)
+	}
+
+	return b.String()
+}
+
+// Errors returns a copy of the per-recipient failures in the order they
+// occurred.
+func (e *BatchError) Errors() []RecipientError {
+	return append([]RecipientError(nil), e.errs...)
+}
+
+// Unwrap returns every contained error, so errors.Is and errors.As match
+// if any one of them does.
+func (e *BatchError) Unwrap() []error {
+	errs := make([]error, len(e.errs))
+	for i, re := range e.errs {
+		errs[i] = re.Err
+	}
+
+	return errs
+}
@@ -0,0 +1,218 @@
+package notify_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+// failWriter fails every write with err.
+type failWriter struct{ err error }
+
+func (w failWriter) Write([]byte) (int, error) { return 0, w.err }
+
+func TestNotificationError(t *testing.T) {
+	errDisk := errors.New("disk full")
+	var buf bytes.Buffer
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name          string
+		n             notify.Notifier
+		ctx           context.Context
+		out           io.Writer
+		wantRecipient string
+		wantChannel   string
+		wantErr       error
+	}{
+		{
+			name:          "write failure",
+			n:             &notify.User{Name: "Janet", Email: "janet@email.com"},
+			ctx:           context.Background(),
+			out:           failWriter{errDisk},
+			wantRecipient: "janet@email.com",
+			wantChannel:   "email",
+			wantErr:       errDisk,
+		},
+		{
+			name:          "invalid email",
+			n:             &notify.User{Name: "Janet", Email: "not-an-email"},
+			ctx:           context.Background(),
+			wantRecipient: "not-an-email",
+			wantChannel:   "email",
+			wantErr:       notify.ErrInvalidEmail,
+		},
+		{
+			name:          "cancelled admin",
+			n:             &notify.Admin{User: notify.User{Name: "Lisa", Email: "lisa@email.com"}, Level: notify.LevelRoot},
+			ctx:           cancelled,
+			wantRecipient: "lisa@email.com",
+			wantChannel:   "email",
+			wantErr:       context.Canceled,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := tt.out
+			if out == nil {
+				out = &buf
+			}
+			tt.n.(interface{ SetOutput(io.Writer) }).SetOutput(out)
+			err := tt.n.Notify(tt.ctx)
+
+			var ne *notify.NotificationError
+			if !errors.As(err, &ne) {
+				t.Fatalf("Notify() = %v, want a *NotificationError", err)
+			}
+			if ne.Recipient != tt.wantRecipient || ne.Channel != tt.wantChannel {
+				t.Errorf("Recipient, Channel = %q, %q, want %q, %q", ne.Recipient, ne.Channel, tt.wantRecipient, tt.wantChannel)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Notify() = %v, want it to match %v", err, tt.wantErr)
+			}
+			if errors.Unwrap(err) != ne.Err {
+				t.Errorf("Unwrap() = %v, want %v", errors.Unwrap(err), ne.Err)
+			}
+			if !errors.Is(err, notify.ErrDeliveryFailed) {
+				t.Errorf("Notify() = %v, want it to match ErrDeliveryFailed", err)
+			}
+		})
+	}
+}
+
+func TestBatchError(t *testing.T) {
+	errBounce := errors.New("mailbox full")
+	err := notify.SendNotifications(context.Background(),
+		&notify.SMSUser{Phone: "bad"},
+		&notify.MockNotifier{},
+		notify.NotifierFunc(func(context.Context) error { return fmt.Errorf("smtp: %w", errBounce) }),
+	)
+
+	var be *notify.BatchError
+	if !errors.As(err, &be) {
+		t.Fatalf("SendNotifications() = %v, want a *BatchError", err)
+	}
+	want := "notify: 2 notifications failed:\n" +
+		"\tbad: notify: sms to bad: phone \"bad\" is not in E.164 format\n" +
+		"\tunknown: smtp: mailbox full"
+	if got := be.Error(); got != want {
+		t.Errorf("Error() =\n%s\nwant\n%s", got, want)
+	}
+
+	entries := be.Errors()
+	if len(entries) != 2 || entries[0].Recipient != "bad" || entries[1].Recipient != "unknown" {
+		t.Fatalf("Errors() = %+v, want bad and unknown", entries)
+	}
+	entries[0].Recipient = "changed"
+	if be.Errors()[0].Recipient != "bad" {
+		t.Error("changing the result of Errors() changed the BatchError")
+	}
+
+	tests := []struct {
+		target error
+		want   bool
+	}{
+		{errBounce, true},
+		{notify.ErrInvalidRecipient, true},
+		{notify.ErrDeliveryFailed, true},
+		{context.Canceled, false},
+	}
+	for _, tt := range tests {
+		if got := errors.Is(err, tt.target); got != tt.want {
+			t.Errorf("errors.Is(err, %v) = %v, want %v", tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	errDown := errors.New("server down")
+	retryable := &notify.NotificationError{Recipient: "janet@email.com", Channel: "email", Retryable: true, Err: errDown}
+	final := &notify.NotificationError{Recipient: "janet@email.com", Channel: "email", Err: errDown}
+	_, invalid := notify.NewUser("Janet Jones", "janet")
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"plain error", errDown, true},
+		{"rate limited", notify.ErrRateLimited, true},
+		{"retryable notification error", retryable, true},
+		{"final notification error", final, false},
+		{"wrapped retryable", fmt.Errorf("batch: %w", retryable), true},
+		{"outermost decides", &notify.NotificationError{Retryable: true, Err: final}, true},
+		{"permanent", notify.Permanent(errDown), false},
+		{"permanent inside retryable", &notify.NotificationError{Retryable: true, Err: notify.Permanent(errDown)}, false},
+		{"invalid recipient", invalid, false},
+		{"cancelled", context.Canceled, false},
+		{"wrapped cancellation", &notify.NotificationError{Retryable: true, Err: context.Canceled}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := notify.IsRetryable(tt.err); got != tt.want {
+				t.Errorf("IsRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+	if notify.Permanent(nil) != nil {
+		t.Error("Permanent(nil) != nil")
+	}
+}
+
+func TestErrorsThroughWrappers(t *testing.T) {
+	tests := []struct {
+		name      string
+		replies   map[string]string
+		to        string
+		wantIs    []error
+		retryable bool
+		wantDials int
+	}{
+		{"rejected mailbox isn't retried", map[string]string{"RCPT": "550 5.1.1 no such user"}, "janet@email.com",
+			[]error{notify.ErrSMTPRejected, notify.ErrDeliveryFailed}, false, 1},
+		{"temporary failure is retried", map[string]string{"MAIL": "451 4.3.0 try later"}, "janet@email.com",
+			[]error{notify.ErrSMTPConnect, notify.ErrDeliveryFailed}, true, 3},
+		{"invalid recipient is never sent", nil, "janet",
+			[]error{notify.ErrPermanent, notify.ErrDeliveryFailed}, false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := startFakeSMTP(t, &fakeSMTP{replies: tt.replies})
+			dialer := &countingDialer{}
+			smtpN := &notify.SMTPNotifier{
+				Addr:   srv.addr,
+				From:   "alerts@email.com",
+				To:     &notify.User{Name: "Janet Jones", Email: tt.to},
+				Body:   "hello",
+				Dialer: dialer,
+			}
+			// Timeout around retry around SMTP.
+			n := notify.WithTimeout(&notify.RetryNotifier{Notifier: smtpN, MaxAttempts: 3, Sleep: func(time.Duration) {}}, 5*time.Second)
+
+			err := n.Notify(context.Background())
+			for _, want := range tt.wantIs {
+				if !errors.Is(err, want) {
+					t.Errorf("Notify() = %v, want it to match %v", err, want)
+				}
+			}
+			var ne *notify.NotificationError
+			if !errors.As(err, &ne) || ne.Recipient != tt.to || ne.Channel != "email" {
+				t.Errorf("Notify() = %v, want a *NotificationError for %s by email", err, tt.to)
+			}
+			if got := notify.IsRetryable(err); got != tt.retryable {
+				t.Errorf("IsRetryable(%v) = %v, want %v", err, got, tt.retryable)
+			}
+			if dialer.dials != tt.wantDials {
+				t.Errorf("dialed %d times, want %d", dialer.dials, tt.wantDials)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrEscalationExhausted is matched by an *EscalationError, returned when
+// no step of an EscalationChain succeeded.
+var ErrEscalationExhausted = errors.New("notify: escalation chain exhausted")
+
+// EscalationChain models on-call escalation: each step is tried in turn,
+// such as a user's email, then their admin, then a super admin, until one
+// of them is notified.
+type EscalationChain struct {
+	Steps []Notifier
+
+	// StepTimeout, when positive, bounds each step. Deadline, when
+	// positive, bounds the whole chain, so steps that haven't been
+	// reached by then are never tried.
+	StepTimeout time.Duration
+	Deadline    time.Duration
+}
+
+// EscalationResult reports which step of a chain was notified.
+type EscalationResult struct {
+	// Step is the index in Steps of the step that succeeded.
+	Step     int
+	Notifier Notifier
+
+	// Failures holds the errors of the steps tried before it, in order.
+	Failures []error
+}
+
+// EscalationError is returned when every step of a chain failed, or the
+// chain's deadline passed first. It holds each attempted step's error.
+type EscalationError struct {
+	errs []error
+}
+
+// Error lists each attempted step's failure on its own line.
+func (e *EscalationError) Error() string {
+	var b strings.Builder
+	b.WriteString("notify: escalation failed:")
+	for i, err := range e.errs {
+		fmt.Fprintf(&b, "\n\tstep %d: %v", i, err)
+	}
+
+	return b.String()
+}
+
+// Errors returns a copy of the step failures, indexed by step.
+func (e *EscalationError) Errors() []error {
+	return append([]error(nil), e.errs...)
+}
+
+// Is reports whether target is ErrEscalationExhausted.
+func (e *EscalationError) Is(target error) bool {
+	return target == ErrEscalationExhausted
+}
+
+// Unwrap returns every step failure, so errors.Is and errors.As match if
+// any one of them does.
+func (e *EscalationError) Unwrap() []error {
+	return e.Errors()
+}
+
+// Escalate tries each step in order and stops at the first that succeeds.
+// A step is tried at most once, so a step that was notified isn't sent
+// again whatever happens to ctx afterwards. Once ctx is done or the
+// chain's deadline passes, the remaining steps are skipped and their
+// context error is recorded as the failure of the step that was next.
+func (c *EscalationChain) Escalate(ctx context.Context) (EscalationResult, error) {
+	if c.Deadline > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Deadline)
+		defer cancel()
+	}
+
+	var errs []error
+	for i, n := range c.Steps {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+
+		err := c.notify(ctx, n)
+		if err == nil {
+			return EscalationResult{Step: i, Notifier: n, Failures: errs}, nil
+		}
+		errs = append(errs, err)
+	}
+
+	return EscalationResult{Step: -1, Failures: errs}, &EscalationError{errs: errs}
+}
+
+// Notify escalates, discarding which step was notified.
+func (c *EscalationChain) Notify(ctx context.Context) error {
+	_, err := c.Escalate(ctx)
+	return err
+}
+
+// notify sends one step within StepTimeout.
+func (c *EscalationChain) notify(ctx context.Context, n Notifier) error {
+	if c.StepTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.StepTimeout)
+		defer cancel()
+	}
+
+	return n.Notify(ctx)
+}
@@ -0,0 +1,137 @@
+package notify_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+// blocking is a step that never answers before its context is done.
+var blocking = notify.NotifierFunc(func(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+})
+
+func TestEscalate(t *testing.T) {
+	errDown := errors.New("server down")
+
+	tests := []struct {
+		name      string
+		errs      []error
+		wantStep  int
+		wantCalls []int
+	}{
+		{"first step", []error{nil, nil, nil}, 0, []int{1, 0, 0}},
+		{"second step", []error{errDown, nil, nil}, 1, []int{1, 1, 0}},
+		{"last step", []error{errDown, errDown, nil}, 2, []int{1, 1, 1}},
+		{"exhausted", []error{errDown, errDown, errDown}, -1, []int{1, 1, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &notify.EscalationChain{}
+			var mocks []*notify.MockNotifier
+			for _, err := range tt.errs {
+				m := &notify.MockNotifier{Err: err}
+				mocks = append(mocks, m)
+				c.Steps = append(c.Steps, m)
+			}
+
+			res, err := c.Escalate(context.Background())
+			if res.Step != tt.wantStep {
+				t.Errorf("Step = %d, want %d", res.Step, tt.wantStep)
+			}
+			for i, m := range mocks {
+				if m.Calls() != tt.wantCalls[i] {
+					t.Errorf("step %d called %d times, want %d", i, m.Calls(), tt.wantCalls[i])
+				}
+			}
+
+			if tt.wantStep < 0 {
+				var ee *notify.EscalationError
+				if !errors.As(err, &ee) || !errors.Is(err, notify.ErrEscalationExhausted) || !errors.Is(err, errDown) {
+					t.Fatalf("Escalate() = %v, want an exhausted *EscalationError wrapping %v", err, errDown)
+				}
+				if len(ee.Errors()) != len(tt.errs) {
+					t.Errorf("Errors() = %v, want one per step", ee.Errors())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Escalate() = %v", err)
+			}
+			if res.Notifier != c.Steps[tt.wantStep] || len(res.Failures) != tt.wantStep {
+				t.Errorf("result = %+v, want step %d's notifier after %d failures", res, tt.wantStep, tt.wantStep)
+			}
+		})
+	}
+}
+
+func TestEscalateStepTimeout(t *testing.T) {
+	next := &notify.MockNotifier{}
+	c := &notify.EscalationChain{Steps: []notify.Notifier{blocking, next}, StepTimeout: 10 * time.Millisecond}
+
+	res, err := c.Escalate(context.Background())
+	if err != nil {
+		t.Fatalf("Escalate() = %v, want the second step to succeed", err)
+	}
+	if res.Step != 1 || len(res.Failures) != 1 || !errors.Is(res.Failures[0], context.DeadlineExceeded) {
+		t.Errorf("result = %+v, want step 1 after the first step timed out", res)
+	}
+}
+
+func TestEscalateDeadline(t *testing.T) {
+	later := &notify.MockNotifier{}
+	c := &notify.EscalationChain{
+		Steps:       []notify.Notifier{blocking, later, later},
+		StepTimeout: time.Minute,
+		Deadline:    20 * time.Millisecond,
+	}
+
+	start := time.Now()
+	res, err := c.Escalate(context.Background())
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("Escalate() took %v, want it cut off by the deadline", elapsed)
+	}
+	if !errors.Is(err, notify.ErrEscalationExhausted) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Escalate() = %v, want an exhausted chain past its deadline", err)
+	}
+	if res.Step != -1 || later.Calls() != 0 {
+		t.Errorf("Step = %d and later steps called %d times, want none reached", res.Step, later.Calls())
+	}
+}
+
+func TestEscalateCancelledAfterSuccess(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	first := &notify.MockNotifier{Err: errors.New("server down")}
+	calls := 0
+	second := notify.NotifierFunc(func(context.Context) error {
+		calls++
+		cancel()
+		return nil
+	})
+	last := &notify.MockNotifier{}
+	c := &notify.EscalationChain{Steps: []notify.Notifier{first, second, last}}
+
+	if err := c.Notify(ctx); err != nil {
+		t.Fatalf("Notify() = %v, want the second step's success to stand", err)
+	}
+	if first.Calls() != 1 || calls != 1 || last.Calls() != 0 {
+		t.Errorf("steps called %d, %d, %d times, want 1, 1, 0", first.Calls(), calls, last.Calls())
+	}
+}
+
+func TestEscalationErrorMessage(t *testing.T) {
+	c := &notify.EscalationChain{Steps: []notify.Notifier{
+		&notify.MockNotifier{Err: errors.New("mailbox full")},
+		&notify.MockNotifier{Err: errors.New("no signal")},
+	}}
+	_, err := c.Escalate(context.Background())
+	want := "notify: escalation failed:\n\tstep 0: mailbox full\n\tstep 1: no signal"
+	if err == nil || err.Error() != want {
+		t.Errorf("Escalate() = %v, want %q", err, want)
+	}
+}
@@ -0,0 +1,95 @@
+package notify
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// NotificationEvent describes one call to SendNotification.
+type NotificationEvent struct {
+	Recipient string
+	Channel   string
+	Err       error
+	Time      time.Time
+}
+
+// EventBus delivers a NotificationEvent to every subscriber after each
+// send. The zero value is ready to use and safe for concurrent use.
+type EventBus struct {
+	mu     sync.Mutex
+	nextID int
+	subs   []eventSubscriber
+	clock  Clock
+}
+
+// eventSubscriber is one callback subscribed to an EventBus.
+type eventSubscriber struct {
+	id int
+	fn func(NotificationEvent)
+}
+
+// DefaultBus is the bus SendNotification publishes to.
+var DefaultBus EventBus
+
+// Subscribe registers fn to receive events and returns a function that
+// stops delivery. Calling cancel more than once has no further effect.
+func (b *EventBus) Subscribe(fn func(event NotificationEvent)) (cancel func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextID++
+	id := b.nextID
+	b.subs = append(b.subs, eventSubscriber{id: id, fn: fn})
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+
+			b.subs = slices.DeleteFunc(slices.Clone(b.subs), func(s eventSubscriber) bool {
+				return s.id == id
+			})
+		})
+	}
+}
+
+// SetClock sets the clock published events are stamped with. It defaults
+// to SystemClock, which a nil clock restores.
+func (b *EventBus) SetClock(clock Clock) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.clock = clock
+}
+
+// Publish delivers event to every current subscriber, in the order they
+// subscribed. Subscribers are called without the bus locked, so they may
+// subscribe or cancel themselves.
+func (b *EventBus) Publish(event NotificationEvent) {
+	b.mu.Lock()
+	subs := b.subs
+	b.mu.Unlock()
+
+	for _, s := range subs {
+		s.fn(event)
+	}
+}
+
+// published records the outcome of sending through n, skipping the work
+// of describing n when nobody is listening.
+func (b *EventBus) published(n Notifier, err error) {
+	b.mu.Lock()
+	listening, clock := len(b.subs) > 0, b.clock
+	b.mu.Unlock()
+	if !listening {
+		return
+	}
+	if clock == nil {
+		clock = SystemClock
+	}
+
+	recipient, channel := AddressOf(n)
+	b.Publish(NotificationEvent{Recipient: recipient, Channel: channel, Err: err, Time: clock.Now()})
+}
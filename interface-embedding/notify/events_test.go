@@ -0,0 +1,106 @@
+package notify_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+func TestEventBusSubscribers(t *testing.T) {
+	var mu sync.Mutex
+	var first, second []notify.NotificationEvent
+	cancelFirst := notify.DefaultBus.Subscribe(func(e notify.NotificationEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		first = append(first, e)
+	})
+	defer cancelFirst()
+	cancelSecond := notify.DefaultBus.Subscribe(func(e notify.NotificationEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		second = append(second, e)
+	})
+	defer cancelSecond()
+
+	errDown := errors.New("down")
+	sms := &notify.SMSUser{Phone: "+14155552671"}
+	notify.SendNotification(context.Background(), notify.NotifierFunc(func(context.Context) error { return errDown }))
+	sms.SetOutput(io.Discard)
+	notify.SendNotification(context.Background(), sms)
+
+	if len(first) != 2 || len(second) != 2 {
+		t.Fatalf("subscribers got %d and %d events, want 2 each", len(first), len(second))
+	}
+	if first[0].Err != errDown || first[0].Time.IsZero() {
+		t.Errorf("first event = %+v, want the failure with a time", first[0])
+	}
+	if e := second[1]; e.Recipient != "+14155552671" || e.Channel != "sms" || e.Err != nil {
+		t.Errorf("second event = %+v, want a successful sms to +14155552671", e)
+	}
+
+	cancelFirst()
+	cancelFirst()
+	notify.SendNotification(context.Background(), sms)
+	if len(first) != 2 || len(second) != 3 {
+		t.Errorf("after cancelling, subscribers got %d and %d events, want 2 and 3", len(first), len(second))
+	}
+}
+
+func TestEventBusClock(t *testing.T) {
+	start := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	notify.DefaultBus.SetClock(notify.NewSimClock(start))
+	t.Cleanup(func() { notify.DefaultBus.SetClock(nil) })
+
+	var got []time.Time
+	cancel := notify.DefaultBus.Subscribe(func(e notify.NotificationEvent) { got = append(got, e.Time) })
+	defer cancel()
+
+	notify.SendNotification(context.Background(), &notify.MockNotifier{})
+	notify.DefaultBus.SetClock(nil)
+	notify.SendNotification(context.Background(), &notify.MockNotifier{})
+
+	if len(got) != 2 {
+		t.Fatalf("got %d events, want 2", len(got))
+	}
+	if !got[0].Equal(start) {
+		t.Errorf("event stamped %v, want %v from the clock", got[0], start)
+	}
+	if got[1].Before(time.Now().Add(-time.Minute)) {
+		t.Errorf("event stamped %v after resetting the clock, want the system time", got[1])
+	}
+}
+
+func TestEventBusConcurrent(t *testing.T) {
+	var bus notify.EventBus
+	var got atomic.Int64
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			cancel := bus.Subscribe(func(notify.NotificationEvent) { got.Add(1) })
+			for range 20 {
+				bus.Publish(notify.NotificationEvent{})
+			}
+			cancel()
+		}()
+		go func() {
+			defer wg.Done()
+			for range 20 {
+				bus.Publish(notify.NotificationEvent{})
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Each subscriber sees at least its own 20 events.
+	if n := got.Load(); n < 200 {
+		t.Errorf("delivered %d events, want at least 200", n)
+	}
+}
@@ -0,0 +1,163 @@
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// ErrUnknownChannel is returned by NewNotifier for a channel that hasn't
+// been registered.
+var ErrUnknownChannel = errors.New("notify: unknown channel")
+
+// ChannelFactory builds a notifier for one channel from string settings,
+// such as those read from flags or a config file. The options configure
+// the User that the notifier delivers to.
+type ChannelFactory func(cfg map[string]string, opts ...UserOption) (Notifier, error)
+
+var (
+	channelsMu sync.RWMutex
+	channels   = make(map[string]ChannelFactory)
+)
+
+// RegisterChannel makes a channel available to NewNotifier under name. It
+// is meant to be called from init functions, and panics if name is
+// already registered or factory is nil, as database/sql.Register does.
+func RegisterChannel(name string, factory ChannelFactory) {
+	channelsMu.Lock()
+	defer channelsMu.Unlock()
+
+	if factory == nil {
+		panic("notify: RegisterChannel factory for " + name + " is nil")
+	}
+	if _, dup := channels[name]; dup {
+		panic("notify: RegisterChannel called twice for channel " + name)
+	}
+	channels[name] = factory
+}
+
+// Channels returns the names of the registered channels, sorted.
+func Channels() []string {
+	channelsMu.RLock()
+	defer channelsMu.RUnlock()
+
+	return slices.Sorted(maps.Keys(channels))
+}
+
+// NewNotifier builds a notifier for the named channel from cfg. Unknown
+// names are reported as ErrUnknownChannel, listing the channels there are.
+func NewNotifier(name string, cfg map[string]string, opts ...UserOption) (Notifier, error) {
+	channelsMu.RLock()
+	factory, ok := channels[name]
+	channelsMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w %q: want one of %s", ErrUnknownChannel, name, strings.Join(Channels(), ", "))
+	}
+
+	return factory(cfg, opts...)
+}
+
+// The built-in channels all read the recipient from the "name" and
+// "email" settings.
+func init() {
+	RegisterChannel("email", newEmailNotifier)
+	RegisterChannel("sms", newSMSNotifier)
+	RegisterChannel("slack", newSlackNotifier)
+	RegisterChannel("webhook", newWebhookNotifier)
+}
+
+// newEmailNotifier builds a *User, or an *Admin when "level" is set.
+func newEmailNotifier(cfg map[string]string, opts ...UserOption) (Notifier, error) {
+	if cfg["level"] == "" {
+		return NewUser(cfg["name"], cfg["email"], opts...)
+	}
+
+	level, err := ParseLevel(cfg["level"])
+	if err != nil {
+		return nil, err
+	}
+	adminOpts := []AdminOption{WithLevel(level)}
+	for _, opt := range opts {
+		adminOpts = append(adminOpts, opt)
+	}
+
+	return NewAdmin(cfg["name"], cfg["email"], adminOpts...)
+}
+
+// newSMSNotifier builds an *SMSUser texting the "phone" setting.
+func newSMSNotifier(cfg map[string]string, opts ...UserOption) (Notifier, error) {
+	if cfg["level"] != "" {
+		return nil, errors.New("notify: sms channel: \"level\" setting is not supported")
+	}
+	if err := requireSettings("sms", cfg, "phone"); err != nil {
+		return nil, err
+	}
+	u, err := NewUser(cfg["name"], cfg["email"], opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &SMSUser{User: *u, Phone: cfg["phone"]}
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// newSlackNotifier builds a *SlackUser posting to the "url" and "channel"
+// settings.
+func newSlackNotifier(cfg map[string]string, opts ...UserOption) (Notifier, error) {
+	if cfg["level"] != "" {
+		return nil, errors.New("notify: slack channel: \"level\" setting is not supported")
+	}
+	if err := requireSettings("slack", cfg, "url", "channel"); err != nil {
+		return nil, err
+	}
+	u, err := NewUser(cfg["name"], cfg["email"], opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &SlackUser{User: *u, WebhookURL: cfg["url"], Channel: cfg["channel"]}
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// newWebhookNotifier builds a *WebhookNotifier posting to the "url"
+// setting, signed with "secret" and including "level" when they are set.
+func newWebhookNotifier(cfg map[string]string, opts ...UserOption) (Notifier, error) {
+	if err := requireSettings("webhook", cfg, "url"); err != nil {
+		return nil, err
+	}
+	u, err := NewUser(cfg["name"], cfg["email"], opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &WebhookNotifier{URL: cfg["url"], To: u, Secret: []byte(cfg["secret"])}
+	if cfg["level"] != "" {
+		if w.Level, err = ParseLevel(cfg["level"]); err != nil {
+			return nil, err
+		}
+	}
+
+	return w, nil
+}
+
+// requireSettings reports the first of keys missing from cfg.
+func requireSettings(channel string, cfg map[string]string, keys ...string) error {
+	for _, key := range keys {
+		if cfg[key] == "" {
+			return fmt.Errorf("notify: %s channel: %q setting is required", channel, key)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,143 @@
+package notify_test
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+func TestNewNotifier(t *testing.T) {
+	janet := map[string]string{"name": "Janet Jones", "email": "janet@email.com"}
+	with := func(extra ...string) map[string]string {
+		cfg := map[string]string{"name": "Janet Jones", "email": "janet@email.com"}
+		for i := 0; i < len(extra); i += 2 {
+			cfg[extra[i]] = extra[i+1]
+		}
+		return cfg
+	}
+
+	tests := []struct {
+		name     string
+		channel  string
+		cfg      map[string]string
+		wantType string
+		wantErr  error
+		errText  string
+	}{
+		{"email user", "email", janet, "*notify.User", nil, ""},
+		{"email admin", "email", with("level", "root"), "*notify.Admin", nil, ""},
+		{"sms", "sms", with("phone", "+15555550100"), "*notify.SMSUser", nil, ""},
+		{"slack", "slack", with("url", "https://hooks.slack.com/x", "channel", "#ops"), "*notify.SlackUser", nil, ""},
+		{"webhook", "webhook", with("url", "http://127.0.0.1/hook", "level", "super", "secret", "s"), "*notify.WebhookNotifier", nil, ""},
+		{"unknown channel", "fax", janet, "", notify.ErrUnknownChannel, "want one of email, slack, sms, webhook"},
+		{"invalid email", "email", map[string]string{"name": "Janet", "email": "janet"}, "", notify.ErrInvalidEmail, ""},
+		{"missing name", "email", map[string]string{"email": "janet@email.com"}, "", notify.ErrEmptyName, ""},
+		{"bad level", "email", with("level", "god"), "", notify.ErrUnknownLevel, ""},
+		{"sms without phone", "sms", janet, "", nil, `"phone" setting is required`},
+		{"sms with a bad phone", "sms", with("phone", "555-0100"), "", notify.ErrInvalidRecipient, ""},
+		{"sms with a level", "sms", with("phone", "+15555550100", "level", "root"), "", nil, `"level" setting is not supported`},
+		{"slack without channel", "slack", with("url", "https://hooks.slack.com/x"), "", nil, `"channel" setting is required`},
+		{"webhook without url", "webhook", janet, "", nil, `"url" setting is required`},
+		{"webhook bad level", "webhook", with("url", "http://127.0.0.1/hook", "level", "god"), "", notify.ErrUnknownLevel, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := notify.NewNotifier(tt.channel, tt.cfg)
+			if tt.wantType != "" {
+				if err != nil {
+					t.Fatalf("NewNotifier() = %v", err)
+				}
+				if got := fmt.Sprintf("%T", n); got != tt.wantType {
+					t.Errorf("NewNotifier() built %s, want %s", got, tt.wantType)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("NewNotifier() = %T, want an error", n)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("NewNotifier() = %v, want %v", err, tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.errText) {
+				t.Errorf("NewNotifier() = %v, want it to mention %q", err, tt.errText)
+			}
+		})
+	}
+}
+
+func TestRegisterChannel(t *testing.T) {
+	errConfig := errors.New("bad config")
+	name := "test-" + t.Name()
+	notify.RegisterChannel(name, func(cfg map[string]string, opts ...notify.UserOption) (notify.Notifier, error) {
+		if cfg["fail"] != "" {
+			return nil, errConfig
+		}
+		return &notify.MockNotifier{Name: cfg["name"]}, nil
+	})
+
+	if !slices.Contains(notify.Channels(), name) {
+		t.Errorf("Channels() = %v, want it to include %q", notify.Channels(), name)
+	}
+	n, err := notify.NewNotifier(name, map[string]string{"name": "mock"})
+	if m, ok := n.(*notify.MockNotifier); err != nil || !ok || m.Name != "mock" {
+		t.Errorf("NewNotifier() = %v, %v, want the factory's mock", n, err)
+	}
+	if _, err := notify.NewNotifier(name, map[string]string{"fail": "yes"}); !errors.Is(err, errConfig) {
+		t.Errorf("NewNotifier() = %v, want the factory's error %v", err, errConfig)
+	}
+}
+
+func TestRegisterChannelPanics(t *testing.T) {
+	factory := func(map[string]string, ...notify.UserOption) (notify.Notifier, error) { return nil, nil }
+
+	tests := []struct {
+		name    string
+		channel string
+		factory notify.ChannelFactory
+		want    string
+	}{
+		{"duplicate", "email", factory, "notify: RegisterChannel called twice for channel email"},
+		{"nil factory", "test-nil-factory", nil, "notify: RegisterChannel factory for test-nil-factory is nil"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if got := recover(); got != tt.want {
+					t.Errorf("RegisterChannel() panicked with %v, want %q", got, tt.want)
+				}
+			}()
+			notify.RegisterChannel(tt.channel, tt.factory)
+		})
+	}
+}
+
+func TestNewNotifierConcurrent(t *testing.T) {
+	cfg := map[string]string{"name": "Janet Jones", "email": "janet@email.com"}
+	factory := func(map[string]string, ...notify.UserOption) (notify.Notifier, error) {
+		return &notify.MockNotifier{}, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for range 50 {
+				if _, err := notify.NewNotifier("email", cfg); err != nil {
+					t.Error(err)
+				}
+				notify.Channels()
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			notify.RegisterChannel(fmt.Sprintf("test-concurrent-%d", i), factory)
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,106 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// FallbackNotifier tries Primary and, if it fails, each of Fallbacks in
+// order until one succeeds, such as falling back from email to SMS.
+type FallbackNotifier struct {
+	Primary   Notifier
+	Fallbacks []Notifier
+
+	// RetryableOnly stops at the first failure that IsRetryable says would
+	// recur, such as a rejected message, instead of trying the rest.
+	RetryableOnly bool
+}
+
+// Fallback returns a notifier that tries primary and falls back to
+// secondary only when primary's failure is retryable. Fallbacks compose:
+// Fallback(Fallback(email, sms), console) tries each in turn, and a
+// permanent failure anywhere stops the chain.
+func Fallback(primary, secondary Notifier) *FallbackNotifier {
+	return &FallbackNotifier{Primary: primary, Fallbacks: []Notifier{secondary}, RetryableOnly: true}
+}
+
+// Notify delivers through the first notifier that succeeds.
+func (f *FallbackNotifier) Notify(ctx context.Context) error {
+	_, err := f.NotifyVia(ctx)
+	return err
+}
+
+// NotifyVia delivers through the first notifier that succeeds and returns
+// it. Notifiers after the successful one are not attempted. If every
+// notifier tried fails, their errors are joined in the order they were
+// tried; with RetryableOnly, the result is only retryable if the last of
+// them was. Send reports the notifier that delivered as
+// Result.DeliveredBy.
+func (f *FallbackNotifier) NotifyVia(ctx context.Context) (Notifier, error) {
+	var errs []error
+	for _, n := range append([]Notifier{f.Primary}, f.Fallbacks...) {
+		err := n.Notify(ctx)
+		if err == nil {
+			deliveredBy(ctx, n)
+			return n, nil
+		}
+		errs = append(errs, err)
+
+		if ctx.Err() != nil {
+			break
+		}
+		if f.RetryableOnly && !IsRetryable(err) {
+			if len(errs) > 1 {
+				return nil, Permanent(errors.Join(errs...))
+			}
+			break
+		}
+	}
+
+	return nil, errors.Join(errs...)
+}
+
+// Unwrap returns the primary notifier.
+func (f *FallbackNotifier) Unwrap() Notifier {
+	return f.Primary
+}
+
+// ConsoleNotifier writes a line describing the notification to Out, which
+// defaults to os.Stdout. It never fails, so it can end a Fallback chain
+// and guarantee the notification is at least seen when every real channel
+// is down.
+type ConsoleNotifier struct {
+	// For is the notification being stood in for; its recipient is named
+	// in the line written.
+	For Notifier
+	Out io.Writer
+}
+
+// Notify writes the line and returns nil, even if ctx is done or the write
+// fails.
+func (c *ConsoleNotifier) Notify(ctx context.Context) error {
+	out := c.Out
+	if out == nil {
+		out = os.Stdout
+	}
+	recipient, channel := "unknown", "unknown"
+	if c.For != nil {
+		recipient, channel = AddressOf(c.For)
+	}
+	fmt.Fprintf(out, "Console notification for %s (%s unavailable)\n", Sanitize(recipient), Sanitize(channel))
+
+	return nil
+}
+
+// Address reports the recipient of For and the console channel.
+func (c *ConsoleNotifier) Address() (recipient, channel string) {
+	if c.For == nil {
+		return "unknown", "console"
+	}
+	recipient, _ = AddressOf(c.For)
+
+	return recipient, "console"
+}
@@ -0,0 +1,236 @@
+package notify_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+func TestFallbackNotifier(t *testing.T) {
+	errEmail := errors.New("email down")
+	errSMS := errors.New("sms down")
+	errSlack := errors.New("slack down")
+
+	tests := []struct {
+		name      string
+		errs      []error // primary, then each fallback
+		wantVia   int     // index of the notifier that delivered, or -1
+		wantCalls []int
+	}{
+		{"primary succeeds", []error{nil, nil, nil}, 0, []int{1, 0, 0}},
+		{"first fallback succeeds", []error{errEmail, nil, nil}, 1, []int{1, 1, 0}},
+		{"second fallback succeeds", []error{errEmail, errSMS, nil}, 2, []int{1, 1, 1}},
+		{"all fail", []error{errEmail, errSMS, errSlack}, -1, []int{1, 1, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mocks []*notify.MockNotifier
+			var fallbacks []notify.Notifier
+			for _, err := range tt.errs {
+				m := &notify.MockNotifier{Err: err}
+				mocks = append(mocks, m)
+				if len(mocks) > 1 {
+					fallbacks = append(fallbacks, m)
+				}
+			}
+			f := &notify.FallbackNotifier{Primary: mocks[0], Fallbacks: fallbacks}
+
+			via, err := f.NotifyVia(context.Background())
+			if tt.wantVia >= 0 {
+				if err != nil || via != mocks[tt.wantVia] {
+					t.Errorf("NotifyVia() = %v, %v, want notifier %d and nil", via, err, tt.wantVia)
+				}
+			} else {
+				if via != nil {
+					t.Errorf("NotifyVia() delivered via %v, want nil", via)
+				}
+				for _, want := range tt.errs {
+					if !errors.Is(err, want) {
+						t.Errorf("NotifyVia() = %v, want it to join %v", err, want)
+					}
+				}
+			}
+			for i, m := range mocks {
+				if m.Calls() != tt.wantCalls[i] {
+					t.Errorf("notifier %d called %d times, want %d", i, m.Calls(), tt.wantCalls[i])
+				}
+			}
+		})
+	}
+}
+
+func TestFallbackNotifierStopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	primary := notify.NotifierFunc(func(context.Context) error {
+		cancel()
+		return context.Canceled
+	})
+	backup := &notify.MockNotifier{}
+
+	f := &notify.FallbackNotifier{Primary: primary, Fallbacks: []notify.Notifier{backup}}
+	if err := f.Notify(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Notify() = %v, want %v", err, context.Canceled)
+	}
+	if backup.Calls() != 0 {
+		t.Errorf("fallback called %d times after ctx was cancelled, want 0", backup.Calls())
+	}
+}
+
+func TestFallback(t *testing.T) {
+	errDown := errors.New("server down")
+	errRejected := notify.Permanent(errors.New("message rejected"))
+	errBusy := errors.New("line busy")
+
+	tests := []struct {
+		name      string
+		errs      []error // a, b and c in Fallback(Fallback(a, b), c)
+		wantVia   int     // index of the notifier that delivered, or -1
+		wantCalls []int
+		wantErrs  []error
+		retryable bool
+	}{
+		{"primary delivers", []error{nil, nil, nil}, 0, []int{1, 0, 0}, nil, false},
+		{"retryable falls back", []error{errDown, nil, nil}, 1, []int{1, 1, 0}, nil, false},
+		{"falls through nested", []error{errDown, errBusy, nil}, 2, []int{1, 1, 1}, nil, false},
+		{"permanent stops at primary", []error{errRejected, nil, nil}, -1, []int{1, 0, 0}, []error{errRejected}, false},
+		{"permanent stops in nested", []error{errDown, errRejected, nil}, -1, []int{1, 1, 0}, []error{errDown, errRejected}, false},
+		{"all retryable fail", []error{errDown, errBusy, errDown}, -1, []int{1, 1, 1}, []error{errDown, errBusy}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mocks []*notify.MockNotifier
+			for _, err := range tt.errs {
+				mocks = append(mocks, &notify.MockNotifier{Err: err})
+			}
+			f := notify.Fallback(notify.Fallback(mocks[0], mocks[1]), mocks[2])
+
+			r, err := notify.Send(context.Background(), f)
+			if tt.wantVia >= 0 {
+				// The innermost fallback names the notifier that
+				// delivered, not the nested Fallback that contained it.
+				if err != nil || r.DeliveredBy != mocks[tt.wantVia] {
+					t.Errorf("Send() delivered by %v, error %v, want notifier %d and nil", r.DeliveredBy, err, tt.wantVia)
+				}
+			} else {
+				if r.DeliveredBy != nil {
+					t.Errorf("Send() delivered by %v, want nil after a failure", r.DeliveredBy)
+				}
+				for _, want := range tt.wantErrs {
+					if !errors.Is(err, want) {
+						t.Errorf("Send() = %v, want it to match %v", err, want)
+					}
+				}
+				if notify.IsRetryable(err) != tt.retryable {
+					t.Errorf("IsRetryable(%v) = %v, want %v", err, !tt.retryable, tt.retryable)
+				}
+			}
+			for i, m := range mocks {
+				if m.Calls() != tt.wantCalls[i] {
+					t.Errorf("notifier %d called %d times, want %d", i, m.Calls(), tt.wantCalls[i])
+				}
+			}
+		})
+	}
+}
+
+func TestFallbackNotifierRetryableOnly(t *testing.T) {
+	errRejected := notify.Permanent(errors.New("message rejected"))
+
+	tests := []struct {
+		name          string
+		retryableOnly bool
+		wantCalls     int
+	}{
+		{"tries every fallback by default", false, 1},
+		{"stops at a permanent failure", true, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backup := &notify.MockNotifier{}
+			f := &notify.FallbackNotifier{
+				Primary:       &notify.MockNotifier{Err: errRejected},
+				Fallbacks:     []notify.Notifier{backup},
+				RetryableOnly: tt.retryableOnly,
+			}
+			f.Notify(context.Background())
+			if backup.Calls() != tt.wantCalls {
+				t.Errorf("fallback called %d times, want %d", backup.Calls(), tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestDeliveredByThroughResults(t *testing.T) {
+	errDown := errors.New("server down")
+	var buf bytes.Buffer
+	u := discardUser(t)
+	console := &notify.ConsoleNotifier{For: u, Out: &buf}
+
+	ns := []notify.Notifier{
+		u,
+		notify.Fallback(&notify.MockNotifier{Err: errDown}, console),
+		notify.Fallback(u, console),
+	}
+	report := notify.SendAll(context.Background(), ns, notify.WithConcurrency(1))
+	want := []notify.Notifier{nil, console, u}
+	for i, o := range report.Outcomes {
+		if o.DeliveredBy != want[i] {
+			t.Errorf("outcome %d delivered by %v, want %v", i, o.DeliveredBy, want[i])
+		}
+	}
+	if report.Sent != len(ns) {
+		t.Errorf("sent %d, want %d", report.Sent, len(ns))
+	}
+	if got, wantOut := buf.String(), "Console notification for janet@email.com (email unavailable)\n"; got != wantOut {
+		t.Errorf("console wrote %q, want %q", got, wantOut)
+	}
+}
+
+func TestConsoleNotifier(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name          string
+		ctx           context.Context
+		n             *notify.ConsoleNotifier
+		want          string
+		wantRecipient string
+	}{
+		{"user", context.Background(), &notify.ConsoleNotifier{For: &notify.User{Email: "janet@email.com"}},
+			"Console notification for janet@email.com (email unavailable)\n", "janet@email.com"},
+		{"sms", context.Background(), &notify.ConsoleNotifier{For: &notify.SMSUser{Phone: "+15555550100"}},
+			"Console notification for +15555550100 (sms unavailable)\n", "+15555550100"},
+		{"nothing stood in for", context.Background(), &notify.ConsoleNotifier{},
+			"Console notification for unknown (unknown unavailable)\n", "unknown"},
+		{"control characters escaped", context.Background(), &notify.ConsoleNotifier{For: &notify.User{Email: "janet@email.com\nFAKE"}},
+			"Console notification for janet@email.com\\nFAKE (email unavailable)\n", "janet@email.com\nFAKE"},
+		{"cancelled", cancelled, &notify.ConsoleNotifier{For: &notify.User{Email: "janet@email.com"}},
+			"Console notification for janet@email.com (email unavailable)\n", "janet@email.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			tt.n.Out = &buf
+			if err := tt.n.Notify(tt.ctx); err != nil {
+				t.Errorf("Notify() = %v, want nil", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("wrote %q, want %q", got, tt.want)
+			}
+			if recipient, channel := tt.n.Address(); recipient != tt.wantRecipient || channel != "console" {
+				t.Errorf("Address() = %q, %q, want %q, console", recipient, channel, tt.wantRecipient)
+			}
+		})
+	}
+}
+
+func TestConsoleNotifierNeverFails(t *testing.T) {
+	c := &notify.ConsoleNotifier{For: &notify.User{Email: "janet@email.com"}, Out: failWriter{errors.New("disk full")}}
+	if err := c.Notify(context.Background()); err != nil {
+		t.Errorf("Notify() = %v, want nil even when the write fails", err)
+	}
+}
@@ -0,0 +1,113 @@
+package notify
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+)
+
+// maxRequestBody bounds the size of a notification request.
+const maxRequestBody = 64 << 10
+
+// notifyRequest is the JSON body accepted by NotifyHandler.
+type notifyRequest struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	Level Level  `json:"level"`
+}
+
+// NotifyHandler returns a handler for POST /notify. The JSON body
+// {"name":...,"email":...,"level":...} describes the recipient: an admin
+// when level is present, otherwise a user, built with opts applied. When
+// reg is not nil and holds a notifier under the request's email, that
+// notifier is sent instead. The handler only reads reg: requests never add
+// to it, so callers can't grow it.
+//
+// It responds 202 once the notification is sent, 400 for invalid
+// requests, 413 for bodies over 64KiB, 415 for anything but
+// application/json, and 500 when the notification fails. Error responses
+// have a JSON body of the form {"error":"..."}.
+func NotifyHandler(reg *Registry[Notifier], opts ...UserOption) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /notify", func(w http.ResponseWriter, r *http.Request) {
+		mt, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if err != nil || mt != "application/json" {
+			writeError(w, http.StatusUnsupportedMediaType, errors.New("content type must be application/json"))
+			return
+		}
+
+		req, err := decodeNotifyRequest(http.MaxBytesReader(w, r.Body, maxRequestBody))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				writeError(w, http.StatusRequestEntityTooLarge, err)
+				return
+			}
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		n, err := req.recipient(opts)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		if reg != nil {
+			if registered, ok := reg.Get(req.Email); ok {
+				n = registered
+			}
+		}
+
+		if err := SendNotification(r.Context(), n); err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	return mux
+}
+
+// decodeNotifyRequest decodes exactly one request object from r.
+func decodeNotifyRequest(r io.Reader) (notifyRequest, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+
+	var req notifyRequest
+	if err := dec.Decode(&req); err != nil {
+		return notifyRequest{}, fmt.Errorf("decode request: %w", err)
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return notifyRequest{}, err
+		}
+		return notifyRequest{}, errors.New("decode request: unexpected data after object")
+	}
+
+	return req, nil
+}
+
+// recipient builds the user or admin the request describes.
+func (req notifyRequest) recipient(opts []UserOption) (Notifier, error) {
+	if req.Level == "" {
+		return NewUser(req.Name, req.Email, opts...)
+	}
+
+	adminOpts := []AdminOption{WithLevel(req.Level)}
+	for _, opt := range opts {
+		adminOpts = append(adminOpts, opt)
+	}
+
+	return NewAdmin(req.Name, req.Email, adminOpts...)
+}
+
+// writeError responds with status and a JSON body describing err.
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
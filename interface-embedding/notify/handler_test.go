@@ -0,0 +1,137 @@
+package notify_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+func TestNotifyHandler(t *testing.T) {
+	quietServer := func(w io.Writer) *httptest.Server {
+		return httptest.NewServer(notify.NotifyHandler(nil,
+			notify.WithWriter(w), notify.WithLogger(slog.New(slog.DiscardHandler))))
+	}
+
+	tests := []struct {
+		name        string
+		out         io.Writer
+		contentType string
+		body        string
+		want        int
+		wantErr     string
+	}{
+		{"user", nil, "application/json", `{"name":"Janet Jones","email":"janet@email.com"}`, http.StatusAccepted, ""},
+		{"admin", nil, "application/json; charset=utf-8", `{"name":"Lisa Smith","email":"lisa@email.com","level":"root"}`, http.StatusAccepted, ""},
+		{"invalid email", nil, "application/json", `{"name":"Janet Jones","email":"janet"}`, http.StatusBadRequest, "email"},
+		{"invalid level", nil, "application/json", `{"name":"Lisa Smith","email":"lisa@email.com","level":"god"}`, http.StatusBadRequest, "level"},
+		{"malformed JSON", nil, "application/json", `{"name":`, http.StatusBadRequest, "decode request"},
+		{"unknown field", nil, "application/json", `{"name":"Janet Jones","email":"janet@email.com","phone":"1"}`, http.StatusBadRequest, "unknown field"},
+		{"trailing data", nil, "application/json", `{"name":"Janet Jones","email":"janet@email.com"} {}`, http.StatusBadRequest, "after object"},
+		{"oversized", nil, "application/json", `{"name":"` + strings.Repeat("x", 64<<10) + `","email":"janet@email.com"}`, http.StatusRequestEntityTooLarge, "too large"},
+		{"wrong content type", nil, "text/plain", `{"name":"Janet Jones","email":"janet@email.com"}`, http.StatusUnsupportedMediaType, "application/json"},
+		{"notify fails", failWriter{errors.New("disk full")}, "application/json", `{"name":"Janet Jones","email":"janet@email.com"}`, http.StatusInternalServerError, "disk full"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := tt.out
+			if out == nil {
+				out = new(bytes.Buffer)
+			}
+			srv := quietServer(out)
+			defer srv.Close()
+
+			resp, err := http.Post(srv.URL+"/notify", tt.contentType, strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.want {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.want)
+			}
+			if tt.wantErr == "" {
+				return
+			}
+			if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+				t.Errorf("Content-Type = %q, want application/json", ct)
+			}
+			var body struct{ Error string }
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatalf("decode error body: %v", err)
+			}
+			if !strings.Contains(body.Error, tt.wantErr) {
+				t.Errorf("error = %q, want it to mention %q", body.Error, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNotifyHandlerRegistry(t *testing.T) {
+	var buf bytes.Buffer
+	var reg notify.Registry[notify.Notifier]
+	registered := &notify.MockNotifier{}
+	reg.Register("bob@email.com", registered)
+	reg.Register("carol@email.com", &notify.MockNotifier{Err: errors.New("server down")})
+	srv := httptest.NewServer(notify.NotifyHandler(&reg, quiet(&buf)...))
+	defer srv.Close()
+
+	post := func(body string) int {
+		t.Helper()
+		resp, err := http.Post(srv.URL+"/notify", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if status := post(`{"name":"Janet Jones","email":"janet@email.com"}`); status != http.StatusAccepted {
+		t.Errorf("status = %d, want %d", status, http.StatusAccepted)
+	}
+	if _, ok := reg.Get("janet@email.com"); ok {
+		t.Error("request registered its recipient, want the registry left alone")
+	}
+	if got, want := buf.String(), "Sending user email to Janet Jones<janet@email.com>\n"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+
+	buf.Reset()
+	if status := post(`{"name":"Bob","email":"bob@email.com"}`); status != http.StatusAccepted {
+		t.Errorf("status = %d, want %d", status, http.StatusAccepted)
+	}
+	if calls := registered.Calls(); calls != 1 || buf.Len() != 0 {
+		t.Errorf("registered notifier called %d times with output %q, want it sent instead", calls, buf.String())
+	}
+
+	for i := range 10 {
+		if status := post(fmt.Sprintf(`{"name":"User","email":"user%d@email.com"}`, i)); status != http.StatusAccepted {
+			t.Errorf("status = %d, want %d", status, http.StatusAccepted)
+		}
+	}
+	if status := post(`{"name":"Carol","email":"carol@email.com"}`); status != http.StatusInternalServerError {
+		t.Errorf("failed send status = %d, want %d", status, http.StatusInternalServerError)
+	}
+	for i := range 10 {
+		if _, ok := reg.Get(fmt.Sprintf("user%d@email.com", i)); ok {
+			t.Fatalf("user%d@email.com was registered, want the registry left alone", i)
+		}
+	}
+
+	resp, err := http.Get(srv.URL + "/notify")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("GET status = %d, want %d", resp.StatusCode, http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,84 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrHookPanicked is returned by SendWithHooks, joined with the send's own
+// error, when a hook panics.
+var ErrHookPanicked = errors.New("notify: hook panicked")
+
+// Hooks holds callbacks that observe sends made with SendWithHooks,
+// without wrapping the notifier in middleware. The zero value has no
+// hooks and is safe for concurrent use.
+type Hooks struct {
+	mu     sync.RWMutex
+	before []func(ctx context.Context, n Notifier)
+	after  []func(ctx context.Context, n Notifier, err error, elapsed time.Duration)
+}
+
+// BeforeNotify adds fn to the hooks called before each send.
+func (h *Hooks) BeforeNotify(fn func(ctx context.Context, n Notifier)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.before = append(h.before, fn)
+}
+
+// AfterNotify adds fn to the hooks called after each send, with the send's
+// error and how long it took.
+func (h *Hooks) AfterNotify(fn func(ctx context.Context, n Notifier, err error, elapsed time.Duration)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.after = append(h.after, fn)
+}
+
+// SendWithHooks is SendNotification with h's hooks run around the send,
+// each list in the order it was added. The after hooks run whether or not
+// the send fails. A hook that panics doesn't stop the send or the other
+// hooks; the panic is returned as an ErrHookPanicked joined with the
+// send's own error.
+func SendWithHooks(ctx context.Context, n Notifier, h *Hooks) error {
+	h.mu.RLock()
+	before, after := h.before, h.after
+	h.mu.RUnlock()
+
+	var hookErrs []error
+	for i, fn := range before {
+		if herr := runHook("before", i, func() { fn(ctx, n) }); herr != nil {
+			hookErrs = append(hookErrs, herr)
+		}
+	}
+
+	start := time.Now()
+	err := SendNotification(ctx, n)
+	elapsed := time.Since(start)
+
+	for i, fn := range after {
+		if herr := runHook("after", i, func() { fn(ctx, n, err, elapsed) }); herr != nil {
+			hookErrs = append(hookErrs, herr)
+		}
+	}
+	if len(hookErrs) == 0 {
+		return err
+	}
+
+	return errors.Join(append([]error{err}, hookErrs...)...)
+}
+
+// runHook calls fn, turning a panic into an error naming the hook.
+func runHook(kind string, i int, fn func()) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = fmt.Errorf("%w: %s hook %d: %v", ErrHookPanicked, kind, i, v)
+		}
+	}()
+	fn()
+
+	return nil
+}
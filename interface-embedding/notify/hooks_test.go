@@ -0,0 +1,135 @@
+package notify_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+func TestSendWithHooks(t *testing.T) {
+	errDown := errors.New("server down")
+
+	tests := []struct {
+		name       string
+		sendErr    error
+		panicking  string
+		wantCalls  []string
+		wantErrs   []error
+		wantErrMsg string
+	}{
+		{"order", nil, "", []string{"before 1", "before 2", "send", "after 1", "after 2"}, nil, ""},
+		{"after runs on failure", errDown, "", []string{"before 1", "before 2", "send", "after 1", "after 2"}, []error{errDown}, ""},
+		{"before panics", nil, "before 1", []string{"before 2", "send", "after 1", "after 2"},
+			[]error{notify.ErrHookPanicked}, "before hook 0: before 1"},
+		{"after panics", errDown, "after 1", []string{"before 1", "before 2", "send", "after 2"},
+			[]error{notify.ErrHookPanicked, errDown}, "after hook 0: after 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			record := func(name string) {
+				if name == tt.panicking {
+					panic(name)
+				}
+				calls = append(calls, name)
+			}
+
+			var h notify.Hooks
+			var sawErr error
+			h.BeforeNotify(func(context.Context, notify.Notifier) { record("before 1") })
+			h.BeforeNotify(func(context.Context, notify.Notifier) { record("before 2") })
+			h.AfterNotify(func(_ context.Context, _ notify.Notifier, err error, _ time.Duration) { record("after 1") })
+			h.AfterNotify(func(_ context.Context, _ notify.Notifier, err error, _ time.Duration) {
+				sawErr = err
+				record("after 2")
+			})
+			n := notify.NotifierFunc(func(context.Context) error {
+				calls = append(calls, "send")
+				return tt.sendErr
+			})
+
+			err := notify.SendWithHooks(context.Background(), n, &h)
+			if !slices.Equal(calls, tt.wantCalls) {
+				t.Errorf("calls = %v, want %v", calls, tt.wantCalls)
+			}
+			if !errors.Is(sawErr, tt.sendErr) || (tt.sendErr == nil) != (sawErr == nil) {
+				t.Errorf("AfterNotify saw %v, want %v", sawErr, tt.sendErr)
+			}
+			if (err != nil) != (tt.wantErrs != nil) {
+				t.Fatalf("SendWithHooks() = %v, want errors %v", err, tt.wantErrs)
+			}
+			for _, want := range tt.wantErrs {
+				if !errors.Is(err, want) {
+					t.Errorf("SendWithHooks() = %v, want it to match %v", err, want)
+				}
+			}
+			if err != nil && !strings.Contains(err.Error(), tt.wantErrMsg) {
+				t.Errorf("SendWithHooks() = %v, want it to mention %q", err, tt.wantErrMsg)
+			}
+		})
+	}
+}
+
+func TestSendWithHooksElapsed(t *testing.T) {
+	const delay = 20 * time.Millisecond
+	var h notify.Hooks
+	var elapsed time.Duration
+	h.AfterNotify(func(_ context.Context, _ notify.Notifier, _ error, d time.Duration) { elapsed = d })
+
+	slow := notify.NotifierFunc(func(context.Context) error {
+		time.Sleep(delay)
+		return nil
+	})
+	if err := notify.SendWithHooks(context.Background(), slow, &h); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed < delay || elapsed > delay+time.Second {
+		t.Errorf("AfterNotify elapsed = %v, want about %v", elapsed, delay)
+	}
+}
+
+func TestSendWithHooksNoHooks(t *testing.T) {
+	errDown := errors.New("server down")
+	err := notify.SendWithHooks(context.Background(), &notify.MockNotifier{Err: errDown}, &notify.Hooks{})
+	if err != errDown {
+		t.Errorf("SendWithHooks() = %v, want the send's error unchanged", err)
+	}
+}
+
+func TestHooksConcurrent(t *testing.T) {
+	var h notify.Hooks
+	var mu sync.Mutex
+	count := 0
+	h.AfterNotify(func(context.Context, notify.Notifier, error, time.Duration) {
+		mu.Lock()
+		count++
+		mu.Unlock()
+	})
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for range 25 {
+				notify.SendWithHooks(context.Background(), &notify.MockNotifier{}, &h)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for range 25 {
+				h.BeforeNotify(func(context.Context, notify.Notifier) {})
+			}
+		}()
+	}
+	wg.Wait()
+	if count != 100 {
+		t.Errorf("AfterNotify ran %d times, want 100", count)
+	}
+}
@@ -0,0 +1,138 @@
+package notify
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// KeyedStore records the results of completed sends by idempotency key.
+// MemoryStore is the in-memory implementation; others can keep results in
+// a shared cache so that several processes agree on what was sent.
+type KeyedStore interface {
+	// Load returns the result recorded under key, if it hasn't expired.
+	Load(key string) (Result, bool)
+
+	// Store records r under key.
+	Store(key string, r Result)
+}
+
+// MemoryStore is a KeyedStore that keeps results in memory for a fixed
+// TTL. It is safe for concurrent use.
+type MemoryStore struct {
+	ttl   time.Duration
+	clock Clock
+
+	mu      sync.Mutex
+	results map[string]storedResult
+}
+
+// storedResult is a Result and when it expires.
+type storedResult struct {
+	result  Result
+	expires time.Time
+}
+
+// NewMemoryStore returns an empty store that keeps each result for ttl,
+// measured with clock, or with SystemClock when clock is nil.
+func NewMemoryStore(ttl time.Duration, clock Clock) *MemoryStore {
+	if clock == nil {
+		clock = SystemClock
+	}
+
+	return &MemoryStore{ttl: ttl, clock: clock, results: make(map[string]storedResult)}
+}
+
+// Load returns the result stored under key unless its TTL has passed.
+func (m *MemoryStore) Load(key string) (Result, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, ok := m.results[key]
+	if !ok || !m.clock.Now().Before(stored.expires) {
+		return Result{}, false
+	}
+
+	return stored.result, true
+}
+
+// Store records r under key for the store's TTL, and forgets any results
+// that have expired.
+func (m *MemoryStore) Store(key string, r Result) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.clock.Now()
+	for k, stored := range m.results {
+		if !now.Before(stored.expires) {
+			delete(m.results, k)
+		}
+	}
+	m.results[key] = storedResult{result: r, expires: now.Add(m.ttl)}
+}
+
+// IdempotentSender sends each notification at most once per idempotency
+// key, so a retried request or a replayed message doesn't notify anyone
+// twice. It is safe for concurrent use.
+type IdempotentSender struct {
+	store KeyedStore
+
+	mu       sync.Mutex
+	inflight map[string]*idempotentCall
+}
+
+// idempotentCall is a send in progress that callers with the same key
+// wait for.
+type idempotentCall struct {
+	done   chan struct{}
+	result Result
+}
+
+// NewIdempotentSender returns a sender that records completed sends in
+// store.
+func NewIdempotentSender(store KeyedStore) *IdempotentSender {
+	return &IdempotentSender{store: store, inflight: make(map[string]*idempotentCall)}
+}
+
+// Send delivers through n as Send does, unless key has already been sent.
+// A key with a successful result in the store returns that result without
+// notifying again. Concurrent calls with the same key share one send: the
+// first makes it and the rest wait for its result, or until their own ctx
+// is done. Failures aren't stored, so a later call with the key tries
+// again.
+func (s *IdempotentSender) Send(ctx context.Context, key string, n Notifier) (Result, error) {
+	if r, ok := s.store.Load(key); ok {
+		return r, r.Err
+	}
+
+	s.mu.Lock()
+	if call, ok := s.inflight[key]; ok {
+		s.mu.Unlock()
+		select {
+		case <-call.done:
+			return call.result, call.result.Err
+		case <-ctx.Done():
+			return unstarted(n, ctx.Err()), ctx.Err()
+		}
+	}
+	// The send may have finished between the Load and taking the lock.
+	if r, ok := s.store.Load(key); ok {
+		s.mu.Unlock()
+		return r, r.Err
+	}
+	call := &idempotentCall{done: make(chan struct{})}
+	s.inflight[key] = call
+	s.mu.Unlock()
+
+	call.result, _ = Send(ctx, n)
+	if call.result.Err == nil {
+		s.store.Store(key, call.result)
+	}
+
+	s.mu.Lock()
+	delete(s.inflight, key)
+	s.mu.Unlock()
+	close(call.done)
+
+	return call.result, call.result.Err
+}
@@ -0,0 +1,183 @@
+package notify_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+func TestIdempotentSenderConcurrent(t *testing.T) {
+	g := newGated()
+	janet := &addressed{Notifier: g, to: &notify.User{Email: "janet@email.com"}}
+	s := notify.NewIdempotentSender(notify.NewMemoryStore(time.Hour, nil))
+
+	var wg sync.WaitGroup
+	results := make([]notify.Result, 50)
+	errs := make([]error, 50)
+	for i := range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = s.Send(context.Background(), "order-1", janet)
+		}()
+	}
+	release := <-g.calls
+	// Give the other callers time to find the send in flight.
+	time.Sleep(10 * time.Millisecond)
+	release <- nil
+
+	// Release any further sends rather than hang, counting them.
+	var extra int
+	stop := make(chan struct{})
+	drained := make(chan struct{})
+	go func() {
+		defer close(drained)
+		for {
+			select {
+			case release := <-g.calls:
+				extra++
+				release <- nil
+			case <-stop:
+				return
+			}
+		}
+	}()
+	wg.Wait()
+	close(stop)
+	<-drained
+	if extra != 0 {
+		t.Fatalf("the same key was sent %d more times", extra)
+	}
+	for i := range 50 {
+		if errs[i] != nil || results[i].Recipient != "janet@email.com" || results[i].StartedAt != results[0].StartedAt {
+			t.Errorf("caller %d got %+v, %v, want the one shared result", i, results[i], errs[i])
+		}
+	}
+}
+
+func TestIdempotentSender(t *testing.T) {
+	errDown := errors.New("server down")
+
+	type send struct {
+		advance   time.Duration
+		key       string
+		err       error
+		wantCalls int
+		wantErr   error
+	}
+	tests := []struct {
+		name  string
+		sends []send
+	}{
+		{"repeat within the TTL", []send{{0, "a", nil, 1, nil}, {time.Hour - time.Second, "a", nil, 1, nil}}},
+		{"repeat after the TTL", []send{{0, "a", nil, 1, nil}, {time.Hour, "a", nil, 2, nil}}},
+		{"distinct keys", []send{{0, "a", nil, 1, nil}, {0, "b", nil, 2, nil}, {0, "a", nil, 2, nil}}},
+		{"failures retried", []send{{0, "a", errDown, 1, errDown}, {0, "a", nil, 2, nil}, {0, "a", nil, 2, nil}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := newFakeClock()
+			s := notify.NewIdempotentSender(notify.NewMemoryStore(time.Hour, clock))
+			m := &notify.MockNotifier{}
+
+			for i, snd := range tt.sends {
+				clock.Advance(snd.advance)
+				m.Err = snd.err
+				_, err := s.Send(context.Background(), snd.key, m)
+				if !errors.Is(err, snd.wantErr) || (snd.wantErr == nil) != (err == nil) {
+					t.Errorf("send %d: Send() = %v, want %v", i, err, snd.wantErr)
+				}
+				if got := m.Calls(); got != snd.wantCalls {
+					t.Errorf("send %d: notified %d times, want %d", i, got, snd.wantCalls)
+				}
+			}
+		})
+	}
+}
+
+func TestIdempotentSenderWaiterCancelled(t *testing.T) {
+	g := newGated()
+	s := notify.NewIdempotentSender(notify.NewMemoryStore(time.Hour, nil))
+	first := make(chan error, 1)
+	go func() {
+		_, err := s.Send(context.Background(), "order-1", g)
+		first <- err
+	}()
+	release := <-g.calls
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := s.Send(ctx, "order-1", g); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Send() = %v, want the waiter's own deadline", err)
+	}
+
+	release <- nil
+	if err := <-first; err != nil {
+		t.Errorf("first Send() = %v, want nil", err)
+	}
+}
+
+// mapStore is a KeyedStore without expiry, standing in for a shared cache.
+type mapStore struct {
+	mu      sync.Mutex
+	results map[string]notify.Result
+}
+
+func (s *mapStore) Load(key string) (notify.Result, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, ok := s.results[key]
+	return r, ok
+}
+
+func (s *mapStore) Store(key string, r notify.Result) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.results[key] = r
+}
+
+func TestIdempotentSenderCustomStore(t *testing.T) {
+	store := &mapStore{results: map[string]notify.Result{"order-1": {Recipient: "janet@email.com", Attempt: 1}}}
+	s := notify.NewIdempotentSender(store)
+	m := &notify.MockNotifier{}
+
+	r, err := s.Send(context.Background(), "order-1", m)
+	if err != nil || r.Recipient != "janet@email.com" || m.Calls() != 0 {
+		t.Errorf("Send() = %+v, %v after %d calls, want the stored result without notifying", r, err, m.Calls())
+	}
+	if _, err := s.Send(context.Background(), "order-2", m); err != nil || m.Calls() != 1 {
+		t.Fatalf("Send() = %v after %d calls, want a new key notified", err, m.Calls())
+	}
+	if _, ok := store.Load("order-2"); !ok {
+		t.Error("the new key's result wasn't stored")
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	clock := newFakeClock()
+	m := notify.NewMemoryStore(time.Minute, clock)
+	if _, ok := m.Load("a"); ok {
+		t.Error("Load() found a key in an empty store")
+	}
+
+	m.Store("a", notify.Result{Recipient: "janet@email.com"})
+	clock.Advance(30 * time.Second)
+	m.Store("b", notify.Result{Recipient: "bob@email.com"})
+	if r, ok := m.Load("a"); !ok || r.Recipient != "janet@email.com" {
+		t.Errorf("Load(a) = %+v, %v, want Janet's result", r, ok)
+	}
+
+	clock.Advance(30 * time.Second)
+	if _, ok := m.Load("a"); ok {
+		t.Error("Load(a) found a result after its TTL")
+	}
+	if _, ok := m.Load("b"); !ok {
+		t.Error("Load(b) = false, want it kept until its own TTL")
+	}
+}
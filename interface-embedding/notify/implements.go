@@ -0,0 +1,145 @@
+package notify
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Every notifier in the package is checked against Notifier at compile
+// time, and the other implementations against their interfaces, so a
+// changed signature or receiver fails the build here rather than at some
+// distant call site.
+var (
+	_ Notifier = (*Admin)(nil)
+	_ Notifier = (*AuditNotifier)(nil)
+	_ Notifier = (*CircuitBreakerNotifier)(nil)
+	_ Notifier = (*ConditionalNotifier)(nil)
+	_ Notifier = (*ConsoleNotifier)(nil)
+	_ Notifier = (*DebounceNotifier)(nil)
+	_ Notifier = (*DedupNotifier)(nil)
+	_ Notifier = (*Delegate)(nil)
+	_ Notifier = (*DigestNotifier)(nil)
+	_ Notifier = (*EscalationChain)(nil)
+	_ Notifier = (*FallbackNotifier)(nil)
+	_ Notifier = (*LimitingNotifier)(nil)
+	_ Notifier = (*LoggingNotifier)(nil)
+	_ Notifier = (*MetricsNotifier)(nil)
+	_ Notifier = (*MockNotifier)(nil)
+	_ Notifier = MultiNotifier(nil)
+	_ Notifier = NotifierFunc(nil)
+	_ Notifier = (*OptOutNotifier)(nil)
+	_ Notifier = (*PolicyNotifier)(nil)
+	_ Notifier = (*PreferringNotifier)(nil)
+	_ Notifier = (*RateLimitedNotifier)(nil)
+	_ Notifier = (*RetryNotifier)(nil)
+	_ Notifier = (*RoundRobinNotifier)(nil)
+	_ Notifier = (*SMSUser)(nil)
+	_ Notifier = (*SMTPNotifier)(nil)
+	_ Notifier = (*SlackNotifier)(nil)
+	_ Notifier = (*SlackUser)(nil)
+	_ Notifier = (*TemplatedNotifier)(nil)
+	_ Notifier = (*TimeoutNotifier)(nil)
+	_ Notifier = (*User)(nil)
+	_ Notifier = (*WebhookNotifier)(nil)
+	_ Notifier = (*WeightedNotifier)(nil)
+	_ Notifier = (*recoveryNotifier)(nil)
+	_ Notifier = (*timingNotifier)(nil)
+
+	_ Lifecycle = (*Queue)(nil)
+	_ Lifecycle = (*Scheduler)(nil)
+
+	_ DocumentReader = Document{}
+	_ DocumentReader = SignedDocument{}
+	_ DocumentWriter = (*Document)(nil)
+	_ DocumentWriter = (*SignedDocument)(nil)
+)
+
+var notifierType = reflect.TypeFor[Notifier]()
+
+// ImplementsNotifier reports whether v satisfies Notifier and, when it
+// doesn't, explains why. The explanation points out the classic embedding
+// mistake of passing a User or Admin by value: Notify has a pointer
+// receiver, so it is only in the method set of the pointer type.
+func ImplementsNotifier(v any) (bool, string) {
+	if v == nil {
+		return false, "nil does not implement Notifier"
+	}
+
+	t := reflect.TypeOf(v)
+	if t.Implements(notifierType) {
+		return true, fmt.Sprintf("%s implements Notifier", t)
+	}
+	if t.Kind() != reflect.Pointer && reflect.PointerTo(t).Implements(notifierType) {
+		return false, fmt.Sprintf("%s does not implement Notifier: method Notify has a pointer receiver, pass *%s instead", t, t)
+	}
+
+	want, _ := notifierType.MethodByName("Notify")
+	if m, ok := t.MethodByName("Notify"); ok {
+		// A method's type from a concrete type includes the receiver,
+		// which the interface's doesn't.
+		return false, fmt.Sprintf("%s does not implement Notifier: method Notify has signature %s, want %s", t, signature(m.Type, 1), signature(want.Type, 0))
+	}
+
+	return false, fmt.Sprintf("%s does not implement Notifier: missing method Notify", t)
+}
+
+// methodSetInterfaces are the interfaces DescribeMethodSet checks for, in
+// the order it lists them.
+var methodSetInterfaces = []reflect.Type{
+	reflect.TypeFor[Addresser](),
+	reflect.TypeFor[DocumentReader](),
+	reflect.TypeFor[DocumentWriter](),
+	reflect.TypeFor[Identifier](),
+	reflect.TypeFor[Labeler](),
+	reflect.TypeFor[Locator](),
+	reflect.TypeFor[Notifier](),
+	reflect.TypeFor[Validatable](),
+}
+
+// DescribeMethodSet lists which of the package's interfaces v's type and
+// the pointer to it satisfy, one line each, as in
+// "notify.Document: DocumentReader". Passing a pointer describes the type
+// it points to the same way. Comparing the two lines shows which methods
+// have pointer receivers.
+func DescribeMethodSet(v any) []string {
+	if v == nil {
+		return nil
+	}
+
+	t := reflect.TypeOf(v)
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	var lines []string
+	for _, typ := range []reflect.Type{t, reflect.PointerTo(t)} {
+		var names []string
+		for _, iface := range methodSetInterfaces {
+			if typ.Implements(iface) {
+				names = append(names, iface.Name())
+			}
+		}
+		if len(names) == 0 {
+			names = []string{"none"}
+		}
+		lines = append(lines, typ.String()+": "+strings.Join(names, ", "))
+	}
+
+	return lines
+}
+
+// signature formats fn as "func(...) ...", skipping its first skip
+// parameters.
+func signature(fn reflect.Type, skip int) string {
+	in := make([]reflect.Type, 0, fn.NumIn())
+	for i := skip; i < fn.NumIn(); i++ {
+		in = append(in, fn.In(i))
+	}
+	out := make([]reflect.Type, fn.NumOut())
+	for i := range out {
+		out[i] = fn.Out(i)
+	}
+
+	return reflect.FuncOf(in, out, fn.IsVariadic()).String()
+}
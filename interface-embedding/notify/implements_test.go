@@ -0,0 +1,73 @@
+package notify_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+// wrongSignature has a Notify method that doesn't take a context.
+type wrongSignature struct{}
+
+func (wrongSignature) Notify() error { return nil }
+
+func TestImplementsNotifier(t *testing.T) {
+	tests := []struct {
+		name string
+		v    any
+		ok   bool
+		want string
+	}{
+		{"admin pointer", &notify.Admin{}, true, "*notify.Admin implements Notifier"},
+		{"admin value", notify.Admin{}, false,
+			"notify.Admin does not implement Notifier: method Notify has a pointer receiver, pass *notify.Admin instead"},
+		{"user value", notify.User{}, false,
+			"notify.User does not implement Notifier: method Notify has a pointer receiver, pass *notify.User instead"},
+		{"embedding value", notify.SMSUser{}, false,
+			"notify.SMSUser does not implement Notifier: method Notify has a pointer receiver, pass *notify.SMSUser instead"},
+		{"value receiver", notify.NotifierFunc(func(context.Context) error { return nil }), true, "notify.NotifierFunc implements Notifier"},
+		{"wrong signature", wrongSignature{}, false,
+			"notify_test.wrongSignature does not implement Notifier: method Notify has signature func() error, want func(context.Context) error"},
+		{"unrelated", 42, false, "int does not implement Notifier: missing method Notify"},
+		{"unrelated pointer", new(string), false, "*string does not implement Notifier: missing method Notify"},
+		{"nil", nil, false, "nil does not implement Notifier"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, why := notify.ImplementsNotifier(tt.v)
+			if ok != tt.ok || why != tt.want {
+				t.Errorf("ImplementsNotifier() = %v, %q, want %v, %q", ok, why, tt.ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestDescribeMethodSet(t *testing.T) {
+	notifier := "Addresser, Identifier, Labeler, Locator, Notifier, Validatable"
+
+	tests := []struct {
+		name string
+		v    any
+		want []string
+	}{
+		{"user", notify.User{}, []string{"notify.User: none", "*notify.User: " + notifier}},
+		{"user pointer", &notify.User{}, []string{"notify.User: none", "*notify.User: " + notifier}},
+		{"admin", notify.Admin{}, []string{"notify.Admin: none", "*notify.Admin: " + notifier}},
+		{"admin pointer", &notify.Admin{}, []string{"notify.Admin: none", "*notify.Admin: " + notifier}},
+		{"document", notify.Document{}, []string{
+			"notify.Document: DocumentReader", "*notify.Document: DocumentReader, DocumentWriter"}},
+		{"signed document", notify.SignedDocument{}, []string{
+			"notify.SignedDocument: DocumentReader", "*notify.SignedDocument: DocumentReader, DocumentWriter"}},
+		{"unrelated", 42, []string{"int: none", "*int: none"}},
+		{"nil", nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := notify.DescribeMethodSet(tt.v); !slices.Equal(got, tt.want) {
+				t.Errorf("DescribeMethodSet() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
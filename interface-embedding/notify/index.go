@@ -0,0 +1,133 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+)
+
+// ErrNoRecipientID is returned when a notifier added to an Index doesn't
+// identify its recipient.
+var ErrNoRecipientID = errors.New("notify: notifier has no recipient ID")
+
+// Identifier is implemented by notifiers that can say which person they
+// reach. User implements it, and types embedding User inherit it.
+type Identifier interface {
+	RecipientID() string
+}
+
+// Index groups notifiers by the recipient they reach, so a person
+// registered on several channels is notified only once. The zero value is
+// ready to use and safe for concurrent use.
+type Index struct {
+	mu    sync.RWMutex
+	byID  map[string][]Notifier
+	order []string // IDs in the order they were first added
+}
+
+// Add files n under its recipient ID, found through any wrappers.
+func (x *Index) Add(n Notifier) error {
+	id, ok := recipientID(n)
+	if !ok {
+		return ErrNoRecipientID
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if x.byID == nil {
+		x.byID = make(map[string][]Notifier)
+	}
+	if _, ok := x.byID[id]; !ok {
+		x.order = append(x.order, id)
+	}
+	x.byID[id] = append(x.byID[id], n)
+
+	return nil
+}
+
+// Remove drops every notifier filed under id and reports whether there
+// were any.
+func (x *Index) Remove(id string) bool {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if _, ok := x.byID[id]; !ok {
+		return false
+	}
+	delete(x.byID, id)
+	x.order = slices.DeleteFunc(x.order, func(o string) bool { return o == id })
+
+	return true
+}
+
+// ByID returns the notifiers filed under id, in the order they were added.
+func (x *Index) ByID(id string) []Notifier {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	return slices.Clone(x.byID[id])
+}
+
+// NotifyAll notifies each recipient once, in the order they were first
+// added, and returns any failures as a *BatchError. When a recipient is
+// filed under several notifiers, the first admin among them is used, or
+// else the first added. The index isn't locked while notifying, so
+// notifiers may be added or removed meanwhile; those changes apply from
+// the next call.
+func (x *Index) NotifyAll(ctx context.Context) error {
+	x.mu.RLock()
+	ns := make([]Notifier, 0, len(x.order))
+	for _, id := range x.order {
+		ns = append(ns, preferred(x.byID[id]))
+	}
+	x.mu.RUnlock()
+
+	return SendNotifications(ctx, ns...)
+}
+
+// preferred picks the notifier to reach a recipient through: the first
+// admin, or else the first one.
+func preferred(ns []Notifier) Notifier {
+	for _, n := range ns {
+		if isAdmin(n) {
+			return n
+		}
+	}
+
+	return ns[0]
+}
+
+// isAdmin reports whether n is, or wraps, an *Admin.
+func isAdmin(n Notifier) bool {
+	for n != nil {
+		if _, ok := n.(*Admin); ok {
+			return true
+		}
+		w, ok := n.(Wrapper)
+		if !ok {
+			break
+		}
+		n = w.Unwrap()
+	}
+
+	return false
+}
+
+// recipientID returns the recipient ID of n or the first notifier it wraps
+// that implements Identifier.
+func recipientID(n Notifier) (string, bool) {
+	for n != nil {
+		if i, ok := n.(Identifier); ok {
+			return i.RecipientID(), true
+		}
+		w, ok := n.(Wrapper)
+		if !ok {
+			break
+		}
+		n = w.Unwrap()
+	}
+
+	return "", false
+}
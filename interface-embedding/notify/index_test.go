@@ -0,0 +1,178 @@
+package notify_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+func TestRecipientID(t *testing.T) {
+	tests := []struct {
+		name string
+		n    notify.Identifier
+		want string
+	}{
+		{"user", &notify.User{Email: "janet@email.com"}, "janet@email.com"},
+		{"normalized", &notify.User{Email: "  janet@EMAIL.com "}, "janet@email.com"},
+		{"admin shares its user's ID", &notify.Admin{User: notify.User{Email: "janet@Email.com"}, Level: notify.LevelRoot}, "janet@email.com"},
+		{"sms user shares its user's ID", &notify.SMSUser{User: notify.User{Email: "janet@email.com"}, Phone: "+15555550100"}, "janet@email.com"},
+		{"invalid email as given", &notify.User{Email: "janet"}, "janet"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.n.RecipientID(); got != tt.want {
+				t.Errorf("RecipientID() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIndexNotifyAllPrefersAdmin(t *testing.T) {
+	tests := []struct {
+		name    string
+		add     func(buf *bytes.Buffer) []notify.Notifier
+		wantOut string
+	}{
+		{"admin added after user", func(buf *bytes.Buffer) []notify.Notifier {
+			u, _ := notify.NewUser("Janet Jones", "janet@email.com", quiet(buf)...)
+			a, _ := notify.NewAdmin("Janet Jones", "janet@EMAIL.com", quietAdmin(buf, notify.WithLevel(notify.LevelRoot))...)
+			return []notify.Notifier{u, a}
+		}, "Sending admin email to Janet Jones<janet@email.com> with level root\n"},
+		{"wrapped admin", func(buf *bytes.Buffer) []notify.Notifier {
+			u, _ := notify.NewUser("Janet Jones", "janet@email.com", quiet(buf)...)
+			a, _ := notify.NewAdmin("Janet Jones", "janet@email.com", quietAdmin(buf)...)
+			return []notify.Notifier{u, &notify.RetryNotifier{Notifier: a}}
+		}, "Sending admin email to Janet Jones<janet@email.com> with level normal\n"},
+		{"users only, first wins", func(buf *bytes.Buffer) []notify.Notifier {
+			u, _ := notify.NewUser("Janet Jones", "janet@email.com", quiet(buf)...)
+			sms := &notify.SMSUser{User: *u, Phone: "+15555550100"}
+			return []notify.Notifier{sms, u}
+		}, "Sending user SMS to Janet Jones<+15555550100>\n"},
+		{"distinct recipients in order added", func(buf *bytes.Buffer) []notify.Notifier {
+			bob, _ := notify.NewUser("Bob Brown", "bob@email.com", quiet(buf)...)
+			janet, _ := notify.NewUser("Janet Jones", "janet@email.com", quiet(buf)...)
+			return []notify.Notifier{bob, janet, bob}
+		}, "Sending user email to Bob Brown<bob@email.com>\nSending user email to Janet Jones<janet@email.com>\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			var x notify.Index
+			for _, n := range tt.add(&buf) {
+				if err := x.Add(n); err != nil {
+					t.Fatalf("Add() = %v", err)
+				}
+			}
+			if err := x.NotifyAll(context.Background()); err != nil {
+				t.Fatalf("NotifyAll() = %v", err)
+			}
+			if got := buf.String(); got != tt.wantOut {
+				t.Errorf("output = %q, want %q", got, tt.wantOut)
+			}
+		})
+	}
+}
+
+func TestIndexAddRemove(t *testing.T) {
+	var x notify.Index
+	if err := x.Add(&notify.MockNotifier{}); !errors.Is(err, notify.ErrNoRecipientID) {
+		t.Errorf("Add() of a notifier without a recipient = %v, want %v", err, notify.ErrNoRecipientID)
+	}
+
+	janet := &notify.User{Name: "Janet Jones", Email: "janet@email.com"}
+	wrapped := &notify.RetryNotifier{Notifier: janet}
+	x.Add(janet)
+	x.Add(wrapped)
+	if got := x.ByID("janet@email.com"); len(got) != 2 || got[0] != janet || got[1] != wrapped {
+		t.Errorf("ByID() = %v, want both notifiers in the order added", got)
+	}
+	x.ByID("janet@email.com")[0] = nil
+	if x.ByID("janet@email.com")[0] != janet {
+		t.Error("changing ByID's result changed the index")
+	}
+
+	if !x.Remove("janet@email.com") {
+		t.Error("Remove() = false, want true")
+	}
+	if x.Remove("janet@email.com") {
+		t.Error("second Remove() = true, want false")
+	}
+	if got := x.ByID("janet@email.com"); len(got) != 0 {
+		t.Errorf("ByID() after Remove = %v, want none", got)
+	}
+	if err := x.NotifyAll(context.Background()); err != nil {
+		t.Errorf("NotifyAll() of an empty index = %v", err)
+	}
+}
+
+func TestIndexRemoveDuringNotifyAll(t *testing.T) {
+	var x notify.Index
+	bob := &notify.MockNotifier{}
+	var removed []bool
+	// Janet's notifier removes Bob while NotifyAll is running.
+	x.Add(&identified{Notifier: notify.NotifierFunc(func(context.Context) error {
+		removed = append(removed, x.Remove("bob@email.com"))
+		return nil
+	}), id: "janet@email.com"})
+	x.Add(&identified{Notifier: bob, id: "bob@email.com"})
+
+	if err := x.NotifyAll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(removed) != 1 || !removed[0] {
+		t.Fatalf("Remove() during NotifyAll = %v, want true", removed)
+	}
+	if bob.Calls() != 1 {
+		t.Errorf("bob notified %d times by the running NotifyAll, want 1", bob.Calls())
+	}
+	if err := x.NotifyAll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if bob.Calls() != 1 {
+		t.Errorf("bob notified %d times after removal, want still 1", bob.Calls())
+	}
+}
+
+// identified gives a notifier a recipient ID.
+type identified struct {
+	notify.Notifier
+	id string
+}
+
+func (i *identified) RecipientID() string { return i.id }
+
+func TestIndexConcurrent(t *testing.T) {
+	var x notify.Index
+	var wg sync.WaitGroup
+	for w := range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 50 {
+				id := fmt.Sprintf("user%d-%d@email.com", w, i%5)
+				x.Add(&identified{Notifier: &notify.MockNotifier{}, id: id})
+				x.ByID(id)
+				if i%3 == 0 {
+					x.Remove(id)
+				}
+			}
+		}()
+	}
+	for range 2 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 50 {
+				if err := x.NotifyAll(context.Background()); err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,102 @@
+package notify
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// userJSON is the wire form of a User, with the email already masked.
+type userJSON struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// adminJSON is the wire form of an Admin.
+type adminJSON struct {
+	userJSON
+	Level Level    `json:"level"`
+	CC    []string `json:"cc,omitempty"`
+}
+
+// MarshalJSON encodes the user with its email masked, as in
+// {"name":"Janet Jones","email":"j***t@email.com"}, so responses built from
+// a User never leak the full address. It has a value receiver so that
+// users are masked whether they are marshaled as values, pointers or
+// fields of other structs.
+//
+// The encoding is for output only: decoding it yields the masked address,
+// which is still a valid email, so it must not be fed back to
+// LoadAdminsFromJSON or NewUser as though it were the original.
+func (u User) MarshalJSON() ([]byte, error) {
+	return json.Marshal(userJSON{Name: u.Name, Email: MaskEmail(u.Email)})
+}
+
+// MarshalJSON encodes the admin as a flat object with its level, CC list
+// and the masked user fields. Without it the promoted User.MarshalJSON
+// would be used and the level and CC would be dropped. The CC addresses
+// are masked like the admin's own. Like User.MarshalJSON, it has a value
+// receiver and its output doesn't round-trip the emails.
+func (a Admin) MarshalJSON() ([]byte, error) {
+	var cc []string
+	for _, email := range a.CC {
+		cc = append(cc, MaskEmail(email))
+	}
+
+	return json.Marshal(adminJSON{
+		userJSON: userJSON{Name: a.Name, Email: MaskEmail(a.Email)},
+		Level:    a.Level,
+		CC:       cc,
+	})
+}
+
+// MaskEmail hides all but the first and last characters of an address's
+// local part, turning "janet@email.com" into "j***t@email.com". Characters
+// are counted as runes, so non-ASCII local parts aren't split mid-rune.
+// Local parts of two characters keep only the first, and those of one are
+// hidden entirely.
+func MaskEmail(email string) string {
+	local, domain, ok := strings.Cut(email, "@")
+	if !ok {
+		return "***"
+	}
+
+	runes := []rune(local)
+	switch len(runes) {
+	case 0, 1:
+		return "***@" + domain
+	case 2:
+		return string(runes[0]) + "***@" + domain
+	default:
+		return string(runes[0]) + "***" + string(runes[len(runes)-1]) + "@" + domain
+	}
+}
+
+// LoadAdminsFromJSON decodes a JSON array of flat admin objects, such as
+// [{"name":"Janet","email":"janet@email.com","level":"super"}], and
+// validates each one as NewAdmin would. Unknown fields are rejected.
+func LoadAdminsFromJSON(r io.Reader) ([]*Admin, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+
+	var decoded []Admin
+	if err := dec.Decode(&decoded); err != nil {
+		return nil, fmt.Errorf("notify: decode admins: %w", err)
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return nil, errors.New("notify: decode admins: unexpected data after array")
+	}
+
+	admins := make([]*Admin, 0, len(decoded))
+	for i, d := range decoded {
+		a, err := NewAdmin(d.Name, d.Email, WithLevel(d.Level), WithCC(d.CC...))
+		if err != nil {
+			return nil, fmt.Errorf("notify: admin %d: %w", i, err)
+		}
+		admins = append(admins, a)
+	}
+
+	return admins, nil
+}
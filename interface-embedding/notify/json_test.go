@@ -0,0 +1,195 @@
+package notify_test
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+func TestAdminJSONIsFlat(t *testing.T) {
+	in := `{"name":"Janet","email":"janet@email.com","level":"super","cc":["ops@email.com"]}`
+	var a notify.Admin
+	if err := json.Unmarshal([]byte(in), &a); err != nil {
+		t.Fatal(err)
+	}
+	if a.User.Name != "Janet" || a.User.Email != "janet@email.com" || a.Level != notify.LevelSuper || len(a.CC) != 1 {
+		t.Fatalf("Unmarshal() = %+v, want the flat fields in the embedded User", a)
+	}
+
+	out, err := json.Marshal(&a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(out, &fields); err != nil {
+		t.Fatalf("Marshal() = %s, not valid JSON: %v", out, err)
+	}
+	if _, nested := fields["User"]; nested {
+		t.Errorf("Marshal() = %s, nests the user", out)
+	}
+	if fields["name"] != "Janet" || fields["level"] != "super" || fields["cc"] == nil {
+		t.Errorf("Marshal() = %s, want flat name, level and cc", out)
+	}
+
+	// Encoding is stable across a round trip, so an encoded admin decodes
+	// to one that encodes the same way again.
+	var back notify.Admin
+	if err := json.Unmarshal(out, &back); err != nil {
+		t.Fatal(err)
+	}
+	again, err := json.Marshal(&back)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(again) != string(out) {
+		t.Errorf("round trip = %s, want %s", again, out)
+	}
+	if back.Name != a.Name || back.Level != a.Level || !slices.Equal(back.CC, []string{notify.MaskEmail("ops@email.com")}) {
+		t.Errorf("round trip = %+v, want name, level and masked cc of %+v", back, a)
+	}
+}
+
+func TestLoadAdminsFromJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    []string
+		wantErr bool
+		errIs   error
+	}{
+		{"valid", `[{"name":"Janet","email":"janet@email.com","level":"super"},{"name":"Lisa","email":"lisa@EMAIL.com","level":"root"}]`,
+			[]string{"Janet <janet@email.com> super", "Lisa <lisa@email.com> root"}, false, nil},
+		{"empty", `[]`, nil, false, nil},
+		{"unknown field", `[{"name":"Janet","email":"janet@email.com","level":"super","role":"x"}]`, nil, true, nil},
+		{"bad email", `[{"name":"Janet","email":"janet","level":"super"}]`, nil, true, notify.ErrInvalidEmail},
+		{"bad level", `[{"name":"Janet","email":"janet@email.com","level":"intern"}]`, nil, true, notify.ErrUnknownLevel},
+		{"missing level", `[{"name":"Janet","email":"janet@email.com"}]`, nil, true, notify.ErrEmptyLevel},
+		{"not an array", `{"name":"Janet"}`, nil, true, nil},
+		{"truncated", `[{"name":"Janet"`, nil, true, nil},
+		{"trailing data", `[] []`, nil, true, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			admins, err := notify.LoadAdminsFromJSON(strings.NewReader(tt.in))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadAdminsFromJSON() error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.errIs != nil && !errors.Is(err, tt.errIs) {
+				t.Errorf("LoadAdminsFromJSON() error = %v, want %v", err, tt.errIs)
+			}
+			if len(admins) != len(tt.want) {
+				t.Fatalf("got %d admins, want %d", len(admins), len(tt.want))
+			}
+			for i, a := range admins {
+				if got := a.Name + " <" + a.Email + "> " + string(a.Level); got != tt.want[i] {
+					t.Errorf("admin %d = %q, want %q", i, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestMaskEmail(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"janet@email.com", "j***t@email.com"},
+		{"jo@email.com", "j***@email.com"},
+		{"j@email.com", "***@email.com"},
+		{"@email.com", "***@email.com"},
+		{"zoë.ångström@email.com", "z***m@email.com"},
+		{"no-at-sign", "***"},
+	}
+	for _, tt := range tests {
+		if got := notify.MaskEmail(tt.in); got != tt.want {
+			t.Errorf("MaskEmail(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestMarshalJSONMasksEmail(t *testing.T) {
+	user := notify.User{Name: "Janet", Email: "janet@email.com"}
+	admin := notify.Admin{User: user, Level: notify.LevelSuper}
+	type response struct {
+		Owner  notify.User    `json:"owner"`
+		Admins []notify.Admin `json:"admins"`
+		Ptr    *notify.Admin  `json:"ptr"`
+	}
+
+	tests := []struct {
+		name string
+		v    any
+		want string
+	}{
+		{"user value", user, `{"name":"Janet","email":"j***t@email.com"}`},
+		{"user pointer", &user, `{"name":"Janet","email":"j***t@email.com"}`},
+		{"admin value", admin, `{"name":"Janet","email":"j***t@email.com","level":"super"}`},
+		{"admin cc", notify.Admin{User: user, Level: notify.LevelRoot, CC: []string{"ops@email.com", "bob@email.com"}},
+			`{"name":"Janet","email":"j***t@email.com","level":"root","cc":["o***s@email.com","b***b@email.com"]}`},
+		{"admin pointer", &admin, `{"name":"Janet","email":"j***t@email.com","level":"super"}`},
+		{"nested", response{Owner: user, Admins: []notify.Admin{admin}, Ptr: &admin},
+			`{"owner":{"name":"Janet","email":"j***t@email.com"},` +
+				`"admins":[{"name":"Janet","email":"j***t@email.com","level":"super"}],` +
+				`"ptr":{"name":"Janet","email":"j***t@email.com","level":"super"}}`},
+		{"map of values", map[string]notify.User{"u": user}, `{"u":{"name":"Janet","email":"j***t@email.com"}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := json.Marshal(tt.v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(out) != tt.want {
+				t.Errorf("Marshal() = %s, want %s", out, tt.want)
+			}
+			if strings.Contains(string(out), "janet@") {
+				t.Errorf("Marshal() = %s, leaks the email", out)
+			}
+			if !json.Valid(out) {
+				t.Errorf("Marshal() = %s, not valid JSON", out)
+			}
+		})
+	}
+}
+
+func FuzzUnmarshalAdmin(f *testing.F) {
+	for _, seed := range []string{
+		`{"name":"Janet","email":"janet@email.com","level":"super","cc":["ops@email.com"]}`,
+		`{"name":"Janet","email":"janet@email.com","level":"god"}`,
+		`{"name":"\u0000","email":"janet\u0000@email.com"}`,
+		`{"cc":[` + strings.Repeat(`"ops@email.com",`, 100) + `""]}`,
+		strings.Repeat(`{"name":`, 10000) + `"x"` + strings.Repeat("}", 10000),
+		strings.Repeat("[", 10000),
+		`{"level":1}`,
+	} {
+		f.Add([]byte(seed))
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var a notify.Admin
+		err := json.Unmarshal(data, &a)
+		if err == nil && !json.Valid(data) {
+			t.Fatalf("Unmarshal(%q) = nil, want an error for invalid JSON", data)
+		}
+		if err == nil {
+			out, err := json.Marshal(&a)
+			if err != nil || !json.Valid(out) {
+				t.Errorf("Marshal() = %q, %v, want valid JSON", out, err)
+			}
+		}
+
+		admins, err := notify.LoadAdminsFromJSON(strings.NewReader("[" + string(data) + "]"))
+		for _, a := range admins {
+			if err := a.Validate(); err != nil {
+				t.Errorf("LoadAdminsFromJSON() returned %+v, invalid: %v", a, err)
+			}
+		}
+		if err != nil && admins != nil {
+			t.Errorf("LoadAdminsFromJSON() = %v, %v, want no admins with an error", admins, err)
+		}
+	})
+}
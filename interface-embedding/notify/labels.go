@@ -0,0 +1,55 @@
+package notify
+
+import "maps"
+
+// WithLabels adds labels to the recipient, such as "team": "billing", for
+// routing and analytics. Later labels replace earlier ones with the same
+// key.
+func WithLabels(labels map[string]string) UserOption {
+	return func(u *User) error {
+		if u.labels == nil {
+			u.labels = make(map[string]string, len(labels))
+		}
+		maps.Copy(u.labels, labels)
+
+		return nil
+	}
+}
+
+// Labels returns a copy of the recipient's labels, or nil if it has none.
+func (u *User) Labels() map[string]string {
+	return maps.Clone(u.labels)
+}
+
+// Labeler is implemented by notifiers that carry labels. User implements
+// it, and types embedding User inherit it.
+type Labeler interface {
+	Labels() map[string]string
+}
+
+// FilterByLabel returns the notifiers whose label key is exactly value,
+// in order. Labels are found through any wrappers, as AddressOf does;
+// notifiers without labels are left out.
+func FilterByLabel(ns []Notifier, key, value string) []Notifier {
+	return Filter(ns, func(n Notifier) bool {
+		v, ok := labelsOf(n)[key]
+		return ok && v == value
+	})
+}
+
+// labelsOf returns the labels of n or the first notifier it wraps that
+// implements Labeler.
+func labelsOf(n Notifier) map[string]string {
+	for n != nil {
+		if l, ok := n.(Labeler); ok {
+			return l.Labels()
+		}
+		w, ok := n.(Wrapper)
+		if !ok {
+			break
+		}
+		n = w.Unwrap()
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package notify_test
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+func TestFilterByLabel(t *testing.T) {
+	var buf bytes.Buffer
+	user := func(email string, labels map[string]string) notify.Notifier {
+		u, err := notify.NewUser("Someone", email, append(quiet(&buf), notify.WithLabels(labels))...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return u
+	}
+	billing := user("janet@email.com", map[string]string{"team": "billing", "tier": "gold"})
+	ops := user("bob@email.com", map[string]string{"team": "ops"})
+	shouting := user("lisa@email.com", map[string]string{"team": "Billing"})
+	admin, err := notify.NewAdmin("Lisa Smith", "lisa.smith@email.com",
+		quietAdmin(&buf, notify.WithLabels(map[string]string{"team": "billing"}))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wrapped := &notify.TimeoutNotifier{Notifier: billing}
+	unlabeled := &notify.MockNotifier{}
+	all := []notify.Notifier{billing, ops, shouting, admin, wrapped, unlabeled}
+
+	tests := []struct {
+		name       string
+		key, value string
+		want       []notify.Notifier
+	}{
+		{"matching", "team", "billing", []notify.Notifier{billing, admin, wrapped}},
+		{"case-sensitive", "team", "Billing", []notify.Notifier{shouting}},
+		{"other key", "tier", "gold", []notify.Notifier{billing, wrapped}},
+		{"no such value", "team", "sales", nil},
+		{"no such key", "region", "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := notify.FilterByLabel(all, tt.key, tt.value); !slices.Equal(got, tt.want) {
+				t.Errorf("FilterByLabel(%q, %q) = %v, want %v", tt.key, tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLabelsReturnsCopy(t *testing.T) {
+	var buf bytes.Buffer
+	u, err := notify.NewUser("Janet Jones", "janet@email.com",
+		append(quiet(&buf), notify.WithLabels(map[string]string{"team": "billing"}), notify.WithLabels(map[string]string{"team": "ops"}))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := u.Labels()["team"]; got != "ops" {
+		t.Errorf(`Labels()["team"] = %q, want the later label "ops"`, got)
+	}
+
+	u.Labels()["team"] = "sales"
+	if got := u.Labels()["team"]; got != "ops" {
+		t.Errorf(`Labels()["team"] = %q after changing the copy, want "ops"`, got)
+	}
+	if got := (&notify.User{}).Labels(); got != nil {
+		t.Errorf("Labels() = %v, want nil without labels", got)
+	}
+}
@@ -0,0 +1,86 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Level is an admin's privilege level.
+type Level string
+
+// The levels an admin can hold, from least to most privileged.
+const (
+	LevelNormal Level = "normal"
+	LevelSuper  Level = "super"
+	LevelRoot   Level = "root"
+)
+
+// ErrUnknownLevel is returned for levels other than the ones declared
+// above.
+var ErrUnknownLevel = errors.New("notify: unknown level")
+
+// ParseLevel converts s, ignoring case and surrounding space, to a Level.
+func ParseLevel(s string) (Level, error) {
+	l := Level(strings.ToLower(strings.TrimSpace(s)))
+	if err := l.Validate(); err != nil {
+		return "", err
+	}
+
+	return l, nil
+}
+
+// Validate reports whether l is one of the declared levels.
+func (l Level) Validate() error {
+	switch l {
+	case LevelNormal, LevelSuper, LevelRoot:
+		return nil
+	}
+
+	return fmt.Errorf("%w %q", ErrUnknownLevel, string(l))
+}
+
+// Rank orders levels by privilege: 3 for root, 2 for super, 1 for normal
+// and 0 for unknown levels.
+func (l Level) Rank() int {
+	switch l {
+	case LevelRoot:
+		return 3
+	case LevelSuper:
+		return 2
+	case LevelNormal:
+		return 1
+	}
+
+	return 0
+}
+
+// Router picks how to notify an admin based on their level, such as paging
+// super admins while emailing normal ones.
+type Router struct {
+	// Routes builds the notifier for admins at each level.
+	Routes map[Level]func(*Admin) Notifier
+
+	// Default builds the notifier for admins whose level has no route.
+	// When nil the admin is notified directly.
+	Default func(*Admin) Notifier
+}
+
+// Route notifies a through the route for its level. Admins with a level
+// that has no route are sent through Default, and the result is joined
+// with an error wrapping ErrUnknownLevel so callers can tell a fallback
+// happened.
+func (r *Router) Route(ctx context.Context, a *Admin) error {
+	if route, ok := r.Routes[a.Level]; ok {
+		return SendNotification(ctx, route(a))
+	}
+
+	var n Notifier = a
+	if r.Default != nil {
+		n = r.Default(a)
+	}
+	err := SendNotification(ctx, n)
+
+	return errors.Join(fmt.Errorf("%w %q: used default route", ErrUnknownLevel, string(a.Level)), err)
+}
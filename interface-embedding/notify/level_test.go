@@ -0,0 +1,87 @@
+package notify_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    notify.Level
+		wantErr bool
+	}{
+		{"normal", notify.LevelNormal, false},
+		{" Super ", notify.LevelSuper, false},
+		{"ROOT", notify.LevelRoot, false},
+		{"", "", true},
+		{"admin", "", true},
+		{"super user", "", true},
+	}
+	for _, tt := range tests {
+		got, err := notify.ParseLevel(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseLevel(%q) = %q, %v, want %q, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+		if err != nil && !errors.Is(err, notify.ErrUnknownLevel) {
+			t.Errorf("ParseLevel(%q) error = %v, want it to match ErrUnknownLevel", tt.in, err)
+		}
+	}
+}
+
+func TestRouter(t *testing.T) {
+	page, email, fallback := &notify.MockNotifier{Name: "page"}, &notify.MockNotifier{Name: "email"}, &notify.MockNotifier{Name: "fallback"}
+	r := &notify.Router{
+		Routes: map[notify.Level]func(*notify.Admin) notify.Notifier{
+			notify.LevelRoot:   func(*notify.Admin) notify.Notifier { return page },
+			notify.LevelSuper:  func(*notify.Admin) notify.Notifier { return page },
+			notify.LevelNormal: func(*notify.Admin) notify.Notifier { return email },
+		},
+		Default: func(*notify.Admin) notify.Notifier { return fallback },
+	}
+
+	tests := []struct {
+		level       notify.Level
+		want        *notify.MockNotifier
+		wantUnknown bool
+	}{
+		{notify.LevelRoot, page, false},
+		{notify.LevelSuper, page, false},
+		{notify.LevelNormal, email, false},
+		{"intern", fallback, true},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.level), func(t *testing.T) {
+			page.Reset()
+			email.Reset()
+			fallback.Reset()
+
+			a := &notify.Admin{User: notify.User{Name: "Lisa", Email: "lisa@email.com"}, Level: tt.level}
+			err := r.Route(context.Background(), a)
+			if got := errors.Is(err, notify.ErrUnknownLevel); got != tt.wantUnknown {
+				t.Errorf("Route() = %v, want ErrUnknownLevel %v", err, tt.wantUnknown)
+			}
+			if !tt.wantUnknown && err != nil {
+				t.Errorf("Route() = %v", err)
+			}
+			if tt.want.Calls() != 1 {
+				t.Errorf("%s route called %d times, want 1", tt.want.Name, tt.want.Calls())
+			}
+			if total := page.Calls() + email.Calls() + fallback.Calls(); total != 1 {
+				t.Errorf("%d routes called, want 1", total)
+			}
+		})
+	}
+}
+
+func TestLevelRank(t *testing.T) {
+	levels := []notify.Level{"intern", notify.LevelNormal, notify.LevelSuper, notify.LevelRoot}
+	for i, l := range levels {
+		if l.Rank() != i {
+			t.Errorf("%q.Rank() = %d, want %d", l, l.Rank(), i)
+		}
+	}
+}
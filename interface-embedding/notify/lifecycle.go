@@ -0,0 +1,99 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrAlreadyStarted is returned by Start when a component is already
+// running.
+var ErrAlreadyStarted = errors.New("notify: already started")
+
+// Lifecycle is implemented by components that run goroutines of their own,
+// such as Queue and Scheduler, so that a Manager can start and stop them
+// together. Shutdown should return ctx.Err() if ctx is done before the
+// component has stopped, and nil when it isn't running.
+type Lifecycle interface {
+	Start(ctx context.Context) error
+	Shutdown(ctx context.Context) error
+}
+
+// Manager starts a set of components in the order they were registered
+// and shuts them down in reverse, so that each is stopped before the ones
+// it was started after. The zero value is ready to use.
+type Manager struct {
+	// StopTimeout, when positive, bounds each component's Shutdown in
+	// StopAll, so that one stuck component can't hold up the rest.
+	StopTimeout time.Duration
+
+	mu         sync.Mutex
+	components []Lifecycle
+	started    []Lifecycle
+}
+
+// Register adds c to the components started by StartAll.
+func (m *Manager) Register(c Lifecycle) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.components = append(m.components, c)
+}
+
+// StartAll starts every registered component in registration order. If
+// one fails, those already started are shut down again, in reverse order,
+// and the start error is returned along with any from the rollback.
+func (m *Manager) StartAll(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.started) > 0 {
+		return ErrAlreadyStarted
+	}
+	for i, c := range m.components {
+		if err := c.Start(ctx); err != nil {
+			err = fmt.Errorf("notify: starting component %d: %w", i, err)
+			return errors.Join(err, m.stopLocked(ctx))
+		}
+		m.started = append(m.started, c)
+	}
+
+	return nil
+}
+
+// StopAll shuts down the started components in reverse order. A component
+// that fails or runs past StopTimeout doesn't stop the rest being shut
+// down; its error is wrapped with its position and joined into the result.
+// Calling StopAll again, or before StartAll, does nothing.
+func (m *Manager) StopAll(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.stopLocked(ctx)
+}
+
+// stopLocked shuts down m.started in reverse order and forgets them.
+func (m *Manager) stopLocked(ctx context.Context) error {
+	var errs []error
+	for i := len(m.started) - 1; i >= 0; i-- {
+		if err := m.shutdown(ctx, m.started[i]); err != nil {
+			errs = append(errs, fmt.Errorf("notify: stopping component %d: %w", i, err))
+		}
+	}
+	m.started = nil
+
+	return errors.Join(errs...)
+}
+
+// shutdown stops c, giving it StopTimeout when one is set.
+func (m *Manager) shutdown(ctx context.Context, c Lifecycle) error {
+	if m.StopTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.StopTimeout)
+		defer cancel()
+	}
+
+	return c.Shutdown(ctx)
+}
@@ -0,0 +1,152 @@
+package notify_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+// component is a Lifecycle that records its calls in a shared log. A stuck
+// component doesn't stop until its Shutdown context is done.
+type component struct {
+	name     string
+	log      *[]string
+	mu       *sync.Mutex
+	startErr error
+	stuck    bool
+}
+
+func (c *component) record(event string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	*c.log = append(*c.log, event+" "+c.name)
+}
+
+func (c *component) Start(context.Context) error {
+	c.record("start")
+	return c.startErr
+}
+
+func (c *component) Shutdown(ctx context.Context) error {
+	c.record("stop")
+	if c.stuck {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	return nil
+}
+
+func TestManager(t *testing.T) {
+	errBind := errors.New("address in use")
+
+	tests := []struct {
+		name        string
+		startErr    map[string]error
+		stuck       string
+		wantStart   error
+		wantStop    error
+		wantStopMsg string
+		wantLog     []string
+	}{
+		{name: "starts in order, stops in reverse",
+			wantLog: []string{"start a", "start b", "start c", "stop c", "stop b", "stop a"}},
+		{name: "partial start rolled back", startErr: map[string]error{"c": errBind}, wantStart: errBind,
+			wantLog: []string{"start a", "start b", "start c", "stop b", "stop a"}},
+		{name: "first fails, nothing to roll back", startErr: map[string]error{"a": errBind}, wantStart: errBind,
+			wantLog: []string{"start a"}},
+		{name: "stop timeout doesn't stop the rest", stuck: "b", wantStop: context.DeadlineExceeded, wantStopMsg: "stopping component 1",
+			wantLog: []string{"start a", "start b", "start c", "stop c", "stop b", "stop a"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var log []string
+			var mu sync.Mutex
+			m := &notify.Manager{StopTimeout: 20 * time.Millisecond}
+			for _, name := range []string{"a", "b", "c"} {
+				m.Register(&component{name: name, log: &log, mu: &mu, startErr: tt.startErr[name], stuck: name == tt.stuck})
+			}
+
+			err := m.StartAll(context.Background())
+			if !errors.Is(err, tt.wantStart) {
+				t.Fatalf("StartAll() = %v, want %v", err, tt.wantStart)
+			}
+			if err == nil {
+				err := m.StopAll(context.Background())
+				if !errors.Is(err, tt.wantStop) || !strings.Contains(errString(err), tt.wantStopMsg) {
+					t.Errorf("StopAll() = %v, want %v, naming %q", err, tt.wantStop, tt.wantStopMsg)
+				}
+			}
+			if !slices.Equal(log, tt.wantLog) {
+				t.Errorf("calls = %q, want %q", log, tt.wantLog)
+			}
+
+			// Stopping again does nothing.
+			if err := m.StopAll(context.Background()); err != nil {
+				t.Errorf("second StopAll() = %v, want nil", err)
+			}
+			if !slices.Equal(log, tt.wantLog) {
+				t.Errorf("calls after second StopAll = %q, want none added", log)
+			}
+		})
+	}
+}
+
+// errString returns err's message, or "" for nil.
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+func TestManagerStartTwice(t *testing.T) {
+	var log []string
+	var mu sync.Mutex
+	var m notify.Manager
+	m.Register(&component{name: "a", log: &log, mu: &mu})
+
+	if err := m.StartAll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.StartAll(context.Background()); !errors.Is(err, notify.ErrAlreadyStarted) {
+		t.Errorf("second StartAll() = %v, want %v", err, notify.ErrAlreadyStarted)
+	}
+	if err := m.StopAll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	// Once stopped, the components can be started again.
+	if err := m.StartAll(context.Background()); err != nil {
+		t.Errorf("StartAll() after StopAll = %v, want nil", err)
+	}
+	m.StopAll(context.Background())
+	if want := []string{"start a", "stop a", "start a", "stop a"}; !slices.Equal(log, want) {
+		t.Errorf("calls = %q, want %q", log, want)
+	}
+}
+
+func TestManagerQueueAndScheduler(t *testing.T) {
+	checkNoLeaks(t)
+	q := notify.NewQueue(1)
+	s := notify.NewScheduler(nil)
+	var m notify.Manager
+	m.Register(q)
+	m.Register(s)
+
+	if err := m.StartAll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	mock := &notify.MockNotifier{}
+	q.Enqueue(mock)
+	s.Every(mock, time.Hour)
+	waitFor(t, "the queued send", func() bool { return mock.Calls() >= 1 })
+
+	if err := m.StopAll(context.Background()); err != nil {
+		t.Errorf("StopAll() = %v, want nil", err)
+	}
+}
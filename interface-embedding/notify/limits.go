@@ -0,0 +1,163 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"unicode/utf8"
+)
+
+// ErrPayloadTooLarge is returned when a notification exceeds a size limit
+// and is rejected rather than truncated. It is permanent: the same
+// notification would be too large every time.
+var ErrPayloadTooLarge = Permanent(errors.New("notify: payload too large"))
+
+// ellipsis marks truncated text.
+const ellipsis = "…"
+
+// Limits bounds the size of a notification. A zero field means no limit.
+type Limits struct {
+	// MaxBodyBytes bounds the message body, in bytes of UTF-8.
+	MaxBodyBytes int
+
+	// MaxSubjectRunes bounds the subject, for notifiers that have one,
+	// in characters.
+	MaxSubjectRunes int
+}
+
+// LimitMode says what happens to a notification that exceeds its limits.
+type LimitMode int
+
+const (
+	// LimitTruncate shortens the text to fit, ending it with an ellipsis.
+	LimitTruncate LimitMode = iota
+
+	// LimitReject refuses the notification with ErrPayloadTooLarge.
+	LimitReject
+)
+
+// LimitingNotifier enforces Limits on the notifications sent through the
+// embedded Notifier. The limits travel in the context, so they apply to
+// the notifiers in this package that render a body or subject, however
+// deeply they are wrapped; others send unchanged.
+type LimitingNotifier struct {
+	Notifier
+
+	limits Limits
+	mode   LimitMode
+}
+
+// NewLimitingNotifier wraps n so that its notifications fit within limits,
+// truncated or rejected according to mode.
+func NewLimitingNotifier(n Notifier, limits Limits, mode LimitMode) *LimitingNotifier {
+	return &LimitingNotifier{Notifier: n, limits: limits, mode: mode}
+}
+
+// Notify sends through the embedded Notifier with the limits in effect.
+func (l *LimitingNotifier) Notify(ctx context.Context) error {
+	return l.Notifier.Notify(context.WithValue(ctx, limitsKey{}, l))
+}
+
+// Unwrap returns the notifier being limited.
+func (l *LimitingNotifier) Unwrap() Notifier {
+	return l.Notifier
+}
+
+// limitsKey is the context key under which LimitingNotifier passes itself
+// to the notifier it wraps.
+type limitsKey struct{}
+
+// limitBody applies the body limit in ctx, if any, to body.
+func limitBody(ctx context.Context, body string) (string, error) {
+	l, ok := ctx.Value(limitsKey{}).(*LimitingNotifier)
+	if !ok || l.limits.MaxBodyBytes <= 0 || len(body) <= l.limits.MaxBodyBytes {
+		return body, nil
+	}
+	if l.mode == LimitReject {
+		return "", fmt.Errorf("%w: body is %d bytes, limit is %d", ErrPayloadTooLarge, len(body), l.limits.MaxBodyBytes)
+	}
+
+	return TruncateBytes(body, l.limits.MaxBodyBytes), nil
+}
+
+// limitLine is limitBody for a message being built in buf. Messages
+// within the limit are returned as they are.
+func limitLine(ctx context.Context, buf []byte) ([]byte, error) {
+	l, ok := ctx.Value(limitsKey{}).(*LimitingNotifier)
+	if !ok || l.limits.MaxBodyBytes <= 0 || len(buf) <= l.limits.MaxBodyBytes {
+		return buf, nil
+	}
+	body, err := limitBody(ctx, string(buf))
+	if err != nil {
+		return buf, err
+	}
+
+	return append(buf[:0], body...), nil
+}
+
+// limitSubject applies the subject limit in ctx, if any, to subject.
+func limitSubject(ctx context.Context, subject string) (string, error) {
+	l, ok := ctx.Value(limitsKey{}).(*LimitingNotifier)
+	if !ok || l.limits.MaxSubjectRunes <= 0 {
+		return subject, nil
+	}
+	n := utf8.RuneCountInString(subject)
+	if n <= l.limits.MaxSubjectRunes {
+		return subject, nil
+	}
+	if l.mode == LimitReject {
+		return "", fmt.Errorf("%w: subject is %d characters, limit is %d", ErrPayloadTooLarge, n, l.limits.MaxSubjectRunes)
+	}
+
+	return TruncateRunes(subject, l.limits.MaxSubjectRunes), nil
+}
+
+// TruncateBytes shortens s to at most limit bytes, ending it with an
+// ellipsis when anything was cut. It only cuts between characters, so the
+// result is valid UTF-8 whenever s is. Strings that already fit are
+// returned unchanged.
+func TruncateBytes(s string, limit int) string {
+	if len(s) <= limit {
+		return s
+	}
+	if limit < len(ellipsis) {
+		return cutBytes(s, limit)
+	}
+
+	return cutBytes(s, limit-len(ellipsis)) + ellipsis
+}
+
+// TruncateRunes shortens s to at most limit characters, the last of them
+// an ellipsis when anything was cut. Strings that already fit are returned
+// unchanged.
+func TruncateRunes(s string, limit int) string {
+	if utf8.RuneCountInString(s) <= limit {
+		return s
+	}
+	if limit <= 0 {
+		return ""
+	}
+
+	n := 0
+	for i := range s {
+		if n == limit-1 {
+			return s[:i] + ellipsis
+		}
+		n++
+	}
+
+	return s
+}
+
+// cutBytes returns the longest prefix of s of at most limit bytes that
+// doesn't end partway through a UTF-8 sequence.
+func cutBytes(s string, limit int) string {
+	if limit < 0 {
+		limit = 0
+	}
+	for limit > 0 && !utf8.RuneStart(s[limit]) {
+		limit--
+	}
+
+	return s[:limit]
+}
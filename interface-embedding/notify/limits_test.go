@@ -0,0 +1,202 @@
+package notify_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+func TestTruncateBytes(t *testing.T) {
+	tests := []struct {
+		name  string
+		s     string
+		limit int
+		want  string
+	}{
+		{"fits", "hello", 10, "hello"},
+		{"exact", "hello", 5, "hello"},
+		{"ascii", "hello world", 8, "hello…"},
+		{"before a two-byte rune", "café au lait", 6, "caf…"},
+		{"inside a two-byte rune", "café au lait", 7, "caf…"},
+		{"after a two-byte rune", "café au lait", 8, "café…"},
+		{"inside a three-byte rune", "日本語のテキスト", 10, "日本…"},
+		{"inside a four-byte rune", "a😀b😀c", 7, "a…"},
+		{"shorter than the ellipsis", "日本語", 2, ""},
+		{"ellipsis only", "hello", 3, "…"},
+		{"zero", "hello", 0, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := notify.TruncateBytes(tt.s, tt.limit)
+			if got != tt.want {
+				t.Errorf("TruncateBytes(%q, %d) = %q, want %q", tt.s, tt.limit, got, tt.want)
+			}
+			if len(got) > tt.limit || !utf8.ValidString(got) {
+				t.Errorf("TruncateBytes(%q, %d) = %q, want valid UTF-8 within the limit", tt.s, tt.limit, got)
+			}
+		})
+	}
+}
+
+func TestTruncateRunes(t *testing.T) {
+	tests := []struct {
+		name  string
+		s     string
+		limit int
+		want  string
+	}{
+		{"fits", "日本語", 5, "日本語"},
+		{"exact", "日本語", 3, "日本語"},
+		{"cut", "日本語のテキスト", 4, "日本語…"},
+		{"one", "日本語", 1, "…"},
+		{"zero", "日本語", 0, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := notify.TruncateRunes(tt.s, tt.limit); got != tt.want {
+				t.Errorf("TruncateRunes(%q, %d) = %q, want %q", tt.s, tt.limit, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLimitingNotifier(t *testing.T) {
+	// The message is "Sending user email to Zoë Jones<zoe@email.com>",
+	// 47 bytes with the ë taking two.
+	tests := []struct {
+		name    string
+		limit   int
+		mode    notify.LimitMode
+		wantOut string
+		wantErr bool
+	}{
+		{"no limit", 0, notify.LimitReject, "Sending user email to Zoë Jones<zoe@email.com>\n", false},
+		{"exact limit", 47, notify.LimitReject, "Sending user email to Zoë Jones<zoe@email.com>\n", false},
+		{"truncated", 29, notify.LimitTruncate, "Sending user email to Zoë…\n", false},
+		{"truncated at a rune boundary", 28, notify.LimitTruncate, "Sending user email to Zo…\n", false},
+		{"rejected", 46, notify.LimitReject, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			u, err := notify.NewUser("Zoë Jones", "zoe@email.com", quiet(&buf)...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			n := notify.NewLimitingNotifier(&notify.TimeoutNotifier{Notifier: u}, notify.Limits{MaxBodyBytes: tt.limit}, tt.mode)
+
+			err = n.Notify(context.Background())
+			if tt.wantErr != errors.Is(err, notify.ErrPayloadTooLarge) {
+				t.Fatalf("Notify() = %v, want ErrPayloadTooLarge %v", err, tt.wantErr)
+			}
+			if err != nil && notify.IsRetryable(err) {
+				t.Errorf("IsRetryable(%v) = true, want an oversized payload to be permanent", err)
+			}
+			if got := buf.String(); got != tt.wantOut {
+				t.Errorf("output = %q, want %q", got, tt.wantOut)
+			}
+		})
+	}
+}
+
+func TestSMTPNotifierLimits(t *testing.T) {
+	janet := &notify.User{Name: "Janet Jones", Email: "janet@email.com"}
+	longLine := strings.Repeat("0123456789", 150)
+
+	tests := []struct {
+		name        string
+		subject     string
+		body        string
+		limits      notify.Limits
+		mode        notify.LimitMode
+		wantSubject string
+		wantBody    string
+		wantErr     bool
+	}{
+		{"subject truncated", "Disk is full on db-1", "", notify.Limits{MaxSubjectRunes: 10}, notify.LimitTruncate,
+			"Disk is f…", "Sending user email to Janet Jones<janet@email.com>\n", false},
+		{"subject rejected", "Disk is full on db-1", "", notify.Limits{MaxSubjectRunes: 10}, notify.LimitReject, "", "", true},
+		{"body truncated", "Disk", "The disk is full.", notify.Limits{MaxBodyBytes: 11}, notify.LimitTruncate,
+			"Disk", "The disk…\n", false},
+		{"body rejected", "Disk", "The disk is full.", notify.Limits{MaxBodyBytes: 11}, notify.LimitReject, "", "", true},
+		{"long words folded", strings.Repeat("full ", 250), "", notify.Limits{}, notify.LimitTruncate,
+			strings.Repeat("full ", 250)[:899] + "…", "Sending user email to Janet Jones<janet@email.com>\n", false},
+		{"long subject capped", strings.Repeat("x", 2000), "", notify.Limits{}, notify.LimitTruncate,
+			strings.Repeat("x", 899) + "…", "Sending user email to Janet Jones<janet@email.com>\n", false},
+		{"long body line", "Disk", longLine, notify.Limits{}, notify.LimitTruncate, "Disk", longLine + "\n", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := startFakeSMTP(t, &fakeSMTP{})
+			smtpN := &notify.SMTPNotifier{Addr: srv.addr, From: "alerts@email.com", To: janet, Subject: tt.subject, Body: tt.body}
+			err := notify.NewLimitingNotifier(smtpN, tt.limits, tt.mode).Notify(context.Background())
+			if tt.wantErr {
+				if !errors.Is(err, notify.ErrPayloadTooLarge) {
+					t.Errorf("Notify() = %v, want %v", err, notify.ErrPayloadTooLarge)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Notify() = %v", err)
+			}
+
+			_, data := srv.session()
+			for line := range strings.Lines(data) {
+				if len(strings.TrimRight(line, "\r\n")) > 998 {
+					t.Fatalf("message has a %d-octet line, over SMTP's 998:\n%s", len(line), data)
+				}
+			}
+			header, _, _ := strings.Cut(data, "\n\n")
+			for line := range strings.Lines(header) {
+				// A line can only be folded between words, and an encoded
+				// word can't be split.
+				line = strings.TrimRight(line, "\r\n")
+				if len(line) > 78 && len(strings.Fields(line)) > 2 {
+					t.Errorf("header line %q is over 78 characters, want it folded", line)
+				}
+			}
+			msg, err := mail.ReadMessage(strings.NewReader(data))
+			if err != nil {
+				t.Fatalf("ReadMessage() = %v\n%s", err, data)
+			}
+			subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+			if err != nil || subject != tt.wantSubject {
+				t.Errorf("Subject = %q, %v, want %q", subject, err, tt.wantSubject)
+			}
+
+			var body io.Reader = msg.Body
+			if msg.Header.Get("Content-Transfer-Encoding") == "quoted-printable" {
+				body = quotedprintable.NewReader(body)
+			}
+			got, err := io.ReadAll(body)
+			if err != nil || strings.ReplaceAll(string(got), "\r\n", "\n") != tt.wantBody {
+				t.Errorf("body = %q, %v, want %q", got, err, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestWebhookNotifierPayloadLimit(t *testing.T) {
+	srv, hits := webhookServer(t, 200)
+	w := &notify.WebhookNotifier{
+		URL:    srv.URL,
+		To:     &notify.User{Name: strings.Repeat("x", 1<<20), Email: "janet@email.com"},
+		Client: srv.Client(),
+	}
+	err := w.Notify(context.Background())
+	if !errors.Is(err, notify.ErrPayloadTooLarge) || notify.IsRetryable(err) {
+		t.Errorf("Notify() = %v, want a permanent ErrPayloadTooLarge", err)
+	}
+	if hits.Load() != 0 {
+		t.Errorf("server got %d requests, want none for an oversized payload", hits.Load())
+	}
+}
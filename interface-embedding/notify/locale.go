@@ -0,0 +1,225 @@
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DefaultLocale is the locale of recipients built without WithLocale.
+const DefaultLocale = "en-US"
+
+// defaultLocaleID is DefaultLocale lowercased for catalog lookups.
+var defaultLocaleID = strings.ToLower(DefaultLocale)
+
+// FallbackLocale is the catalog locale used when a recipient's locale, or
+// the message within it, is missing.
+const FallbackLocale = "en"
+
+// Catalog message keys used by the built-in notifiers.
+const (
+	KeyUserEmail  = "user.email"
+	KeyAdminEmail = "admin.email"
+)
+
+// ErrMissingTranslation is returned when a message is missing from the
+// requested locale, its base language and FallbackLocale.
+var ErrMissingTranslation = errors.New("notify: missing translation")
+
+// ErrBadTranslation is returned when a translation's formatting verbs
+// don't match the arguments the message is formatted with.
+var ErrBadTranslation = errors.New("notify: translation has the wrong number of verbs")
+
+// Catalog maps locale tags to message keys to fmt format strings. Tags
+// are matched ignoring case, so a catalog may use "pt-BR" or "pt-br".
+type Catalog map[string]map[string]string
+
+// DefaultCatalog holds the messages used by recipients built without
+// WithCatalog.
+var DefaultCatalog = Catalog{
+	"en": {
+		KeyUserEmail:  "Sending user email to %s<%s>",
+		KeyAdminEmail: "Sending admin email to %s<%s> with level %s",
+	},
+	"es": {
+		KeyUserEmail:  "Enviando correo de usuario a %s<%s>",
+		KeyAdminEmail: "Enviando correo de administrador a %s<%s> con nivel %s",
+	},
+	"fr": {
+		KeyUserEmail:  "Envoi d'un e-mail utilisateur à %s<%s>",
+		KeyAdminEmail: "Envoi d'un e-mail administrateur à %s<%s> avec le niveau %s",
+	},
+	"de": {
+		KeyUserEmail:  "Sende Benutzer-E-Mail an %s<%s>",
+		KeyAdminEmail: "Sende Administrator-E-Mail an %s<%s> mit Stufe %s",
+	},
+}
+
+// Format looks up key for locale and formats it with args. When the
+// locale or the key within it is missing, it tries the locale's base
+// language, so "de-AT" falls back to "de", and then FallbackLocale.
+func (c Catalog) Format(locale, key string, args ...any) (string, error) {
+	format, err := c.find(locale, key, len(args))
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf(format, args...), nil
+}
+
+// find returns the format string Format would use for key in locale,
+// checking that it takes nargs arguments.
+func (c Catalog) find(locale, key string, nargs int) (string, error) {
+	format, ok := c.lookup(locale, key)
+	if base, _, found := strings.Cut(locale, "-"); !ok && found {
+		format, ok = c.lookup(base, key)
+	}
+	if !ok {
+		format, ok = c.lookup(FallbackLocale, key)
+	}
+	if !ok {
+		return "", fmt.Errorf("%w: %q for locale %q", ErrMissingTranslation, key, locale)
+	}
+	if n := countVerbs(format); n != nargs {
+		return "", fmt.Errorf("%w: %q for locale %q has %d, want %d", ErrBadTranslation, key, locale, n, nargs)
+	}
+
+	return format, nil
+}
+
+// lookup returns the message for key in locale, matching the tag without
+// regard to case. Exact matches, as for DefaultCatalog's lowercase tags,
+// are found without scanning.
+func (c Catalog) lookup(locale, key string) (string, bool) {
+	if messages, ok := c[locale]; ok {
+		format, ok := messages[key]
+		return format, ok
+	}
+	for tag, messages := range c {
+		if strings.EqualFold(tag, locale) {
+			format, ok := messages[key]
+			return format, ok
+		}
+	}
+
+	return "", false
+}
+
+// countVerbs counts the formatting verbs in format, not counting the "%%"
+// escape.
+func countVerbs(format string) int {
+	n := 0
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		if i+1 < len(format) && format[i+1] == '%' {
+			i++
+			continue
+		}
+		n++
+	}
+
+	return n
+}
+
+// localeTag matches BCP 47 style tags such as "en", "fr-CA", or "zh-Hant".
+var localeTag = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+
+// WithLocale sets the locale the recipient prefers, such as "fr-CA".
+func WithLocale(tag string) UserOption {
+	return func(u *User) error {
+		if !localeTag.MatchString(tag) {
+			return fmt.Errorf("notify: invalid locale %q", tag)
+		}
+		u.locale = tag
+		u.localeID = strings.ToLower(tag)
+
+		return nil
+	}
+}
+
+// WithCatalog replaces DefaultCatalog as the source of the recipient's
+// messages.
+func WithCatalog(c Catalog) UserOption {
+	return func(u *User) error {
+		u.catalog = c
+		return nil
+	}
+}
+
+// Locale returns the recipient's locale, or DefaultLocale when none was
+// set.
+func (u *User) Locale() string {
+	if u.locale == "" {
+		return DefaultLocale
+	}
+
+	return u.locale
+}
+
+// localize formats the catalog message key in the user's locale.
+func (u *User) localize(key string, args ...string) (string, error) {
+	msg, err := u.appendLocalized(nil, key, args...)
+	return string(msg), err
+}
+
+// appendLocalized appends the catalog message key, formatted in the
+// user's locale, to buf. Messages whose verbs are all %s, as in
+// DefaultCatalog, are built by copying args into place, so sending into a
+// reused buffer doesn't allocate; other verbs go through fmt.
+func (u *User) appendLocalized(buf []byte, key string, args ...string) ([]byte, error) {
+	c := u.catalog
+	if c == nil {
+		c = DefaultCatalog
+	}
+
+	locale := u.localeID
+	if locale == "" {
+		locale = defaultLocaleID
+	}
+
+	format, err := c.find(locale, key, len(args))
+	if err != nil {
+		return buf, err
+	}
+	if !onlyStringVerbs(format) {
+		anys := make([]any, len(args))
+		for i, a := range args {
+			anys[i] = a
+		}
+		return fmt.Appendf(buf, format, anys...), nil
+	}
+
+	for {
+		i := strings.IndexByte(format, '%')
+		if i < 0 {
+			return append(buf, format...), nil
+		}
+		buf = append(buf, format[:i]...)
+		if format[i+1] == '%' {
+			buf = append(buf, '%')
+		} else {
+			buf = append(buf, args[0]...)
+			args = args[1:]
+		}
+		format = format[i+2:]
+	}
+}
+
+// onlyStringVerbs reports whether every verb in format is a bare %s,
+// ignoring the "%%" escape.
+func onlyStringVerbs(format string) bool {
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		i++
+		if i == len(format) || (format[i] != 's' && format[i] != '%') {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,139 @@
+package notify_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+func TestCatalogFormat(t *testing.T) {
+	c := notify.Catalog{
+		"en":    {"greet": "Hello %s", "bye": "Bye %s"},
+		"de":    {"greet": "Hallo %s"},
+		"pt-BR": {"greet": "Olá %s"},
+		"fr":    {"greet": "Bonjour"},
+		"es":    {"greet": "Hola %s, 100%% %s"},
+	}
+	tests := []struct {
+		locale, key string
+		want        string
+		wantErr     error
+	}{
+		{"en", "greet", "Hello Janet", nil},
+		{"de", "greet", "Hallo Janet", nil},
+		{"de-AT", "greet", "Hallo Janet", nil},
+		{"de", "bye", "Bye Janet", nil},
+		{"pt-BR", "greet", "Olá Janet", nil},
+		{"pt-br", "greet", "Olá Janet", nil},
+		{"PT-BR", "greet", "Olá Janet", nil},
+		{"pt", "greet", "Hello Janet", nil},
+		{"xx", "greet", "Hello Janet", nil},
+		{"en", "missing", "", notify.ErrMissingTranslation},
+		{"fr", "greet", "", notify.ErrBadTranslation},
+		{"es", "greet", "", notify.ErrBadTranslation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.locale+"/"+tt.key, func(t *testing.T) {
+			got, err := c.Format(tt.locale, tt.key, "Janet")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Format() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Format() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithLocale(t *testing.T) {
+	custom := notify.Catalog{
+		"en":    {notify.KeyUserEmail: "Mail to %s<%s>", notify.KeyAdminEmail: "Admin mail to %s<%s> (%s)"},
+		"pt-BR": {notify.KeyUserEmail: "Enviando e-mail para %s<%s>", notify.KeyAdminEmail: "Enviando e-mail de admin para %s<%s> nível %s"},
+	}
+	tests := []struct {
+		name    string
+		opts    []notify.AdminOption
+		want    string
+		wantErr bool
+	}{
+		{"default", nil, "Sending admin email to Lisa<lisa@email.com> with level super\n", false},
+		{"spanish", []notify.AdminOption{notify.WithLocale("es")}, "Enviando correo de administrador a Lisa<lisa@email.com> con nivel super\n", false},
+		{"german region", []notify.AdminOption{notify.WithLocale("de-CH")}, "Sende Administrator-E-Mail an Lisa<lisa@email.com> mit Stufe super\n", false},
+		{"unknown tag falls back", []notify.AdminOption{notify.WithLocale("sw")}, "Sending admin email to Lisa<lisa@email.com> with level super\n", false},
+		{"mixed-case custom catalog", []notify.AdminOption{notify.WithCatalog(custom), notify.WithLocale("pt-BR")},
+			"Enviando e-mail de admin para Lisa<lisa@email.com> nível super\n", false},
+		{"mixed-case tag", []notify.AdminOption{notify.WithCatalog(custom), notify.WithLocale("PT-br")},
+			"Enviando e-mail de admin para Lisa<lisa@email.com> nível super\n", false},
+		{"custom fallback", []notify.AdminOption{notify.WithCatalog(custom), notify.WithLocale("fr")},
+			"Admin mail to Lisa<lisa@email.com> (super)\n", false},
+		{"invalid tag", []notify.AdminOption{notify.WithLocale("not a tag")}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			a, err := notify.NewAdmin("Lisa", "lisa@email.com", quietAdmin(&buf, append(tt.opts, notify.WithLevel(notify.LevelSuper))...)...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewAdmin() error = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if err := a.Notify(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUserLocale(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []notify.UserOption
+		want string
+	}{
+		{"no locale", nil, "Sending user email to Janet<janet@email.com>\n"},
+		{"french", []notify.UserOption{notify.WithLocale("fr")}, "Envoi d'un e-mail utilisateur à Janet<janet@email.com>\n"},
+		{"french region", []notify.UserOption{notify.WithLocale("fr-CA")}, "Envoi d'un e-mail utilisateur à Janet<janet@email.com>\n"},
+		{"unknown locale", []notify.UserOption{notify.WithLocale("ja")}, "Sending user email to Janet<janet@email.com>\n"},
+		{"english", []notify.UserOption{notify.WithLocale("en-GB")}, "Sending user email to Janet<janet@email.com>\n"},
+		{"escaped percent", []notify.UserOption{notify.WithCatalog(notify.Catalog{"en": {notify.KeyUserEmail: "100%% sure: %s<%s>%%"}})}, "100% sure: Janet<janet@email.com>%\n"},
+		{"other verbs", []notify.UserOption{notify.WithCatalog(notify.Catalog{"en": {notify.KeyUserEmail: "To %q at %-16s|"}})}, "To \"Janet\" at janet@email.com |\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			u, err := notify.NewUser("Janet", "janet@email.com", append(quiet(&buf), tt.opts...)...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := u.Notify(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNotifyMissingTranslation(t *testing.T) {
+	var buf bytes.Buffer
+	empty := notify.Catalog{"en": {}}
+	u, err := notify.NewUser("Janet", "janet@email.com", append(quiet(&buf), notify.WithCatalog(empty))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := u.Notify(context.Background()); !errors.Is(err, notify.ErrMissingTranslation) {
+		t.Errorf("Notify() = %v, want %v", err, notify.ErrMissingTranslation)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("Notify() wrote %q without a translation", buf.String())
+	}
+}
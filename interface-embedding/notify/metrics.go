@@ -0,0 +1,184 @@
+package notify
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultBuckets are the upper bounds of the latency histogram kept by
+// Metrics. Durations above the last bound land in an overflow bucket.
+// Each Metrics copies them when it records its first notification, so
+// changing them later only affects Metrics that haven't been used yet.
+var DefaultBuckets = []time.Duration{
+	time.Millisecond,
+	10 * time.Millisecond,
+	100 * time.Millisecond,
+	time.Second,
+}
+
+// Metrics counts notification outcomes and buckets their latency using
+// atomic counters, so recording never blocks, in total and for each
+// channel. The zero value is ready to use and safe for concurrent use.
+type Metrics struct {
+	once   sync.Once
+	bounds []time.Duration
+	total  *outcomes
+
+	mu       sync.RWMutex
+	channels map[string]*outcomes
+}
+
+// outcomes are the counters Metrics keeps, in total and per channel.
+type outcomes struct {
+	sent   atomic.Int64
+	failed atomic.Int64
+	sum    atomic.Int64 // nanoseconds
+	bounds []time.Duration
+	counts []atomic.Int64
+}
+
+// MetricsSnapshot is a point-in-time copy of Metrics that can be read and
+// copied freely. Counts holds one entry per bound in Bounds plus a final
+// overflow entry, and Sum is the total latency. Channels breaks the same
+// figures down by the channel AddressOf reports; its entries share Bounds
+// and have no Channels of their own.
+type MetricsSnapshot struct {
+	Sent     int64
+	Failed   int64
+	Bounds   []time.Duration
+	Counts   []int64
+	Sum      time.Duration
+	Channels map[string]MetricsSnapshot
+}
+
+// newOutcomes returns counters with a histogram bucket for each of bounds
+// plus overflow.
+func newOutcomes(bounds []time.Duration) *outcomes {
+	return &outcomes{bounds: bounds, counts: make([]atomic.Int64, len(bounds)+1)}
+}
+
+// totals returns the overall counters, copying DefaultBuckets and
+// allocating the counters on first use.
+func (m *Metrics) totals() *outcomes {
+	m.once.Do(func() {
+		m.bounds = slices.Clone(DefaultBuckets)
+		m.total = newOutcomes(m.bounds)
+	})
+
+	return m.total
+}
+
+// channel returns the counters for channel, allocating them on first use.
+func (m *Metrics) channel(channel string) *outcomes {
+	bounds := m.totals().bounds
+	m.mu.RLock()
+	o, ok := m.channels[channel]
+	m.mu.RUnlock()
+	if ok {
+		return o
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if o, ok = m.channels[channel]; !ok {
+		if m.channels == nil {
+			m.channels = make(map[string]*outcomes)
+		}
+		o = newOutcomes(bounds)
+		m.channels[channel] = o
+	}
+
+	return o
+}
+
+// observe records the outcome and latency of one notification on channel.
+func (m *Metrics) observe(channel string, err error, d time.Duration) {
+	m.totals().observe(err, d)
+	m.channel(channel).observe(err, d)
+}
+
+// observe records one notification in o.
+func (o *outcomes) observe(err error, d time.Duration) {
+	i := 0
+	for i < len(o.bounds) && d > o.bounds[i] {
+		i++
+	}
+	o.counts[i].Add(1)
+	o.sum.Add(int64(d))
+
+	if err != nil {
+		o.failed.Add(1)
+	} else {
+		o.sent.Add(1)
+	}
+}
+
+// snapshot copies o's counters.
+func (o *outcomes) snapshot(bounds []time.Duration) MetricsSnapshot {
+	counts := make([]int64, len(o.counts))
+	for i := range o.counts {
+		counts[i] = o.counts[i].Load()
+	}
+
+	return MetricsSnapshot{
+		Sent:   o.sent.Load(),
+		Failed: o.failed.Load(),
+		Bounds: bounds,
+		Counts: counts,
+		Sum:    time.Duration(o.sum.Load()),
+	}
+}
+
+// Snapshot returns a copy of the current counters. Each counter is read
+// atomically, but notifications finishing during the call may be counted
+// in some fields and not yet in others.
+func (m *Metrics) Snapshot() MetricsSnapshot {
+	total := m.totals()
+	bounds := slices.Clone(total.bounds)
+	snap := total.snapshot(bounds)
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if len(m.channels) > 0 {
+		snap.Channels = make(map[string]MetricsSnapshot, len(m.channels))
+		for channel, o := range m.channels {
+			snap.Channels[channel] = o.snapshot(bounds)
+		}
+	}
+
+	return snap
+}
+
+// MetricsNotifier records the outcome and latency of every call to the
+// embedded Notifier in Metrics.
+type MetricsNotifier struct {
+	Notifier
+	Metrics *Metrics
+}
+
+// Measure returns middleware that wraps notifiers in a MetricsNotifier
+// recording into m.
+func Measure(m *Metrics) Middleware {
+	return func(n Notifier) Notifier {
+		return &MetricsNotifier{Notifier: n, Metrics: m}
+	}
+}
+
+// Notify calls the embedded Notifier and records the outcome under its
+// channel.
+func (m *MetricsNotifier) Notify(ctx context.Context) error {
+	start := time.Now()
+	err := m.Notifier.Notify(ctx)
+	_, channel := AddressOf(m.Notifier)
+	m.Metrics.observe(channel, err, time.Since(start))
+
+	return err
+}
+
+// Unwrap returns the notifier being measured.
+func (m *MetricsNotifier) Unwrap() Notifier {
+	return m.Notifier
+}
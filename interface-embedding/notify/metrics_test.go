@@ -0,0 +1,99 @@
+package notify
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMetricsObserve(t *testing.T) {
+	var m Metrics
+	observations := []struct {
+		channel string
+		err     error
+		d       time.Duration
+	}{
+		{"email", nil, 500 * time.Microsecond},
+		{"email", errors.New("down"), 5 * time.Millisecond},
+		{"sms", nil, 50 * time.Millisecond},
+		{"sms", nil, 500 * time.Millisecond},
+		{"sms", errors.New("down"), time.Minute},
+	}
+	for _, o := range observations {
+		m.observe(o.channel, o.err, o.d)
+	}
+
+	tests := []struct {
+		name         string
+		snap         MetricsSnapshot
+		sent, failed int64
+		counts       []int64
+	}{
+		{"total", m.Snapshot(), 3, 2, []int64{1, 1, 1, 1, 1}},
+		{"email", m.Snapshot().Channels["email"], 1, 1, []int64{1, 1, 0, 0, 0}},
+		{"sms", m.Snapshot().Channels["sms"], 2, 1, []int64{0, 0, 1, 1, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.snap.Sent != tt.sent || tt.snap.Failed != tt.failed {
+				t.Errorf("Sent, Failed = %d, %d, want %d, %d", tt.snap.Sent, tt.snap.Failed, tt.sent, tt.failed)
+			}
+			if !slices.Equal(tt.snap.Counts, tt.counts) {
+				t.Errorf("Counts = %v, want %v", tt.snap.Counts, tt.counts)
+			}
+		})
+	}
+}
+
+func TestMetricsKeepBucketsFromFirstUse(t *testing.T) {
+	saved := DefaultBuckets
+	t.Cleanup(func() { DefaultBuckets = saved })
+
+	var m Metrics
+	m.observe("email", nil, time.Millisecond)
+
+	// Neither growing nor shrinking the defaults may change, or panic,
+	// a Metrics already in use.
+	DefaultBuckets = append(slices.Clone(saved), time.Minute, time.Hour)
+	m.observe("email", nil, time.Hour)
+	m.observe("sms", nil, time.Hour)
+	DefaultBuckets = DefaultBuckets[:1]
+	m.observe("push", nil, time.Hour)
+
+	snap := m.Snapshot()
+	if !slices.Equal(snap.Bounds, saved) {
+		t.Errorf("Bounds = %v, want %v", snap.Bounds, saved)
+	}
+	for channel, cs := range snap.Channels {
+		if len(cs.Counts) != len(saved)+1 {
+			t.Errorf("%s has %d buckets, want %d", channel, len(cs.Counts), len(saved)+1)
+		}
+	}
+	snap.Bounds[0] = 0
+	if m.Snapshot().Bounds[0] == 0 {
+		t.Error("changing a snapshot's Bounds changed the Metrics")
+	}
+}
+
+func TestMetricsConcurrent(t *testing.T) {
+	var m Metrics
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			channel := []string{"email", "sms"}[i%2]
+			for range 100 {
+				m.observe(channel, nil, time.Millisecond)
+				m.Snapshot()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if snap := m.Snapshot(); snap.Sent != 800 {
+		t.Errorf("Sent = %d, want 800", snap.Sent)
+	}
+}
@@ -0,0 +1,130 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrPanicked is wrapped by the error WithRecovery returns when a notifier
+// panics.
+var ErrPanicked = errors.New("notify: notifier panicked")
+
+// Middleware decorates a Notifier with behavior such as logging, timing,
+// or retries, without the notifier having to know about it.
+type Middleware func(Notifier) Notifier
+
+// Chain wraps n in the given middlewares. The first middleware is the
+// outermost, so it sees each call first and its result last.
+func Chain(n Notifier, mws ...Middleware) Notifier {
+	for i := len(mws) - 1; i >= 0; i-- {
+		n = mws[i](n)
+	}
+
+	return n
+}
+
+// LoggingNotifier writes one line per notification to Out, recording the
+// recipient, how long the embedded Notifier took, and any error. Email
+// recipients are masked with MaskEmail, including within the error.
+type LoggingNotifier struct {
+	Notifier
+	Out io.Writer
+
+	mu sync.Mutex
+}
+
+// Logging returns middleware that wraps notifiers in a LoggingNotifier
+// writing to w.
+func Logging(w io.Writer) Middleware {
+	return func(n Notifier) Notifier {
+		return &LoggingNotifier{Notifier: n, Out: w}
+	}
+}
+
+// Notify calls the embedded Notifier and logs the outcome.
+func (l *LoggingNotifier) Notify(ctx context.Context) error {
+	start := time.Now()
+	err := l.Notifier.Notify(ctx)
+	elapsed := time.Since(start)
+
+	recipient, channel := AddressOf(l.Notifier)
+	redacted := recipient
+	if strings.Contains(recipient, "@") {
+		redacted = MaskEmail(recipient)
+	}
+	msg := ""
+	if err != nil {
+		msg = strings.ReplaceAll(err.Error(), recipient, redacted)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	fmt.Fprintf(l.Out, "notify recipient=%s channel=%s duration=%s error=%q\n", redacted, channel, elapsed, msg)
+
+	return err
+}
+
+// Unwrap returns the notifier being logged.
+func (l *LoggingNotifier) Unwrap() Notifier {
+	return l.Notifier
+}
+
+// WithTiming is middleware that logs how long each notification took to
+// slog.Default. Email recipients are masked with MaskEmail.
+func WithTiming(n Notifier) Notifier {
+	return &timingNotifier{Notifier: n}
+}
+
+// timingNotifier is the Notifier returned by WithTiming.
+type timingNotifier struct {
+	Notifier
+}
+
+func (t *timingNotifier) Notify(ctx context.Context) error {
+	start := time.Now()
+	err := t.Notifier.Notify(ctx)
+
+	recipient, channel := AddressOf(t.Notifier)
+	if strings.Contains(recipient, "@") {
+		recipient = MaskEmail(recipient)
+	}
+	slog.Default().InfoContext(ctx, "notification timed",
+		"recipient", recipient, "channel", channel, "duration", time.Since(start))
+
+	return err
+}
+
+func (t *timingNotifier) Unwrap() Notifier {
+	return t.Notifier
+}
+
+// WithRecovery is middleware that turns a panic inside Notify into an
+// error wrapping ErrPanicked.
+func WithRecovery(n Notifier) Notifier {
+	return &recoveryNotifier{Notifier: n}
+}
+
+// recoveryNotifier is the Notifier returned by WithRecovery.
+type recoveryNotifier struct {
+	Notifier
+}
+
+func (r *recoveryNotifier) Notify(ctx context.Context) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = fmt.Errorf("%w: %v", ErrPanicked, v)
+		}
+	}()
+
+	return r.Notifier.Notify(ctx)
+}
+
+func (r *recoveryNotifier) Unwrap() Notifier {
+	return r.Notifier
+}
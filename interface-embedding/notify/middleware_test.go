@@ -0,0 +1,227 @@
+package notify_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+// tracing returns middleware that appends "name>" to trace before the
+// call and "<name" after it.
+func tracing(name string, trace *[]string) notify.Middleware {
+	return func(n notify.Notifier) notify.Notifier {
+		return notify.NotifierFunc(func(ctx context.Context) error {
+			*trace = append(*trace, name+">")
+			err := n.Notify(ctx)
+			*trace = append(*trace, "<"+name)
+			return err
+		})
+	}
+}
+
+func TestChainOrder(t *testing.T) {
+	var trace []string
+	inner := notify.NotifierFunc(func(context.Context) error {
+		trace = append(trace, "notify")
+		return nil
+	})
+
+	n := notify.Chain(inner, tracing("a", &trace), tracing("b", &trace), tracing("c", &trace))
+	if err := n.Notify(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "a> b> c> notify <c <b <a"
+	if got := strings.Join(trace, " "); got != want {
+		t.Errorf("trace = %q, want %q", got, want)
+	}
+}
+
+func TestLoggingNotifier(t *testing.T) {
+	var out bytes.Buffer
+	user, err := notify.NewUser("Janet", "janet@email.com", quiet(&out)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		n        notify.Notifier
+		want     []string
+		wantErr  bool
+		notWants []string
+	}{
+		{"success", user, []string{"recipient=j***t@email.com", "channel=email", "duration=", `error=""`}, false, []string{"janet@email.com"}},
+		{"failure", &notify.User{Name: "Janet", Email: "janet@"}, []string{"recipient=j***t@ ", "channel=email", "error=\"notify: email to "}, true, nil},
+		{"address in the error", &addressed{Notifier: &notify.MockNotifier{Err: errors.New("mailbox janet@email.com is full")}, to: user},
+			[]string{"recipient=j***t@email.com", `error="mailbox j***t@email.com is full"`}, true, []string{"janet@email.com"}},
+		{"admin", &notify.Admin{User: notify.User{Name: "Jo", Email: "jo@email.com"}, Level: notify.LevelRoot},
+			[]string{"recipient=j***@email.com", "channel=email"}, false, []string{"jo@email.com"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var log bytes.Buffer
+			err := notify.Chain(tt.n, notify.Logging(&log)).Notify(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Notify() = %v, want error %v", err, tt.wantErr)
+			}
+			line := log.String()
+			if strings.Count(line, "\n") != 1 {
+				t.Errorf("log = %q, want exactly one line", line)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(line, want) {
+					t.Errorf("log = %q, want it to contain %q", line, want)
+				}
+			}
+			for _, notWant := range tt.notWants {
+				if strings.Contains(line, notWant) {
+					t.Errorf("log = %q, leaks %q", line, notWant)
+				}
+			}
+		})
+	}
+}
+
+func TestMeasure(t *testing.T) {
+	var m notify.Metrics
+	mock := &notify.MockNotifier{Errs: []error{nil, errors.New("down"), nil, errors.New("down"), nil}}
+	n := notify.Chain(mock, notify.Measure(&m))
+	for range 5 {
+		n.Notify(context.Background())
+	}
+
+	snap := m.Snapshot()
+	if snap.Sent != 3 || snap.Failed != 2 {
+		t.Errorf("Sent, Failed = %d, %d, want 3, 2", snap.Sent, snap.Failed)
+	}
+	if len(snap.Counts) != len(snap.Bounds)+1 {
+		t.Fatalf("len(Counts) = %d, want len(Bounds)+1 = %d", len(snap.Counts), len(snap.Bounds)+1)
+	}
+	var total int64
+	for _, c := range snap.Counts {
+		total += c
+	}
+	if total != 5 {
+		t.Errorf("histogram holds %d observations, want 5", total)
+	}
+	if snap.Counts[0] != 5 {
+		t.Errorf("Counts = %v, want every instant call in the first bucket", snap.Counts)
+	}
+}
+
+func TestWithRecovery(t *testing.T) {
+	errDown := errors.New("down")
+	tests := []struct {
+		name      string
+		n         notify.Notifier
+		wantErr   error
+		wantPanic bool
+	}{
+		{"panic", notify.NotifierFunc(func(context.Context) error { panic("nil map") }), notify.ErrPanicked, true},
+		{"error", &notify.MockNotifier{Err: errDown}, errDown, false},
+		{"success", &notify.MockNotifier{}, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := notify.Chain(tt.n, notify.WithRecovery).Notify(context.Background())
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Notify() = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantPanic && !strings.Contains(err.Error(), "nil map") {
+				t.Errorf("Notify() = %v, want it to include the panic value", err)
+			}
+		})
+	}
+}
+
+func TestWithTiming(t *testing.T) {
+	h := &captureHandler{}
+	saved := slog.Default()
+	slog.SetDefault(slog.New(h))
+	t.Cleanup(func() { slog.SetDefault(saved) })
+
+	errDown := errors.New("down")
+	sms := &notify.SMSUser{Phone: "+14155552671"}
+	n := notify.Chain(notify.NotifierFunc(func(context.Context) error { return errDown }), notify.WithTiming)
+	if err := n.Notify(context.Background()); err != errDown {
+		t.Errorf("Notify() = %v, want %v unchanged", err, errDown)
+	}
+	sms.SetOutput(io.Discard)
+	if err := notify.Chain(sms, notify.WithTiming).Notify(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	u, err := notify.NewUser("Janet", "janet@email.com", notify.WithLogger(slog.New(slog.DiscardHandler)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	u.SetOutput(io.Discard)
+	if err := notify.Chain(u, notify.WithTiming).Notify(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	records := h.attrs()
+	if len(records) != 3 {
+		t.Fatalf("logged %d records, want 3", len(records))
+	}
+	if r := records[1]; r["@msg"] != "notification timed" || r["recipient"] != "+14155552671" || r["channel"] != "sms" || r["duration"] == "" {
+		t.Errorf("record = %v, want the recipient, channel and duration", r)
+	}
+	if r := records[2]; r["recipient"] != "j***t@email.com" || r["channel"] != "email" {
+		t.Errorf("record = %v, want the masked email recipient", r)
+	}
+}
+
+func TestMetricsNotifierConcurrent(t *testing.T) {
+	const goroutines, each = 8, 250
+	errDown := errors.New("server down")
+	email := &notify.User{Name: "Janet", Email: "janet@email.com"}
+	sms := &notify.SMSUser{User: *email, Phone: "+15555550100"}
+
+	var m notify.Metrics
+	ok := &notify.MetricsNotifier{Notifier: &addressed{Notifier: &notify.MockNotifier{}, to: email}, Metrics: &m}
+	failing := &notify.MetricsNotifier{Notifier: &notify.MockNotifier{Err: errDown}, Metrics: &m}
+	texts := &notify.MetricsNotifier{Notifier: &addressed{Notifier: &notify.MockNotifier{}, to: sms}, Metrics: &m}
+
+	var wg sync.WaitGroup
+	for i := range goroutines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n := []notify.Notifier{ok, failing, texts, ok}[i%4]
+			for range each {
+				n.Notify(context.Background())
+				m.Snapshot()
+			}
+		}()
+	}
+	wg.Wait()
+
+	snap := m.Snapshot()
+	perKind := int64(goroutines / 4 * each)
+	if snap.Sent != 3*perKind || snap.Failed != perKind {
+		t.Errorf("Sent, Failed = %d, %d, want %d, %d", snap.Sent, snap.Failed, 3*perKind, perKind)
+	}
+	var bucketed int64
+	for _, c := range snap.Counts {
+		bucketed += c
+	}
+	if bucketed != snap.Sent+snap.Failed {
+		t.Errorf("histogram holds %d observations, want %d", bucketed, snap.Sent+snap.Failed)
+	}
+
+	want := map[string][2]int64{"email": {2 * perKind, 0}, "sms": {perKind, 0}, "unknown": {0, perKind}}
+	for channel, counts := range want {
+		got := snap.Channels[channel]
+		if got.Sent != counts[0] || got.Failed != counts[1] {
+			t.Errorf("channel %s Sent, Failed = %d, %d, want %d, %d", channel, got.Sent, got.Failed, counts[0], counts[1])
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package notify
+
+import (
+	"context"
+	"sync"
+)
+
+// MockNotifier is a controllable Notifier for tests. The call number
+// (counting from 1) selects the error to return: Errs[n-1] when Errs is
+// long enough, otherwise Err. It is safe for concurrent use.
+type MockNotifier struct {
+	Name string
+	Err  error
+	Errs []error
+
+	// Order, when set, records Name each time the mock is called, so that
+	// several mocks sharing one CallOrder reveal the order they ran in.
+	Order *CallOrder
+
+	mu    sync.Mutex
+	calls int
+}
+
+// Notify records the call and returns the programmed error for it.
+func (m *MockNotifier) Notify(ctx context.Context) error {
+	m.mu.Lock()
+	m.calls++
+	n := m.calls
+	err := m.Err
+	if n <= len(m.Errs) {
+		err = m.Errs[n-1]
+	}
+	m.mu.Unlock()
+
+	if m.Order != nil {
+		m.Order.record(m.Name)
+	}
+
+	return err
+}
+
+// Calls returns the number of times Notify has been called.
+func (m *MockNotifier) Calls() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.calls
+}
+
+// Reset clears the call count so the programmed errors start over.
+func (m *MockNotifier) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls = 0
+}
+
+// CallOrder records the names of mocks as they are called.
+type CallOrder struct {
+	mu    sync.Mutex
+	names []string
+}
+
+// Names returns the recorded names, oldest first.
+func (o *CallOrder) Names() []string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return append([]string(nil), o.names...)
+}
+
+func (o *CallOrder) record(name string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.names = append(o.names, name)
+}
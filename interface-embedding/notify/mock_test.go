@@ -0,0 +1,74 @@
+package notify_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+func TestMockNotifier(t *testing.T) {
+	errFirst, errThird, errDefault := errors.New("first"), errors.New("third"), errors.New("default")
+	tests := []struct {
+		name string
+		mock *notify.MockNotifier
+		want []error
+	}{
+		{"succeeds", &notify.MockNotifier{}, []error{nil, nil}},
+		{"always fails", &notify.MockNotifier{Err: errDefault}, []error{errDefault, errDefault}},
+		{"sequence", &notify.MockNotifier{Errs: []error{errFirst, nil, errThird}}, []error{errFirst, nil, errThird, nil}},
+		{"sequence then default", &notify.MockNotifier{Err: errDefault, Errs: []error{nil, errFirst}}, []error{nil, errFirst, errDefault}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i, want := range tt.want {
+				if err := tt.mock.Notify(context.Background()); err != want {
+					t.Errorf("call %d = %v, want %v", i+1, err, want)
+				}
+			}
+			if tt.mock.Calls() != len(tt.want) {
+				t.Errorf("Calls() = %d, want %d", tt.mock.Calls(), len(tt.want))
+			}
+
+			tt.mock.Reset()
+			if tt.mock.Calls() != 0 {
+				t.Errorf("Calls() after Reset = %d, want 0", tt.mock.Calls())
+			}
+			if err := tt.mock.Notify(context.Background()); err != tt.want[0] {
+				t.Errorf("first call after Reset = %v, want %v", err, tt.want[0])
+			}
+		})
+	}
+}
+
+func TestCallOrder(t *testing.T) {
+	var order notify.CallOrder
+	a := &notify.MockNotifier{Name: "a", Order: &order}
+	b := &notify.MockNotifier{Name: "b", Order: &order}
+	notify.SendNotifications(context.Background(), b, a, b)
+
+	if got, want := order.Names(), []string{"b", "a", "b"}; !slices.Equal(got, want) {
+		t.Errorf("Names() = %v, want %v", got, want)
+	}
+}
+
+func TestMockNotifierConcurrent(t *testing.T) {
+	var order notify.CallOrder
+	m := &notify.MockNotifier{Name: "m", Order: &order}
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.Notify(context.Background())
+		}()
+	}
+	wg.Wait()
+
+	if m.Calls() != 20 || len(order.Names()) != 20 {
+		t.Errorf("Calls() = %d and %d names recorded, want 20", m.Calls(), len(order.Names()))
+	}
+}
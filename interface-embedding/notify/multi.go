@@ -0,0 +1,13 @@
+package notify
+
+import "context"
+
+// MultiNotifier fans a single notification out to every member, such as
+// the email and SMS channels of the same person.
+type MultiNotifier []Notifier
+
+// Notify calls every member, even after one fails, and returns the
+// failures as a *BatchError.
+func (m MultiNotifier) Notify(ctx context.Context) error {
+	return SendNotifications(ctx, m...)
+}
@@ -0,0 +1,59 @@
+package notify_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+func TestMultiNotifier(t *testing.T) {
+	errSMS := errors.New("sms gateway down")
+	errPush := errors.New("push token expired")
+
+	tests := []struct {
+		name    string
+		errs    []error
+		wantErr []error
+	}{
+		{"all succeed", []error{nil, nil, nil}, nil},
+		{"mixed", []error{nil, errSMS, nil, errPush, nil}, []error{errSMS, errPush}},
+		{"all fail", []error{errSMS, errPush}, []error{errSMS, errPush}},
+		{"empty", nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var m notify.MultiNotifier
+			calls := make([]int, len(tt.errs))
+			for i, err := range tt.errs {
+				m = append(m, notify.NotifierFunc(func(context.Context) error {
+					calls[i]++
+					return err
+				}))
+			}
+
+			err := m.Notify(context.Background())
+			for i, n := range calls {
+				if n != 1 {
+					t.Errorf("member %d called %d times, want 1", i, n)
+				}
+			}
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Errorf("Notify() = %v, want nil", err)
+				}
+				return
+			}
+			for _, want := range tt.wantErr {
+				if !errors.Is(err, want) {
+					t.Errorf("Notify() = %v, want it to match %v", err, want)
+				}
+				if err != nil && !strings.Contains(err.Error(), want.Error()) {
+					t.Errorf("Notify() message %q doesn't mention %q", err, want)
+				}
+			}
+		})
+	}
+}
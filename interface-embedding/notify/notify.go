@@ -0,0 +1,96 @@
+// Package notify demonstrates methods, interfaces, and embedded types
+// through a small notification API that other examples can import.
+package notify
+
+import "context"
+
+// Notifier is implemented by anything that can deliver a notification.
+// Implementations should return ctx.Err() instead of doing any work once
+// the context is cancelled or its deadline has passed.
+type Notifier interface {
+	Notify(ctx context.Context) error
+}
+
+// NotifierFunc adapts a plain function to the Notifier interface, in the
+// same way http.HandlerFunc adapts a function to http.Handler.
+type NotifierFunc func(ctx context.Context) error
+
+// Notify calls f.
+func (f NotifierFunc) Notify(ctx context.Context) error {
+	return f(ctx)
+}
+
+// Addresser is implemented by notifiers that can report who they deliver
+// to and over which channel, such as "janet@email.com" and "email".
+type Addresser interface {
+	Address() (recipient, channel string)
+}
+
+// Wrapper is implemented by notifiers that decorate another Notifier, so
+// that helpers can look through them to the notifier doing the delivery.
+type Wrapper interface {
+	Unwrap() Notifier
+}
+
+// AddressOf reports the recipient and channel of n, looking through any
+// wrappers. Notifiers that don't implement Addresser are reported as
+// "unknown".
+func AddressOf(n Notifier) (recipient, channel string) {
+	for n != nil {
+		if a, ok := n.(Addresser); ok {
+			return a.Address()
+		}
+		w, ok := n.(Wrapper)
+		if !ok {
+			break
+		}
+		n = w.Unwrap()
+	}
+
+	return "unknown", "unknown"
+}
+
+// SendNotification delivers a notification through any Notifier. A context
+// that is already done is reported without calling n at all, so nothing is
+// sent even by implementations that forget to check ctx themselves. Every
+// call is published to DefaultBus.
+func SendNotification(ctx context.Context, n Notifier) error {
+	err := ctx.Err()
+	if err == nil {
+		err = n.Notify(ctx)
+	}
+	DefaultBus.published(n, err)
+
+	return err
+}
+
+// NotifyAsync sends through n in a new goroutine, as SendNotification
+// does, and returns a channel that delivers the result exactly once.
+// The channel is buffered, so callers can receive from it whenever they
+// like, or drop it, without leaking the goroutine.
+func NotifyAsync(ctx context.Context, n Notifier) <-chan error {
+	result := make(chan error, 1)
+	go func() {
+		result <- SendNotification(ctx, n)
+	}()
+
+	return result
+}
+
+// SendNotifications delivers through every notifier, continuing past
+// failures. The failures are returned as a *BatchError, which errors.Is and
+// errors.As search for any one of them.
+func SendNotifications(ctx context.Context, ns ...Notifier) error {
+	var failed []RecipientError
+	for _, n := range ns {
+		if err := SendNotification(ctx, n); err != nil {
+			recipient, _ := AddressOf(n)
+			failed = append(failed, RecipientError{Recipient: recipient, Err: err})
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+
+	return &BatchError{errs: failed}
+}
@@ -0,0 +1,341 @@
+package notify_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+func TestNotifierFuncPropagatesError(t *testing.T) {
+	sentinel := errors.New("boom")
+	wrapped := fmt.Errorf("send: %w", sentinel)
+
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"nil", nil},
+		{"sentinel", sentinel},
+		{"wrapped", wrapped},
+		{"notification error", &notify.NotificationError{Recipient: "a@b.c", Channel: "email", Err: sentinel}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			f := notify.NotifierFunc(func(ctx context.Context) error {
+				calls++
+				return tt.err
+			})
+
+			if err := notify.SendNotification(context.Background(), f); err != tt.err {
+				t.Errorf("SendNotification() = %v, want %v unchanged", err, tt.err)
+			}
+			if calls != 1 {
+				t.Errorf("func called %d times, want 1", calls)
+			}
+		})
+	}
+}
+
+func TestSendNotificationDeadline(t *testing.T) {
+	var buf bytes.Buffer
+	u, err := notify.NewUser("Janet Jones", "janet@email.com", quiet(&buf)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := notify.NewAdmin("Lisa Smith", "lisa@email.com", notify.WithWriter(&buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		n    notify.Notifier
+	}{
+		{"user", u},
+		{"admin", a},
+		{"func", notify.NotifierFunc(func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+			defer cancel()
+			<-ctx.Done()
+
+			if err := notify.SendNotification(ctx, tt.n); !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("SendNotification() = %v, want %v", err, context.DeadlineExceeded)
+			}
+		})
+	}
+	if buf.Len() != 0 {
+		t.Errorf("expired notifications wrote %q", buf.String())
+	}
+}
+
+func TestSendNotifications(t *testing.T) {
+	errDown := errors.New("server down")
+	ok1 := &notify.MockNotifier{}
+	failing := &notify.MockNotifier{Err: &notify.NotificationError{Recipient: "bob@email.com", Channel: "email", Err: errDown}}
+	ok2 := &notify.MockNotifier{}
+
+	err := notify.SendNotifications(context.Background(), ok1, failing, ok2)
+	for i, m := range []*notify.MockNotifier{ok1, failing, ok2} {
+		if m.Calls() != 1 {
+			t.Errorf("notifier %d called %d times, want 1", i, m.Calls())
+		}
+	}
+
+	if !errors.Is(err, errDown) {
+		t.Errorf("SendNotifications() = %v, want it to match %v", err, errDown)
+	}
+	var ne *notify.NotificationError
+	if !errors.As(err, &ne) || ne.Recipient != "bob@email.com" {
+		t.Errorf("errors.As found %+v, want the failing notifier's error", ne)
+	}
+	var be *notify.BatchError
+	if !errors.As(err, &be) || len(be.Errors()) != 1 {
+		t.Fatalf("SendNotifications() = %v, want a *BatchError with one failure", err)
+	}
+
+	if err := notify.SendNotifications(context.Background(), ok1, ok2); err != nil {
+		t.Errorf("SendNotifications() with no failures = %v, want nil", err)
+	}
+}
+
+// Sample results on an Intel Xeon, go test -bench . -benchmem:
+//
+//	BenchmarkUserNotify                            	  253 ns/op	    0 B/op	   0 allocs/op
+//	BenchmarkAdminNotify                           	  314 ns/op	    0 B/op	   0 allocs/op
+//	BenchmarkSendNotificationInterface/user/interface	  283 ns/op	    0 B/op	   0 allocs/op
+//	BenchmarkSendNotificationInterface/user/send   	  304 ns/op	    0 B/op	   0 allocs/op
+//	BenchmarkSendNotificationInterface/admin/interface	  273 ns/op	    0 B/op	   0 allocs/op
+//	BenchmarkSendNotificationInterface/admin/send  	  290 ns/op	    0 B/op	   0 allocs/op
+//	BenchmarkSendNotification                      	  286 ns/op	    0 B/op	   0 allocs/op
+//	BenchmarkBroadcast1000                         	  278950 ns/op	   44 B/op	   1 allocs/op
+//	BenchmarkConcurrentFanout                      	  924685 ns/op	236066 B/op	4016 allocs/op
+//
+// Calling through the interface or SendNotification costs no more than a
+// direct call. Messages are built in pooled buffers, so sending doesn't
+// allocate; the fan-out cost is SendConcurrent's per-notifier bookkeeping.
+
+// discardUser and discardAdmin build notifiers whose output and logs go
+// nowhere, so benchmarks measure the call rather than the I/O.
+func discardUser(tb testing.TB) *notify.User {
+	u, err := notify.NewUser("Janet Jones", "janet@email.com",
+		notify.WithWriter(io.Discard), notify.WithLogger(slog.New(slog.DiscardHandler)))
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	return u
+}
+
+func discardAdmin(tb testing.TB) *notify.Admin {
+	a, err := notify.NewAdmin("Lisa Smith", "lisa@email.com",
+		notify.WithWriter(io.Discard), notify.WithLogger(slog.New(slog.DiscardHandler)))
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	return a
+}
+
+func BenchmarkUserNotify(b *testing.B) {
+	u := discardUser(b)
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	for b.Loop() {
+		u.Notify(ctx)
+	}
+}
+
+func BenchmarkAdminNotify(b *testing.B) {
+	a := discardAdmin(b)
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	for b.Loop() {
+		a.Notify(ctx)
+	}
+}
+
+func BenchmarkSendNotificationInterface(b *testing.B) {
+	ctx := context.Background()
+
+	for _, bb := range []struct {
+		name string
+		n    notify.Notifier
+	}{
+		{"user", discardUser(b)},
+		{"admin", discardAdmin(b)},
+	} {
+		b.Run(bb.name+"/interface", func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				bb.n.Notify(ctx)
+			}
+		})
+		b.Run(bb.name+"/send", func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				notify.SendNotification(ctx, bb.n)
+			}
+		})
+	}
+}
+
+func BenchmarkSendNotification(b *testing.B) {
+	u := discardUser(b)
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	for b.Loop() {
+		notify.SendNotification(ctx, u)
+	}
+}
+
+// discardUsers builds n distinct users for the broadcast benchmarks.
+func discardUsers(tb testing.TB, n int) []notify.Notifier {
+	ns := make([]notify.Notifier, n)
+	for i := range ns {
+		u, err := notify.NewUser(fmt.Sprintf("User %d", i), fmt.Sprintf("user%d@email.com", i),
+			notify.WithWriter(io.Discard), notify.WithLogger(slog.New(slog.DiscardHandler)))
+		if err != nil {
+			tb.Fatal(err)
+		}
+		ns[i] = u
+	}
+
+	return ns
+}
+
+func BenchmarkBroadcast1000(b *testing.B) {
+	ns := discardUsers(b, 1000)
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if err := notify.SendNotifications(ctx, ns...); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkConcurrentFanout(b *testing.B) {
+	ns := discardUsers(b, 1000)
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if err := notify.SendConcurrent(ctx, 8, ns); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestNotifyAllocs(t *testing.T) {
+	ctx := context.Background()
+	u, a := discardUser(t), discardAdmin(t)
+	literal := &notify.User{Name: "Bill", Email: "bill@email.com"}
+	literal.SetOutput(io.Discard)
+	if err := notify.WithLogger(slog.New(slog.DiscardHandler))(literal); err != nil {
+		t.Fatal(err)
+	}
+	clone := u.Clone()
+	clone.Name = "Janet Smith"
+	var sends int
+
+	tests := []struct {
+		name string
+		send func() error
+	}{
+		{"user", func() error { return u.Notify(ctx) }},
+		{"admin", func() error { return a.Notify(ctx) }},
+		{"through SendNotification", func() error { return notify.SendNotification(ctx, u) }},
+		{"user literal", func() error { return literal.Notify(ctx) }},
+		{"clones in turn", func() error {
+			sends++
+			if sends%2 == 0 {
+				return clone.Notify(ctx)
+			}
+			return u.Notify(ctx)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.send(); err != nil {
+				t.Fatal(err)
+			}
+			if allocs := testing.AllocsPerRun(100, func() { tt.send() }); allocs != 0 {
+				t.Errorf("Notify() allocates %v times per call, want 0", allocs)
+			}
+		})
+	}
+}
+
+func TestNotifyAsync(t *testing.T) {
+	checkNoLeaks(t)
+	errDown := errors.New("server down")
+	release := make(chan struct{})
+	blocked := notify.NotifierFunc(func(context.Context) error {
+		<-release
+		return nil
+	})
+
+	ns := []notify.Notifier{
+		&notify.MockNotifier{},
+		&notify.MockNotifier{Err: errDown},
+		blocked,
+		&notify.MockNotifier{},
+	}
+	results := make([]<-chan error, len(ns))
+	for i, n := range ns {
+		results[i] = notify.NotifyAsync(context.Background(), n)
+	}
+	// The blocked send doesn't hold up the others.
+	for _, i := range []int{0, 1, 3} {
+		select {
+		case err := <-results[i]:
+			if want := ns[i].(*notify.MockNotifier).Err; !errors.Is(err, want) {
+				t.Errorf("result %d = %v, want %v", i, err, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("result %d never arrived", i)
+		}
+	}
+	close(release)
+	if err := <-results[2]; err != nil {
+		t.Errorf("result 2 = %v, want nil", err)
+	}
+
+	// Each channel delivers once and is then left empty.
+	for i, r := range results {
+		select {
+		case err := <-r:
+			t.Errorf("result %d delivered twice, second %v", i, err)
+		default:
+		}
+	}
+}
+
+func TestNotifyAsyncDropped(t *testing.T) {
+	// checkNoLeaks fails if the send goroutines are stuck on a channel
+	// nobody reads from.
+	checkNoLeaks(t)
+	mock := &notify.MockNotifier{Err: errors.New("server down")}
+	for range 10 {
+		notify.NotifyAsync(context.Background(), mock)
+	}
+	waitFor(t, "the dropped sends", func() bool { return mock.Calls() == 10 })
+}
@@ -0,0 +1,87 @@
+// Package notifytest provides test doubles for code that accepts a
+// notify.Notifier. Every double is safe for concurrent use.
+package notifytest
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+// Call describes one call made to a Recorder.
+type Call struct {
+	Time time.Time
+}
+
+// Recorder is a Notifier that remembers every call made to it. Err, when
+// set, is returned from each call. The zero value records calls and
+// succeeds.
+type Recorder struct {
+	Err error
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+var _ notify.Notifier = (*Recorder)(nil)
+
+// Notify records the call and returns r.Err.
+func (r *Recorder) Notify(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls = append(r.calls, Call{Time: time.Now()})
+	return r.Err
+}
+
+// Calls returns a copy of the calls recorded so far, oldest first.
+func (r *Recorder) Calls() []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]Call(nil), r.calls...)
+}
+
+// Count returns the number of calls recorded so far.
+func (r *Recorder) Count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return len(r.calls)
+}
+
+// Failing returns a Notifier that always fails with err.
+func Failing(err error) notify.Notifier {
+	return notify.NotifierFunc(func(context.Context) error {
+		return err
+	})
+}
+
+// Delay returns a Notifier that waits d before calling inner. If ctx is
+// done first it returns ctx.Err() without calling inner.
+func Delay(d time.Duration, inner notify.Notifier) notify.Notifier {
+	return notify.NotifierFunc(func(ctx context.Context) error {
+		t := time.NewTimer(d)
+		defer t.Stop()
+
+		select {
+		case <-t.C:
+			return inner.Notify(ctx)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+}
+
+// AssertNotified reports a test failure unless rec was called exactly
+// times times.
+func AssertNotified(t testing.TB, rec *Recorder, times int) {
+	t.Helper()
+
+	if got := rec.Count(); got != times {
+		t.Errorf("notifier called %d times, want %d", got, times)
+	}
+}
@@ -0,0 +1,119 @@
+package notifytest_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+	"ultimate-golang-reference/interface-embedding/notify/notifytest"
+)
+
+func TestSendNotificationWithRecorder(t *testing.T) {
+	errDown := errors.New("down")
+	tests := []struct {
+		name    string
+		err     error
+		ctx     func() context.Context
+		want    int
+		wantErr error
+	}{
+		{"success", nil, context.Background, 1, nil},
+		{"failure", errDown, context.Background, 1, errDown},
+		{"cancelled", nil, func() context.Context {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			return ctx
+		}, 0, context.Canceled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &notifytest.Recorder{Err: tt.err}
+			if err := notify.SendNotification(tt.ctx(), rec); !errors.Is(err, tt.wantErr) {
+				t.Errorf("SendNotification() = %v, want %v", err, tt.wantErr)
+			}
+			notifytest.AssertNotified(t, rec, tt.want)
+		})
+	}
+}
+
+func TestRecorderConcurrent(t *testing.T) {
+	var rec notifytest.Recorder
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rec.Notify(context.Background())
+			rec.Calls()
+		}()
+	}
+	wg.Wait()
+
+	calls := rec.Calls()
+	if len(calls) != 50 {
+		t.Fatalf("recorded %d calls, want 50", len(calls))
+	}
+	for i, c := range calls {
+		if c.Time.IsZero() {
+			t.Errorf("call %d has no timestamp", i)
+		}
+	}
+}
+
+func TestFailing(t *testing.T) {
+	err := errors.New("boom")
+	if got := notifytest.Failing(err).Notify(context.Background()); got != err {
+		t.Errorf("Notify() = %v, want %v", got, err)
+	}
+}
+
+func TestDelay(t *testing.T) {
+	t.Run("waits then calls inner", func(t *testing.T) {
+		var rec notifytest.Recorder
+		start := time.Now()
+		if err := notifytest.Delay(20*time.Millisecond, &rec).Notify(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+			t.Errorf("returned after %v, want at least 20ms", elapsed)
+		}
+		notifytest.AssertNotified(t, &rec, 1)
+	})
+
+	t.Run("gives up when ctx is done", func(t *testing.T) {
+		var rec notifytest.Recorder
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		if err := notifytest.Delay(time.Hour, &rec).Notify(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Notify() = %v, want %v", err, context.DeadlineExceeded)
+		}
+		notifytest.AssertNotified(t, &rec, 0)
+	})
+}
+
+// recordingTB captures the failures reported through it.
+type recordingTB struct {
+	testing.TB
+	failures []string
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) Errorf(format string, args ...any) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func TestAssertNotifiedReportsMismatch(t *testing.T) {
+	var rec notifytest.Recorder
+	rec.Notify(context.Background())
+
+	tb := &recordingTB{TB: t}
+	notifytest.AssertNotified(tb, &rec, 2)
+	if len(tb.failures) != 1 || tb.failures[0] != "notifier called 1 times, want 2" {
+		t.Errorf("failures = %q, want one count mismatch", tb.failures)
+	}
+}
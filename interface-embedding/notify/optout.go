@@ -0,0 +1,100 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrOptedOut is returned by an OptOutNotifier when the recipient has
+// opted out of notifications.
+var ErrOptedOut = errors.New("notify: recipient has opted out")
+
+// OptOutRegistry is the set of email addresses that must not be notified.
+// Addresses are compared after NormalizeEmail, so case in the domain
+// doesn't matter. The zero value is empty and safe for concurrent use.
+type OptOutRegistry struct {
+	mu     sync.RWMutex
+	emails map[string]struct{}
+}
+
+// OptOut adds email to the registry.
+func (r *OptOutRegistry) OptOut(email string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.emails == nil {
+		r.emails = make(map[string]struct{})
+	}
+	r.emails[optOutKey(email)] = struct{}{}
+}
+
+// OptIn removes email from the registry.
+func (r *OptOutRegistry) OptIn(email string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.emails, optOutKey(email))
+}
+
+// IsOptedOut reports whether email is in the registry.
+func (r *OptOutRegistry) IsOptedOut(email string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, ok := r.emails[optOutKey(email)]
+	return ok
+}
+
+// optOutKey is the form email is stored under: normalized when it parses,
+// and as given otherwise.
+func optOutKey(email string) string {
+	if normalized, err := NormalizeEmail(email); err == nil {
+		return normalized
+	}
+
+	return email
+}
+
+// OptOutNotifier refuses to send through the embedded Notifier when its
+// recipient has opted out.
+type OptOutNotifier struct {
+	Notifier
+
+	registry *OptOutRegistry
+}
+
+// NewOptOutNotifier wraps n so that it checks r before every send. The
+// recipient is identified by RecipientID, found through any wrappers, so
+// a User and the User embedded in an Admin or SMSUser are treated alike.
+// Notifiers that don't identify their recipient, such as a WebhookNotifier,
+// are checked by the recipient AddressOf reports instead.
+func NewOptOutNotifier(n Notifier, r *OptOutRegistry) *OptOutNotifier {
+	return &OptOutNotifier{Notifier: n, registry: r}
+}
+
+// Notify returns ErrOptedOut, wrapped in a NotificationError, without
+// sending when the recipient has opted out, and sends otherwise. Opting
+// out must be honoured, so a notifier whose recipient can't be found at
+// all is refused as though it had opted out.
+func (o *OptOutNotifier) Notify(ctx context.Context) error {
+	recipient, channel := AddressOf(o.Notifier)
+	id, ok := recipientID(o.Notifier)
+	if !ok {
+		id = recipient
+	}
+	if id == "unknown" {
+		return &NotificationError{Recipient: recipient, Channel: channel, Err: fmt.Errorf("%w: no recipient to check", ErrOptedOut)}
+	}
+	if o.registry.IsOptedOut(id) {
+		return &NotificationError{Recipient: recipient, Channel: channel, Err: ErrOptedOut}
+	}
+
+	return o.Notifier.Notify(ctx)
+}
+
+// Unwrap returns the notifier being guarded.
+func (o *OptOutNotifier) Unwrap() Notifier {
+	return o.Notifier
+}
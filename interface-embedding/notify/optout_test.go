@@ -0,0 +1,199 @@
+package notify_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+func TestOptOutNotifier(t *testing.T) {
+	var buf bytes.Buffer
+	janet, err := notify.NewUser("Janet Jones", "janet@email.com", quiet(&buf)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lisa, err := notify.NewAdmin("Lisa Smith", "lisa@email.com", quietAdmin(&buf)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sms := &notify.SMSUser{User: *janet.Clone(), Phone: "+15555550100"}
+
+	tests := []struct {
+		name     string
+		n        notify.Notifier
+		optedOut []string
+		wantErr  bool
+		wantOut  string
+	}{
+		{"user sent", janet, []string{"bob@email.com"}, false, "Sending user email to Janet Jones<janet@email.com>\n"},
+		{"user opted out", janet, []string{"janet@email.com"}, true, ""},
+		{"domain case ignored", janet, []string{"janet@EMAIL.com"}, true, ""},
+		{"admin sent", lisa, nil, false, "Sending admin email to Lisa Smith<lisa@email.com> with level normal\n"},
+		{"admin opted out", lisa, []string{"lisa@email.com"}, true, ""},
+		{"sms opted out by email", sms, []string{"janet@email.com"}, true, ""},
+		{"wrapped user opted out", &notify.TimeoutNotifier{Notifier: janet}, []string{"janet@email.com"}, true, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+			var r notify.OptOutRegistry
+			for _, email := range tt.optedOut {
+				r.OptOut(email)
+			}
+
+			err := notify.NewOptOutNotifier(tt.n, &r).Notify(context.Background())
+			if tt.wantErr != errors.Is(err, notify.ErrOptedOut) {
+				t.Errorf("Notify() = %v, want ErrOptedOut %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("Notify() = %v, want nil", err)
+			}
+			if got := buf.String(); got != tt.wantOut {
+				t.Errorf("output = %q, want %q", got, tt.wantOut)
+			}
+		})
+	}
+}
+
+func TestOptOutNotifierUnidentified(t *testing.T) {
+	tests := []struct {
+		name     string
+		to       notify.Addresser // nil for a notifier with no address at all
+		optedOut []string
+		wantSent bool
+	}{
+		{"addressed, not opted out", &notify.User{Email: "bob@email.com"}, []string{"janet@email.com"}, true},
+		{"addressed, opted out", &notify.User{Email: "janet@email.com"}, []string{"janet@email.com"}, false},
+		{"no recipient fails closed", nil, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var r notify.OptOutRegistry
+			for _, email := range tt.optedOut {
+				r.OptOut(email)
+			}
+			m := &notify.MockNotifier{}
+			var n notify.Notifier = m
+			if tt.to != nil {
+				n = &addressed{Notifier: m, to: tt.to}
+			}
+
+			err := notify.NewOptOutNotifier(n, &r).Notify(context.Background())
+			if sent := m.Calls() == 1; sent != tt.wantSent {
+				t.Errorf("sent = %v, want %v", sent, tt.wantSent)
+			}
+			if tt.wantSent != (err == nil) || (err != nil && !errors.Is(err, notify.ErrOptedOut)) {
+				t.Errorf("Notify() = %v, want ErrOptedOut %v", err, !tt.wantSent)
+			}
+		})
+	}
+}
+
+func TestOptOutNotifierChannels(t *testing.T) {
+	to := &notify.User{Name: "Janet Jones", Email: "janet@email.com"}
+
+	tests := []struct {
+		name     string
+		optedOut bool
+	}{
+		{"opted out", true},
+		{"not opted out", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var r notify.OptOutRegistry
+			if tt.optedOut {
+				r.OptOut("janet@EMAIL.com")
+			}
+
+			srv, hits := webhookServer(t, http.StatusNoContent)
+			webhook := &notify.WebhookNotifier{URL: srv.URL, To: to, Client: srv.Client()}
+			err := notify.NewOptOutNotifier(webhook, &r).Notify(context.Background())
+			if tt.optedOut != errors.Is(err, notify.ErrOptedOut) || (!tt.optedOut && err != nil) {
+				t.Errorf("webhook Notify() = %v, want ErrOptedOut %v", err, tt.optedOut)
+			}
+			want := int32(1)
+			if tt.optedOut {
+				want = 0
+			}
+			if got := hits.Load(); got != want {
+				t.Errorf("webhook got %d requests, want %d", got, want)
+			}
+
+			mail := startFakeSMTP(t, &fakeSMTP{})
+			smtp := &notify.SMTPNotifier{Addr: mail.addr, From: "alerts@email.com", To: to, Subject: "Disk full", Body: "Disk full.", Clock: newFakeClock()}
+			err = notify.NewOptOutNotifier(smtp, &r).Notify(context.Background())
+			if tt.optedOut != errors.Is(err, notify.ErrOptedOut) || (!tt.optedOut && err != nil) {
+				t.Errorf("smtp Notify() = %v, want ErrOptedOut %v", err, tt.optedOut)
+			}
+			if _, data := mail.session(); tt.optedOut != (data == "") {
+				t.Errorf("smtp server got message %q, want opted out %v", data, tt.optedOut)
+			}
+		})
+	}
+}
+
+func TestOptOutRegistry(t *testing.T) {
+	var r notify.OptOutRegistry
+	if r.IsOptedOut("janet@email.com") {
+		t.Error("IsOptedOut() = true on an empty registry")
+	}
+	r.OptIn("janet@email.com")
+
+	r.OptOut("Janet@Email.COM")
+	if !r.IsOptedOut("Janet@email.com") {
+		t.Error("IsOptedOut() = false after OptOut with a differently cased domain")
+	}
+	if r.IsOptedOut("janet@email.com") {
+		t.Error("IsOptedOut() = true for a different local part")
+	}
+	r.OptIn("Janet@EMAIL.com")
+	if r.IsOptedOut("Janet@email.com") {
+		t.Error("IsOptedOut() = true after OptIn")
+	}
+
+	r.OptOut("not an email")
+	if !r.IsOptedOut("not an email") {
+		t.Error("IsOptedOut() = false for an unparseable address stored as given")
+	}
+}
+
+func TestOptOutConcurrent(t *testing.T) {
+	var r notify.OptOutRegistry
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(2)
+		email := fmt.Sprintf("user%d@email.com", i)
+		go func() {
+			defer wg.Done()
+			for range 50 {
+				r.OptOut(email)
+				r.OptIn(email)
+			}
+			r.OptOut(email)
+		}()
+		go func() {
+			defer wg.Done()
+			n := notify.NewOptOutNotifier(&identified{Notifier: &notify.MockNotifier{}, id: email}, &r)
+			for range 50 {
+				r.IsOptedOut(email)
+				if err := n.Notify(context.Background()); err != nil && !errors.Is(err, notify.ErrOptedOut) {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i := range 8 {
+		if email := fmt.Sprintf("user%d@email.com", i); !r.IsOptedOut(email) {
+			t.Errorf("IsOptedOut(%q) = false, want true after the last OptOut", email)
+		}
+	}
+}
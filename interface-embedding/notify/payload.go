@@ -0,0 +1,62 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+)
+
+// PayloadNotifier delivers notifications that carry a typed payload, such
+// as an invoice or an alert. It sits alongside Notifier rather than
+// replacing it.
+type PayloadNotifier[T any] interface {
+	NotifyPayload(ctx context.Context, p T) error
+}
+
+// SendTyped delivers p through n. The compiler rejects payloads of the
+// wrong type for n.
+func SendTyped[T any](ctx context.Context, n PayloadNotifier[T], p T) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return n.NotifyPayload(ctx, p)
+}
+
+// EmailNotifier emails payloads of type T to a user, using Format to turn
+// each payload into the message body. Out defaults to os.Stdout.
+type EmailNotifier[T any] struct {
+	To     *User
+	Format func(T) string
+	Out    io.Writer
+}
+
+// NewStringerEmail returns an EmailNotifier whose message body comes from
+// the payload's own String method.
+func NewStringerEmail[T fmt.Stringer](to *User) *EmailNotifier[T] {
+	return &EmailNotifier[T]{
+		To:     to,
+		Format: func(p T) string { return p.String() },
+	}
+}
+
+// NotifyPayload emails the formatted payload.
+func (e *EmailNotifier[T]) NotifyPayload(ctx context.Context, p T) error {
+	if err := ctx.Err(); err != nil {
+		return &NotificationError{Recipient: e.To.Email, Channel: "email", Err: err}
+	}
+
+	body := fmt.Sprint(p)
+	if e.Format != nil {
+		body = e.Format(p)
+	}
+
+	out := e.Out
+	if out == nil {
+		out = os.Stdout
+	}
+	_, err := fmt.Fprintf(out, "Sending user email to %s<%s>: %s\n", e.To.Name, e.To.Email, body)
+
+	return err
+}
@@ -0,0 +1,105 @@
+package notify_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+type invoice struct {
+	number string
+	cents  int
+}
+
+type alert struct {
+	service string
+	down    bool
+}
+
+func (a alert) String() string {
+	state := "up"
+	if a.down {
+		state = "down"
+	}
+	return a.service + " is " + state
+}
+
+func TestSendTyped(t *testing.T) {
+	to := &notify.User{Name: "Janet", Email: "janet@email.com"}
+	var buf bytes.Buffer
+
+	invoices := &notify.EmailNotifier[invoice]{
+		To:     to,
+		Out:    &buf,
+		Format: func(inv invoice) string { return fmt.Sprintf("invoice %s for %d cents", inv.number, inv.cents) },
+	}
+	alerts := notify.NewStringerEmail[alert](to)
+	alerts.Out = &buf
+	plain := &notify.EmailNotifier[int]{To: to, Out: &buf}
+
+	tests := []struct {
+		name string
+		send func() error
+		want string
+	}{
+		{"invoice", func() error { return notify.SendTyped(context.Background(), invoices, invoice{"INV-1", 1299}) },
+			"Sending user email to Janet<janet@email.com>: invoice INV-1 for 1299 cents\n"},
+		{"stringer", func() error { return notify.SendTyped(context.Background(), alerts, alert{"billing", true}) },
+			"Sending user email to Janet<janet@email.com>: billing is down\n"},
+		{"default format", func() error { return notify.SendTyped(context.Background(), plain, 42) },
+			"Sending user email to Janet<janet@email.com>: 42\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+			if err := tt.send(); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSendTypedCancelled(t *testing.T) {
+	var buf bytes.Buffer
+	n := &notify.EmailNotifier[invoice]{To: &notify.User{Name: "Janet", Email: "janet@email.com"}, Out: &buf}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := notify.SendTyped(ctx, n, invoice{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("SendTyped() = %v, want %v", err, context.Canceled)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("SendTyped() wrote %q after cancellation", buf.String())
+	}
+}
+
+func TestTypedAndUntypedSideBySide(t *testing.T) {
+	var buf bytes.Buffer
+	u, err := notify.NewUser("Janet", "janet@email.com", quiet(&buf)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The same user is reachable through the untyped Notifier path and as
+	// the recipient of a typed notifier.
+	var untyped notify.Notifier = u
+	var typed notify.PayloadNotifier[alert] = &notify.EmailNotifier[alert]{To: u, Out: &buf}
+	if err := notify.SendNotification(context.Background(), untyped); err != nil {
+		t.Fatal(err)
+	}
+	if err := notify.SendTyped(context.Background(), typed, alert{"api", false}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "Sending user email to Janet<janet@email.com>\nSending user email to Janet<janet@email.com>: api is up\n"
+	if got := buf.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
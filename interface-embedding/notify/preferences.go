@@ -0,0 +1,165 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"sync"
+)
+
+// ErrNoPreferredChannel is returned by a PreferringNotifier when it has no
+// notifier for any of the recipient's preferred channels.
+var ErrNoPreferredChannel = errors.New("notify: no notifier for a preferred channel")
+
+// DefaultChannels is the channel order used for recipients without a
+// preference.
+var DefaultChannels = []string{"email"}
+
+// Preferences stores each recipient's preferred channels, in the order
+// they should be tried, keyed by RecipientID. The zero value is ready to
+// use and safe for concurrent use.
+type Preferences struct {
+	mu       sync.RWMutex
+	channels map[string][]string
+}
+
+// Set replaces the recipient's preferred channels.
+func (p *Preferences) Set(id string, channels ...string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.channels == nil {
+		p.channels = make(map[string][]string)
+	}
+	p.channels[id] = slices.Clone(channels)
+}
+
+// Get returns a copy of the recipient's preferred channels, or of
+// DefaultChannels when none are set.
+func (p *Preferences) Get(id string) []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if channels, ok := p.channels[id]; ok {
+		return slices.Clone(channels)
+	}
+
+	return slices.Clone(DefaultChannels)
+}
+
+// Delete forgets the recipient's preference, so DefaultChannels apply.
+func (p *Preferences) Delete(id string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.channels, id)
+}
+
+// Load replaces every preference with those read from r, a JSON object
+// mapping recipient IDs to channel lists, as written by Save.
+func (p *Preferences) Load(r io.Reader) error {
+	var channels map[string][]string
+	if err := json.NewDecoder(r).Decode(&channels); err != nil {
+		return fmt.Errorf("notify: load preferences: %w", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.channels = channels
+	return nil
+}
+
+// Save writes every preference to w as a JSON object with the recipient
+// IDs in sorted order.
+func (p *Preferences) Save(w io.Writer) error {
+	p.mu.RLock()
+	channels := maps.Clone(p.channels)
+	p.mu.RUnlock()
+
+	if channels == nil {
+		channels = map[string][]string{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(channels); err != nil {
+		return fmt.Errorf("notify: save preferences: %w", err)
+	}
+
+	return nil
+}
+
+// PreferringNotifier reaches one recipient over whichever of their
+// channels they prefer, trying the next preference when a send fails.
+type PreferringNotifier struct {
+	prefs    *Preferences
+	id       string
+	channels map[string]Notifier
+	first    Notifier
+}
+
+// NewPreferringNotifier returns a notifier for the recipient reached by
+// ns, one notifier per channel, as named by AddressOf. The recipient is
+// identified by the first of ns, which must implement Identifier, as User
+// does. Later notifiers replace earlier ones for the same channel.
+func NewPreferringNotifier(prefs *Preferences, ns ...Notifier) (*PreferringNotifier, error) {
+	if len(ns) == 0 {
+		return nil, errors.New("notify: preferring notifier needs at least one notifier")
+	}
+	id, ok := recipientID(ns[0])
+	if !ok {
+		return nil, ErrNoRecipientID
+	}
+
+	p := &PreferringNotifier{prefs: prefs, id: id, channels: make(map[string]Notifier), first: ns[0]}
+	for _, n := range ns {
+		_, channel := AddressOf(n)
+		p.channels[channel] = n
+	}
+
+	return p, nil
+}
+
+// Notify tries the recipient's preferred channels in order, skipping any
+// it has no notifier for, and stops at the first that succeeds. If every
+// one fails their errors are joined in the order they were tried.
+func (p *PreferringNotifier) Notify(ctx context.Context) error {
+	preferred := p.prefs.Get(p.id)
+
+	var errs []error
+	for _, channel := range preferred {
+		n, ok := p.channels[channel]
+		if !ok {
+			continue
+		}
+		err := n.Notify(ctx)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, err)
+
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	if len(errs) == 0 {
+		return fmt.Errorf("%w: %s prefers %v", ErrNoPreferredChannel, p.id, preferred)
+	}
+
+	return errors.Join(errs...)
+}
+
+// RecipientID returns the ID preferences are looked up by.
+func (p *PreferringNotifier) RecipientID() string {
+	return p.id
+}
+
+// Unwrap returns the first notifier the recipient was given, which
+// identifies them.
+func (p *PreferringNotifier) Unwrap() Notifier {
+	return p.first
+}
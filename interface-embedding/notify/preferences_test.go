@@ -0,0 +1,222 @@
+package notify_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"testing"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+// channelMock is a mock reaching janet@email.com over channel.
+type channelMock struct {
+	notify.Notifier
+	channel string
+}
+
+func (c channelMock) Address() (recipient, channel string) { return "janet@email.com", c.channel }
+func (c channelMock) RecipientID() string                  { return "janet@email.com" }
+
+func TestPreferringNotifier(t *testing.T) {
+	errEmail := errors.New("mailbox full")
+	errSMS := errors.New("no signal")
+
+	tests := []struct {
+		name      string
+		preferred []string
+		errs      map[string]error
+		wantOrder []string
+		wantErrs  []error
+	}{
+		{"default is email", nil, nil, []string{"email"}, nil},
+		{"first preference", []string{"sms", "email"}, nil, []string{"sms"}, nil},
+		{"falls through", []string{"sms", "slack", "email"}, map[string]error{"sms": errSMS}, []string{"sms", "slack"}, nil},
+		{"skips unknown channels", []string{"fax", "email"}, nil, []string{"email"}, nil},
+		{"all fail", []string{"sms", "email"}, map[string]error{"sms": errSMS, "email": errEmail}, []string{"sms", "email"}, []error{errSMS, errEmail}},
+		{"no notifier for any", []string{"fax", "pager"}, nil, nil, []error{notify.ErrNoPreferredChannel}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := &notify.CallOrder{}
+			var ns []notify.Notifier
+			for _, channel := range []string{"email", "sms", "slack"} {
+				m := &notify.MockNotifier{Name: channel, Err: tt.errs[channel], Order: order}
+				ns = append(ns, channelMock{m, channel})
+			}
+			var prefs notify.Preferences
+			if tt.preferred != nil {
+				prefs.Set("janet@email.com", tt.preferred...)
+			}
+			p, err := notify.NewPreferringNotifier(&prefs, ns...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = p.Notify(context.Background())
+			if (err != nil) != (tt.wantErrs != nil) {
+				t.Fatalf("Notify() = %v, want errors %v", err, tt.wantErrs)
+			}
+			for _, want := range tt.wantErrs {
+				if !errors.Is(err, want) {
+					t.Errorf("Notify() = %v, want it to match %v", err, want)
+				}
+			}
+			if got := order.Names(); !slices.Equal(got, tt.wantOrder) {
+				t.Errorf("tried %v, want %v", got, tt.wantOrder)
+			}
+		})
+	}
+}
+
+func TestPreferringNotifierStopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errSMS := errors.New("no signal")
+	sms := notify.NotifierFunc(func(context.Context) error {
+		cancel()
+		return errSMS
+	})
+	email := &notify.MockNotifier{}
+
+	var prefs notify.Preferences
+	prefs.Set("janet@email.com", "sms", "email")
+	p, err := notify.NewPreferringNotifier(&prefs, channelMock{email, "email"}, channelMock{sms, "sms"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := p.Notify(ctx); !errors.Is(err, errSMS) {
+		t.Errorf("Notify() = %v, want %v", err, errSMS)
+	}
+	if email.Calls() != 0 {
+		t.Errorf("email called %d times, want no fallback once cancelled", email.Calls())
+	}
+}
+
+func TestNewPreferringNotifierErrors(t *testing.T) {
+	var prefs notify.Preferences
+	if _, err := notify.NewPreferringNotifier(&prefs); err == nil {
+		t.Error("NewPreferringNotifier() = nil, want an error without notifiers")
+	}
+	if _, err := notify.NewPreferringNotifier(&prefs, &notify.MockNotifier{}); !errors.Is(err, notify.ErrNoRecipientID) {
+		t.Errorf("NewPreferringNotifier() = %v, want %v", err, notify.ErrNoRecipientID)
+	}
+}
+
+func TestPreferences(t *testing.T) {
+	var prefs notify.Preferences
+	if got := prefs.Get("janet@email.com"); !slices.Equal(got, notify.DefaultChannels) {
+		t.Errorf("Get() of an unknown recipient = %v, want %v", got, notify.DefaultChannels)
+	}
+
+	channels := []string{"sms", "email"}
+	prefs.Set("janet@email.com", channels...)
+	channels[0] = "fax"
+	got := prefs.Get("janet@email.com")
+	if !slices.Equal(got, []string{"sms", "email"}) {
+		t.Errorf("Get() = %v, want [sms email] unaffected by the caller's slice", got)
+	}
+	got[0] = "pager"
+	if got := prefs.Get("janet@email.com"); got[0] != "sms" {
+		t.Errorf("Get() = %v after changing the copy, want [sms email]", got)
+	}
+
+	prefs.Delete("janet@email.com")
+	if got := prefs.Get("janet@email.com"); !slices.Equal(got, notify.DefaultChannels) {
+		t.Errorf("Get() after Delete = %v, want %v", got, notify.DefaultChannels)
+	}
+}
+
+func TestPreferencesSaveLoad(t *testing.T) {
+	var prefs notify.Preferences
+	prefs.Set("janet@email.com", "sms", "email")
+	prefs.Set("bill@email.com", "slack")
+
+	var buf bytes.Buffer
+	if err := prefs.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := "{\n  \"bill@email.com\": [\n    \"slack\"\n  ],\n  \"janet@email.com\": [\n    \"sms\",\n    \"email\"\n  ]\n}\n"
+	if got := buf.String(); got != want {
+		t.Errorf("Save() wrote %q, want %q", got, want)
+	}
+
+	var loaded notify.Preferences
+	loaded.Set("lisa@email.com", "sms")
+	if err := loaded.Load(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for id, want := range map[string][]string{
+		"janet@email.com": {"sms", "email"},
+		"bill@email.com":  {"slack"},
+		"lisa@email.com":  notify.DefaultChannels,
+	} {
+		if got := loaded.Get(id); !slices.Equal(got, want) {
+			t.Errorf("Get(%q) after Load = %v, want %v", id, got, want)
+		}
+	}
+
+	var empty bytes.Buffer
+	if err := (&notify.Preferences{}).Save(&empty); err != nil || empty.String() != "{}\n" {
+		t.Errorf("Save() of no preferences = %q, %v, want {}", empty.String(), err)
+	}
+	if err := loaded.Load(bytes.NewBufferString("[]")); err == nil {
+		t.Error("Load() of a JSON array = nil, want an error")
+	}
+}
+
+func TestPreferencesExampleFile(t *testing.T) {
+	f, err := os.Open("../preferences.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var prefs notify.Preferences
+	if err := prefs.Load(f); err != nil {
+		t.Fatalf("Load() of the example = %v", err)
+	}
+	if got := prefs.Get("bill@email.com"); !slices.Equal(got, []string{"slack", "email"}) {
+		t.Errorf(`Get("bill@email.com") = %v, want [slack email]`, got)
+	}
+}
+
+func TestPreferencesConcurrent(t *testing.T) {
+	var prefs notify.Preferences
+	p, err := notify.NewPreferringNotifier(&prefs,
+		channelMock{&notify.MockNotifier{}, "email"}, channelMock{&notify.MockNotifier{}, "sms"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := range 4 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := range 50 {
+				prefs.Set(fmt.Sprintf("user%d@email.com", j), "sms")
+				prefs.Set("janet@email.com", "sms", "email")
+				if i == 0 {
+					prefs.Load(bytes.NewBufferString(`{"janet@email.com": ["email"]}`))
+				}
+				prefs.Delete(fmt.Sprintf("user%d@email.com", j))
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for range 50 {
+				if err := p.Notify(context.Background()); err != nil {
+					t.Error(err)
+				}
+				prefs.Save(&bytes.Buffer{})
+			}
+		}()
+	}
+	wg.Wait()
+}
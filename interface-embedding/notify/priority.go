@@ -0,0 +1,88 @@
+package notify
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+)
+
+// PriorityQueue orders notifications so higher priorities are sent first,
+// and notifications of equal priority in the order they were pushed. The
+// zero value is ready to use and safe for concurrent use.
+type PriorityQueue struct {
+	mu    sync.Mutex
+	items priorityHeap
+	seq   int
+}
+
+// prioritized is one notification waiting in a PriorityQueue.
+type prioritized struct {
+	n        Notifier
+	priority int
+	seq      int
+}
+
+// Push adds n with the given priority.
+func (q *PriorityQueue) Push(n Notifier, priority int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.seq++
+	heap.Push(&q.items, prioritized{n: n, priority: priority, seq: q.seq})
+}
+
+// Pop removes and returns the highest-priority notification, or nil when
+// the queue is empty.
+func (q *PriorityQueue) Pop() Notifier {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.items) == 0 {
+		return nil
+	}
+
+	return heap.Pop(&q.items).(prioritized).n
+}
+
+// Len returns the number of notifications in the queue.
+func (q *PriorityQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return len(q.items)
+}
+
+// Drain pops and sends every notification in priority order, returning
+// one error per notification in the order they were sent.
+func (q *PriorityQueue) Drain(ctx context.Context) []error {
+	var errs []error
+	for n := q.Pop(); n != nil; n = q.Pop() {
+		errs = append(errs, SendNotification(ctx, n))
+	}
+
+	return errs
+}
+
+// priorityHeap implements heap.Interface with the highest priority, then
+// the lowest sequence number, at the root.
+type priorityHeap []prioritized
+
+func (h priorityHeap) Len() int { return len(h) }
+
+func (h priorityHeap) Less(i, j int) bool {
+	if h[i].priority != h[j].priority {
+		return h[i].priority > h[j].priority
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h priorityHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *priorityHeap) Push(x any) { *h = append(*h, x.(prioritized)) }
+
+func (h *priorityHeap) Pop() any {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
@@ -0,0 +1,66 @@
+package notify_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+func TestPriorityQueueOrder(t *testing.T) {
+	type item struct {
+		name     string
+		priority int
+	}
+	tests := []struct {
+		name  string
+		items []item
+		want  string
+	}{
+		{"mixed", []item{{"user", 1}, {"root", 10}, {"admin", 5}, {"digest", 0}}, "root,admin,user,digest"},
+		{"equal priorities are FIFO", []item{{"a", 3}, {"b", 3}, {"c", 3}, {"d", 3}}, "a,b,c,d"},
+		{"ties within mixed", []item{{"u1", 1}, {"a1", 5}, {"u2", 1}, {"a2", 5}, {"n", -1}, {"u3", 1}}, "a1,a2,u1,u2,u3,n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var q notify.PriorityQueue
+			for _, it := range tt.items {
+				q.Push(&notify.MockNotifier{Name: it.name}, it.priority)
+			}
+
+			var got []string
+			for q.Len() > 0 {
+				got = append(got, q.Pop().(*notify.MockNotifier).Name)
+			}
+			if s := strings.Join(got, ","); s != tt.want {
+				t.Errorf("popped %s, want %s", s, tt.want)
+			}
+			if q.Pop() != nil {
+				t.Error("Pop() on an empty queue != nil")
+			}
+		})
+	}
+}
+
+func TestPriorityQueueDrain(t *testing.T) {
+	errDown := errors.New("down")
+	var order notify.CallOrder
+	var q notify.PriorityQueue
+	q.Push(&notify.MockNotifier{Name: "low", Order: &order}, 1)
+	q.Push(&notify.MockNotifier{Name: "high", Err: errDown, Order: &order}, 9)
+	q.Push(&notify.MockNotifier{Name: "mid", Order: &order}, 5)
+
+	errs := q.Drain(context.Background())
+	if got, want := order.Names(), []string{"high", "mid", "low"}; !slices.Equal(got, want) {
+		t.Errorf("sent %v, want %v", got, want)
+	}
+	if len(errs) != 3 || !errors.Is(errs[0], errDown) || errs[1] != nil || errs[2] != nil {
+		t.Errorf("Drain() = %v, want the failure first to match send order", errs)
+	}
+	if q.Len() != 0 {
+		t.Errorf("Len() = %d after Drain, want 0", q.Len())
+	}
+}
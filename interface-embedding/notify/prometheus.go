@@ -0,0 +1,83 @@
+package notify
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MetricsHandler returns a handler that serves the snapshot returned by
+// snap, usually a Metrics' Snapshot method, in the Prometheus text
+// exposition format:
+//
+//	notify_total{channel="email",outcome="success"} 3
+//	notify_duration_seconds_bucket{channel="email",le="0.1"} 2
+//
+// Series are labelled by channel, and histogram buckets are cumulative as
+// Prometheus expects.
+func MetricsHandler(snap func() MetricsSnapshot) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var b bytes.Buffer
+		writeMetrics(&b, snap())
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		w.Write(b.Bytes())
+	})
+}
+
+// writeMetrics renders s in the exposition format, with channels in
+// sorted order so that scrapes are stable.
+func writeMetrics(b *bytes.Buffer, s MetricsSnapshot) {
+	channels := slices.Sorted(maps.Keys(s.Channels))
+
+	b.WriteString("# HELP notify_total Notifications sent, by channel and outcome.\n")
+	b.WriteString("# TYPE notify_total counter\n")
+	for _, c := range channels {
+		cs := s.Channels[c]
+		label := `channel="` + escapeLabel(c) + `"`
+		fmt.Fprintf(b, "notify_total{%s,outcome=\"success\"} %d\n", label, cs.Sent)
+		fmt.Fprintf(b, "notify_total{%s,outcome=\"failure\"} %d\n", label, cs.Failed)
+	}
+
+	b.WriteString("# HELP notify_duration_seconds How long notifications took, by channel.\n")
+	b.WriteString("# TYPE notify_duration_seconds histogram\n")
+	for _, c := range channels {
+		cs := s.Channels[c]
+		label := `channel="` + escapeLabel(c) + `"`
+		var cumulative int64
+		for i, n := range cs.Counts {
+			cumulative += n
+			le := "+Inf"
+			if i < len(cs.Bounds) {
+				le = formatSeconds(cs.Bounds[i])
+			}
+			fmt.Fprintf(b, "notify_duration_seconds_bucket{%s,le=\"%s\"} %d\n", label, le, cumulative)
+		}
+		fmt.Fprintf(b, "notify_duration_seconds_sum{%s} %s\n", label, formatSeconds(cs.Sum))
+		fmt.Fprintf(b, "notify_duration_seconds_count{%s} %d\n", label, cumulative)
+	}
+}
+
+// labelEscaper escapes label values as the exposition format requires.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// escapeLabel escapes a label value for use between double quotes.
+func escapeLabel(v string) string {
+	return labelEscaper.Replace(v)
+}
+
+// formatSeconds formats d as a number of seconds in the shortest form
+// that round-trips.
+func formatSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'g', -1, 64)
+}
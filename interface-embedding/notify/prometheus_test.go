@@ -0,0 +1,143 @@
+package notify_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+// scrape fetches the handler's metrics the way Prometheus would.
+func scrape(t *testing.T, h http.Handler) string {
+	t.Helper()
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	resp, err := srv.Client().Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/plain; version=0.0.4; charset=utf-8" {
+		t.Errorf("Content-Type = %q, want the text exposition format", ct)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(body)
+}
+
+func TestMetricsHandler(t *testing.T) {
+	bounds := []time.Duration{100 * time.Millisecond, time.Second}
+	snap := notify.MetricsSnapshot{
+		Sent:   4,
+		Failed: 1,
+		Bounds: bounds,
+		Channels: map[string]notify.MetricsSnapshot{
+			"sms":   {Sent: 1, Failed: 0, Bounds: bounds, Counts: []int64{0, 1, 0}, Sum: 250 * time.Millisecond},
+			"email": {Sent: 3, Failed: 1, Bounds: bounds, Counts: []int64{2, 1, 1}, Sum: 2500 * time.Millisecond},
+		},
+	}
+
+	got := scrape(t, notify.MetricsHandler(func() notify.MetricsSnapshot { return snap }))
+	want := `# HELP notify_total Notifications sent, by channel and outcome.
+# TYPE notify_total counter
+notify_total{channel="email",outcome="success"} 3
+notify_total{channel="email",outcome="failure"} 1
+notify_total{channel="sms",outcome="success"} 1
+notify_total{channel="sms",outcome="failure"} 0
+# HELP notify_duration_seconds How long notifications took, by channel.
+# TYPE notify_duration_seconds histogram
+notify_duration_seconds_bucket{channel="email",le="0.1"} 2
+notify_duration_seconds_bucket{channel="email",le="1"} 3
+notify_duration_seconds_bucket{channel="email",le="+Inf"} 4
+notify_duration_seconds_sum{channel="email"} 2.5
+notify_duration_seconds_count{channel="email"} 4
+notify_duration_seconds_bucket{channel="sms",le="0.1"} 0
+notify_duration_seconds_bucket{channel="sms",le="1"} 1
+notify_duration_seconds_bucket{channel="sms",le="+Inf"} 1
+notify_duration_seconds_sum{channel="sms"} 0.25
+notify_duration_seconds_count{channel="sms"} 1
+`
+	if got != want {
+		t.Errorf("scrape =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestMetricsHandlerEscapesLabels(t *testing.T) {
+	tests := []struct {
+		name    string
+		channel string
+		want    string
+	}{
+		{"quote", `say "hi"`, `notify_total{channel="say \"hi\"",outcome="success"} 1`},
+		{"backslash", `C:\queue`, `notify_total{channel="C:\\queue",outcome="success"} 1`},
+		{"newline", "two\nlines", `notify_total{channel="two\nlines",outcome="success"} 1`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snap := notify.MetricsSnapshot{Channels: map[string]notify.MetricsSnapshot{
+				tt.channel: {Sent: 1, Counts: []int64{1}},
+			}}
+			got := scrape(t, notify.MetricsHandler(func() notify.MetricsSnapshot { return snap }))
+			if !strings.Contains(got, tt.want+"\n") {
+				t.Errorf("scrape =\n%s\nwant the line %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMetricsHandlerFromNotifiers(t *testing.T) {
+	var m notify.Metrics
+	janet := &notify.User{Name: "Janet", Email: "janet@email.com"}
+	janet.SetOutput(io.Discard)
+	failing := &addressed{Notifier: &notify.MockNotifier{Err: errors.New("server down")}, to: janet}
+	for _, n := range []notify.Notifier{janet, janet, failing} {
+		(&notify.MetricsNotifier{Notifier: n, Metrics: &m}).Notify(context.Background())
+	}
+
+	got := scrape(t, notify.MetricsHandler(m.Snapshot))
+	for _, want := range []string{
+		`notify_total{channel="email",outcome="success"} 2`,
+		`notify_total{channel="email",outcome="failure"} 1`,
+		`notify_duration_seconds_bucket{channel="email",le="+Inf"} 3`,
+		`notify_duration_seconds_count{channel="email"} 3`,
+	} {
+		if !strings.Contains(got, want+"\n") {
+			t.Errorf("scrape =\n%s\nwant the line %s", got, want)
+		}
+	}
+}
+
+func TestMetricsHandlerMethods(t *testing.T) {
+	h := notify.MetricsHandler(func() notify.MetricsSnapshot { return notify.MetricsSnapshot{} })
+
+	tests := []struct {
+		method string
+		want   int
+	}{
+		{http.MethodGet, http.StatusOK},
+		{http.MethodHead, http.StatusOK},
+		{http.MethodPost, http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(tt.method, "/metrics", nil))
+			if rec.Code != tt.want {
+				t.Errorf("%s /metrics = %d, want %d", tt.method, rec.Code, tt.want)
+			}
+			if tt.want == http.StatusMethodNotAllowed && rec.Header().Get("Allow") != "GET, HEAD" {
+				t.Errorf("Allow = %q, want GET, HEAD", rec.Header().Get("Allow"))
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package notify
+
+import (
+	"context"
+	"sync"
+)
+
+// DeadLetter is a notification that was given up on, along with the error
+// from its final attempt.
+type DeadLetter struct {
+	Notifier Notifier
+	Err      error
+	Attempts int
+}
+
+// Queue is an in-memory notification queue with at-least-once delivery.
+// Failed notifications are put back at the end of the queue until they
+// have been attempted maxAttempts times, then moved to the dead letters.
+type Queue struct {
+	maxAttempts int
+	wake        chan struct{}
+
+	mu      sync.Mutex
+	pending []queued
+	dead    []DeadLetter
+	cancel  context.CancelFunc // set while started by Start
+	done    chan struct{}
+}
+
+// queued is a pending notification and the attempts made so far.
+type queued struct {
+	n        Notifier
+	attempts int
+}
+
+// NewQueue returns an empty queue that attempts each notification up to
+// maxAttempts times.
+func NewQueue(maxAttempts int) *Queue {
+	return &Queue{
+		maxAttempts: max(maxAttempts, 1),
+		wake:        make(chan struct{}, 1),
+	}
+}
+
+// Enqueue adds n to the end of the queue.
+func (q *Queue) Enqueue(n Notifier) {
+	q.mu.Lock()
+	q.pending = append(q.pending, queued{n: n})
+	q.mu.Unlock()
+
+	q.signal()
+}
+
+// Run delivers queued notifications until ctx is done, then returns how
+// many were left unprocessed. A send that is in flight when ctx is done is
+// allowed to finish, and its outcome is recorded before Run returns.
+func (q *Queue) Run(ctx context.Context) (unprocessed int) {
+	for {
+		item, ok := q.next()
+		if !ok {
+			select {
+			case <-q.wake:
+				continue
+			case <-ctx.Done():
+				return q.Len()
+			}
+		}
+
+		item.attempts++
+		err := SendNotification(context.WithoutCancel(ctx), item.n)
+		q.settle(item, err)
+
+		if ctx.Err() != nil {
+			return q.Len()
+		}
+	}
+}
+
+// Start runs the queue in the background until Shutdown, as Run does. The
+// queue keeps running after ctx is done; only its values are used.
+func (q *Queue) Start(ctx context.Context) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.cancel != nil {
+		return ErrAlreadyStarted
+	}
+	ctx, q.cancel = context.WithCancel(context.WithoutCancel(ctx))
+	q.done = make(chan struct{})
+	go func(done chan struct{}) {
+		defer close(done)
+		q.Run(ctx)
+	}(q.done)
+
+	return nil
+}
+
+// Shutdown stops a queue started with Start, waiting for any send in
+// flight to be recorded. It returns ctx.Err() if ctx is done first, and
+// nil if the queue isn't running.
+func (q *Queue) Shutdown(ctx context.Context) error {
+	q.mu.Lock()
+	cancel, done := q.cancel, q.done
+	q.cancel, q.done = nil, nil
+	q.mu.Unlock()
+
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Len returns the number of notifications waiting to be delivered.
+func (q *Queue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return len(q.pending)
+}
+
+// DeadLetters returns a copy of the notifications that exhausted their
+// attempts.
+func (q *Queue) DeadLetters() []DeadLetter {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return append([]DeadLetter(nil), q.dead...)
+}
+
+// next removes and returns the notification at the front of the queue.
+func (q *Queue) next() (queued, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.pending) == 0 {
+		return queued{}, false
+	}
+	item := q.pending[0]
+	q.pending = q.pending[1:]
+
+	return item, true
+}
+
+// settle records the outcome of one attempt, requeueing or dead-lettering
+// the notification if it failed.
+func (q *Queue) settle(item queued, err error) {
+	if err == nil {
+		return
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if item.attempts < q.maxAttempts {
+		q.pending = append(q.pending, item)
+		return
+	}
+	q.dead = append(q.dead, DeadLetter{Notifier: item.n, Err: err, Attempts: item.attempts})
+}
+
+// signal wakes Run if it's waiting for work.
+func (q *Queue) signal() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,132 @@
+package notify_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+// waitFor polls cond until it holds, failing the test after a few seconds.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestQueueRedeliversAndDeadLetters(t *testing.T) {
+	errFlaky, errDown := errors.New("flaky"), errors.New("down")
+	flaky := &notify.MockNotifier{Name: "flaky", Errs: []error{errFlaky, errFlaky, nil}}
+	down := &notify.MockNotifier{Name: "down", Err: errDown}
+	ok := &notify.MockNotifier{Name: "ok"}
+
+	q := notify.NewQueue(3)
+	for _, m := range []*notify.MockNotifier{flaky, down, ok} {
+		q.Enqueue(m)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan int)
+	go func() { done <- q.Run(ctx) }()
+	waitFor(t, "the queue to drain", func() bool { return len(q.DeadLetters()) == 1 && q.Len() == 0 })
+	cancel()
+	if left := <-done; left != 0 {
+		t.Errorf("Run() left %d unprocessed, want 0", left)
+	}
+
+	tests := []struct {
+		m    *notify.MockNotifier
+		want int
+	}{
+		{flaky, 3},
+		{down, 3},
+		{ok, 1},
+	}
+	for _, tt := range tests {
+		if tt.m.Calls() != tt.want {
+			t.Errorf("%s attempted %d times, want %d", tt.m.Name, tt.m.Calls(), tt.want)
+		}
+	}
+
+	dead := q.DeadLetters()
+	if dead[0].Notifier != down || dead[0].Attempts != 3 || !errors.Is(dead[0].Err, errDown) {
+		t.Errorf("dead letter = %+v, want down after 3 attempts", dead[0])
+	}
+}
+
+func TestQueueShutdownFinishesInFlight(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	var sendCtxErr error
+	slow := notify.NotifierFunc(func(ctx context.Context) error {
+		close(started)
+		<-release
+		sendCtxErr = ctx.Err()
+		return errors.New("failed after shutdown")
+	})
+
+	q := notify.NewQueue(1)
+	q.Enqueue(slow)
+	for range 3 {
+		q.Enqueue(&notify.MockNotifier{})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan int)
+	go func() { done <- q.Run(ctx) }()
+	<-started
+	cancel()
+	close(release)
+
+	if left := <-done; left != 3 {
+		t.Errorf("Run() left %d unprocessed, want 3", left)
+	}
+	if sendCtxErr != nil {
+		t.Errorf("in-flight send saw ctx error %v, want it to finish uncancelled", sendCtxErr)
+	}
+	if len(q.DeadLetters()) != 1 {
+		t.Errorf("dead letters = %d, want the in-flight failure recorded", len(q.DeadLetters()))
+	}
+}
+
+func TestQueueStartShutdown(t *testing.T) {
+	q := notify.NewQueue(1)
+	if err := q.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Start(context.Background()); !errors.Is(err, notify.ErrAlreadyStarted) {
+		t.Errorf("second Start() = %v, want %v", err, notify.ErrAlreadyStarted)
+	}
+
+	mocks := make([]*notify.MockNotifier, 50)
+	var wg sync.WaitGroup
+	for i := range mocks {
+		mocks[i] = &notify.MockNotifier{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			q.Enqueue(mocks[i])
+		}()
+	}
+	wg.Wait()
+	waitFor(t, "the queue to drain", func() bool { return q.Len() == 0 })
+
+	if err := q.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Shutdown(context.Background()); err != nil {
+		t.Errorf("Shutdown() of a stopped queue = %v, want nil", err)
+	}
+	for i, m := range mocks {
+		if m.Calls() != 1 {
+			t.Errorf("notification %d delivered %d times, want 1", i, m.Calls())
+		}
+	}
+}
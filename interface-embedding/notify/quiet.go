@@ -0,0 +1,234 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrQuietHours is returned by a PolicyNotifier in QuietReject mode when
+// the recipient is in their quiet hours.
+var ErrQuietHours = errors.New("notify: recipient is in quiet hours")
+
+// WithLocation sets the time zone the recipient lives in, used to work out
+// their quiet hours. Recipients without one are treated as being in UTC.
+func WithLocation(loc *time.Location) UserOption {
+	return func(u *User) error {
+		if loc == nil {
+			return errors.New("notify: location is required")
+		}
+		u.location = loc
+
+		return nil
+	}
+}
+
+// Location returns the recipient's time zone, or time.UTC when none was
+// set.
+func (u *User) Location() *time.Location {
+	if u.location == nil {
+		return time.UTC
+	}
+
+	return u.location
+}
+
+// Locator is implemented by recipients that know their time zone. User
+// implements it, and types embedding User inherit it.
+type Locator interface {
+	Location() *time.Location
+}
+
+// QuietHours is a daily window of wall-clock time, such as 22:00 to 07:00,
+// in which a recipient doesn't want to be disturbed. A window whose end is
+// before its start runs past midnight.
+type QuietHours struct {
+	start, end int // minutes since midnight
+}
+
+// ParseQuietHours parses a window written as "22:00-07:00". Start and end
+// must differ.
+func ParseQuietHours(window string) (QuietHours, error) {
+	var sh, sm, eh, em int
+	if _, err := fmt.Sscanf(window, "%d:%d-%d:%d", &sh, &sm, &eh, &em); err != nil {
+		return QuietHours{}, fmt.Errorf("notify: invalid quiet hours %q: %v", window, err)
+	}
+	for _, v := range [][2]int{{sh, sm}, {eh, em}} {
+		if v[0] < 0 || v[0] > 23 || v[1] < 0 || v[1] > 59 {
+			return QuietHours{}, fmt.Errorf("notify: invalid quiet hours %q: time out of range", window)
+		}
+	}
+
+	q := QuietHours{start: sh*60 + sm, end: eh*60 + em}
+	if q.start == q.end {
+		return QuietHours{}, fmt.Errorf("notify: invalid quiet hours %q: window is empty", window)
+	}
+
+	return q, nil
+}
+
+// Contains reports whether t's wall-clock time, in t's location, falls in
+// the window.
+func (q QuietHours) Contains(t time.Time) bool {
+	m := t.Hour()*60 + t.Minute()
+	if q.start < q.end {
+		return m >= q.start && m < q.end
+	}
+
+	return m >= q.start || m < q.end
+}
+
+// End returns when the window containing t closes, in t's location. The
+// end is found by wall-clock time, so a window ending at 07:00 ends at
+// 07:00 local time even across a daylight saving change.
+func (q QuietHours) End(t time.Time) time.Time {
+	y, mo, d := t.Date()
+	end := time.Date(y, mo, d, q.end/60, q.end%60, 0, 0, t.Location())
+	if !end.After(t) {
+		end = time.Date(y, mo, d+1, q.end/60, q.end%60, 0, 0, t.Location())
+	}
+
+	return end
+}
+
+// String formats the window as "22:00-07:00".
+func (q QuietHours) String() string {
+	return fmt.Sprintf("%02d:%02d-%02d:%02d", q.start/60, q.start%60, q.end/60, q.end%60)
+}
+
+// Policy holds each recipient's quiet hours, keyed by the recipient that
+// AddressOf reports. The zero value has no quiet hours and is safe for
+// concurrent use.
+type Policy struct {
+	mu    sync.RWMutex
+	quiet map[string]QuietHours
+}
+
+// SetQuietHours sets recipient's quiet hours, replacing any already set.
+func (p *Policy) SetQuietHours(recipient string, q QuietHours) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.quiet == nil {
+		p.quiet = make(map[string]QuietHours)
+	}
+	p.quiet[recipient] = q
+}
+
+// QuietUntil reports whether n's recipient is in quiet hours at now and,
+// if so, when they end. The recipient's time zone comes from the first
+// Locator found through any wrappers, defaulting to UTC.
+func (p *Policy) QuietUntil(n Notifier, now time.Time) (time.Time, bool) {
+	recipient, _ := AddressOf(n)
+
+	p.mu.RLock()
+	q, ok := p.quiet[recipient]
+	p.mu.RUnlock()
+	if !ok {
+		return time.Time{}, false
+	}
+
+	now = now.In(locationOf(n))
+	if !q.Contains(now) {
+		return time.Time{}, false
+	}
+
+	return q.End(now), true
+}
+
+// locationOf returns the time zone of n or the first notifier it wraps
+// that implements Locator.
+func locationOf(n Notifier) *time.Location {
+	for n != nil {
+		if l, ok := n.(Locator); ok {
+			return l.Location()
+		}
+		w, ok := n.(Wrapper)
+		if !ok {
+			break
+		}
+		n = w.Unwrap()
+	}
+
+	return time.UTC
+}
+
+// QuietMode says what a PolicyNotifier does with a notification that
+// arrives during quiet hours.
+type QuietMode int
+
+const (
+	// QuietDefer holds the notification and sends it when quiet hours end.
+	QuietDefer QuietMode = iota
+
+	// QuietReject refuses the notification with ErrQuietHours.
+	QuietReject
+)
+
+// PolicyNotifier enforces a Policy's quiet hours on the embedded Notifier.
+type PolicyNotifier struct {
+	Notifier
+
+	// OnError, when set before the first Notify, receives the errors from
+	// deferred sends, which have no caller to return them to.
+	OnError func(error)
+
+	policy *Policy
+	mode   QuietMode
+	clock  Clock
+
+	wg sync.WaitGroup
+}
+
+// NewPolicyNotifier wraps n so that it honours p's quiet hours in the given
+// mode. Time is read from clock, or from SystemClock when clock is nil.
+func NewPolicyNotifier(n Notifier, p *Policy, mode QuietMode, clock Clock) *PolicyNotifier {
+	if clock == nil {
+		clock = SystemClock
+	}
+
+	return &PolicyNotifier{Notifier: n, policy: p, mode: mode, clock: clock}
+}
+
+// Notify sends straight away outside quiet hours. During them it either
+// returns ErrQuietHours or, in QuietDefer mode, returns nil and sends in
+// the background once they end. A deferred send isn't cancelled with ctx,
+// but keeps its values.
+func (p *PolicyNotifier) Notify(ctx context.Context) error {
+	until, quiet := p.policy.QuietUntil(p.Notifier, p.clock.Now())
+	if !quiet {
+		return p.Notifier.Notify(ctx)
+	}
+
+	if p.mode == QuietReject {
+		recipient, channel := AddressOf(p.Notifier)
+		err := fmt.Errorf("%w until %s", ErrQuietHours, until.Format(time.Kitchen))
+		return &NotificationError{Recipient: recipient, Channel: channel, Err: err}
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	wait := until.Sub(p.clock.Now())
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+
+		<-p.clock.After(wait)
+		if err := p.Notifier.Notify(ctx); err != nil && p.OnError != nil {
+			p.OnError(err)
+		}
+	}()
+
+	return nil
+}
+
+// Wait blocks until every deferred notification has been sent.
+func (p *PolicyNotifier) Wait() {
+	p.wg.Wait()
+}
+
+// Unwrap returns the notifier the policy is enforced on.
+func (p *PolicyNotifier) Unwrap() Notifier {
+	return p.Notifier
+}
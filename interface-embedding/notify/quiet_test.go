@@ -0,0 +1,219 @@
+package notify_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+func loadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("LoadLocation(%q) = %v", name, err)
+	}
+
+	return loc
+}
+
+func TestParseQuietHours(t *testing.T) {
+	tests := []struct {
+		window  string
+		want    string
+		wantErr bool
+	}{
+		{"22:00-07:00", "22:00-07:00", false},
+		{"9:5-17:30", "09:05-17:30", false},
+		{"00:00-23:59", "00:00-23:59", false},
+		{"22:00", "", true},
+		{"24:00-07:00", "", true},
+		{"22:60-07:00", "", true},
+		{"07:00-07:00", "", true},
+		{"late-early", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.window, func(t *testing.T) {
+			q, err := notify.ParseQuietHours(tt.window)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseQuietHours() error = %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && q.String() != tt.want {
+				t.Errorf("ParseQuietHours() = %s, want %s", q, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuietHoursContains(t *testing.T) {
+	overnight, _ := notify.ParseQuietHours("22:00-07:00")
+	daytime, _ := notify.ParseQuietHours("12:00-13:30")
+	at := func(h, m int) time.Time { return time.Date(2026, time.January, 1, h, m, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name string
+		q    notify.QuietHours
+		t    time.Time
+		want bool
+	}{
+		{"before overnight window", overnight, at(21, 59), false},
+		{"overnight window starts", overnight, at(22, 0), true},
+		{"just before midnight", overnight, at(23, 59), true},
+		{"midnight", overnight, at(0, 0), true},
+		{"early morning", overnight, at(6, 59), true},
+		{"overnight window ends", overnight, at(7, 0), false},
+		{"midday", overnight, at(12, 0), false},
+		{"daytime window starts", daytime, at(12, 0), true},
+		{"inside daytime window", daytime, at(13, 29), true},
+		{"daytime window ends", daytime, at(13, 30), false},
+		{"night outside daytime window", daytime, at(23, 0), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.q.Contains(tt.t); got != tt.want {
+				t.Errorf("%s.Contains(%s) = %v, want %v", tt.q, tt.t.Format("15:04"), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuietHoursEnd(t *testing.T) {
+	newYork := loadLocation(t, "America/New_York")
+	overnight, _ := notify.ParseQuietHours("22:00-07:00")
+
+	tests := []struct {
+		name     string
+		t        time.Time
+		wantEnd  time.Time
+		wantWait time.Duration
+	}{
+		{"before midnight", time.Date(2026, time.January, 1, 23, 0, 0, 0, newYork),
+			time.Date(2026, time.January, 2, 7, 0, 0, 0, newYork), 8 * time.Hour},
+		{"after midnight", time.Date(2026, time.January, 2, 1, 30, 0, 0, newYork),
+			time.Date(2026, time.January, 2, 7, 0, 0, 0, newYork), 5*time.Hour + 30*time.Minute},
+		// Clocks go forward at 02:00 on 8 March, so the night is an hour short.
+		{"spring forward", time.Date(2026, time.March, 7, 23, 0, 0, 0, newYork),
+			time.Date(2026, time.March, 8, 7, 0, 0, 0, newYork), 7 * time.Hour},
+		// Clocks go back at 02:00 on 1 November, so the night is an hour long.
+		{"fall back", time.Date(2026, time.October, 31, 23, 0, 0, 0, newYork),
+			time.Date(2026, time.November, 1, 7, 0, 0, 0, newYork), 9 * time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			end := overnight.End(tt.t)
+			if !end.Equal(tt.wantEnd) || end.Format("15:04") != "07:00" {
+				t.Errorf("End(%v) = %v, want %v", tt.t, end, tt.wantEnd)
+			}
+			if got := end.Sub(tt.t); got != tt.wantWait {
+				t.Errorf("quiet hours last %v, want %v", got, tt.wantWait)
+			}
+		})
+	}
+}
+
+func TestPolicyQuietUntil(t *testing.T) {
+	overnight, _ := notify.ParseQuietHours("22:00-07:00")
+	var buf bytes.Buffer
+	tokyo, err := notify.NewUser("Janet Jones", "janet@email.com", append(quiet(&buf), notify.WithLocation(loadLocation(t, "Asia/Tokyo")))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	utc := &notify.User{Name: "Bob Brown", Email: "bob@email.com"}
+	var p notify.Policy
+	p.SetQuietHours("janet@email.com", overnight)
+	p.SetQuietHours("bob@email.com", overnight)
+
+	tests := []struct {
+		name      string
+		n         notify.Notifier
+		now       time.Time
+		wantQuiet bool
+		wantUntil time.Time
+	}{
+		// 14:00 UTC is 23:00 in Tokyo.
+		{"in the recipient's time zone", tokyo, time.Date(2026, time.January, 1, 14, 0, 0, 0, time.UTC),
+			true, time.Date(2026, time.January, 1, 22, 0, 0, 0, time.UTC)},
+		{"found through wrappers", &notify.RetryNotifier{Notifier: tokyo}, time.Date(2026, time.January, 1, 14, 0, 0, 0, time.UTC),
+			true, time.Date(2026, time.January, 1, 22, 0, 0, 0, time.UTC)},
+		{"defaults to UTC", utc, time.Date(2026, time.January, 1, 14, 0, 0, 0, time.UTC), false, time.Time{}},
+		{"no quiet hours set", &notify.User{Email: "lisa@email.com"}, time.Date(2026, time.January, 1, 23, 0, 0, 0, time.UTC), false, time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			until, quiet := p.QuietUntil(tt.n, tt.now)
+			if quiet != tt.wantQuiet || !until.Equal(tt.wantUntil) {
+				t.Errorf("QuietUntil() = %v, %v, want %v, %v", until, quiet, tt.wantUntil, tt.wantQuiet)
+			}
+		})
+	}
+}
+
+func TestPolicyNotifierReject(t *testing.T) {
+	overnight, _ := notify.ParseQuietHours("22:00-07:00")
+	var p notify.Policy
+	p.SetQuietHours("janet@email.com", overnight)
+	clock := newFakeClock()
+	var buf bytes.Buffer
+	// 09:00 UTC is 01:00 in Los Angeles.
+	janet, err := notify.NewUser("Janet Jones", "janet@email.com", append(quiet(&buf), notify.WithLocation(loadLocation(t, "America/Los_Angeles")))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n := notify.NewPolicyNotifier(janet, &p, notify.QuietReject, clock)
+	err = n.Notify(context.Background())
+	if !errors.Is(err, notify.ErrQuietHours) || !strings.Contains(err.Error(), "until 7:00AM") {
+		t.Errorf("Notify() = %v, want %v until 7:00AM", err, notify.ErrQuietHours)
+	}
+	var ne *notify.NotificationError
+	if !errors.As(err, &ne) || ne.Recipient != "janet@email.com" || ne.Retryable {
+		t.Errorf("Notify() = %#v, want a permanent NotificationError for janet@email.com", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("output = %q in quiet hours, want nothing", buf.String())
+	}
+
+	clock.Advance(6 * time.Hour)
+	if err := n.Notify(context.Background()); err != nil || buf.Len() == 0 {
+		t.Errorf("Notify() after quiet hours = %v with output %q, want it sent", err, buf.String())
+	}
+}
+
+func TestPolicyNotifierDefer(t *testing.T) {
+	morning, _ := notify.ParseQuietHours("08:00-10:30")
+	var p notify.Policy
+	p.SetQuietHours("janet@email.com", morning)
+	clock := newFakeClock()
+	var buf lockedBuffer
+	janet, err := notify.NewUser("Janet Jones", "janet@email.com", notify.WithWriter(&buf), notify.WithLogger(slog.New(slog.DiscardHandler)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n := notify.NewPolicyNotifier(janet, &p, notify.QuietDefer, clock)
+	var errs []error
+	n.OnError = func(err error) { errs = append(errs, err) }
+	if err := n.Notify(context.Background()); err != nil {
+		t.Fatalf("Notify() = %v, want nil while deferred", err)
+	}
+	if !clock.BlockUntil(1) {
+		t.Fatal("deferred send never waited for quiet hours to end")
+	}
+	if buf.String() != "" {
+		t.Fatalf("output = %q during quiet hours, want nothing yet", buf.String())
+	}
+
+	clock.Advance(90 * time.Minute)
+	n.Wait()
+	if got, want := buf.String(), "Sending user email to Janet Jones<janet@email.com>\n"; got != want {
+		t.Errorf("output after quiet hours = %q, want %q", got, want)
+	}
+	if len(errs) != 0 {
+		t.Errorf("OnError got %v, want no errors", errs)
+	}
+}
@@ -0,0 +1,125 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited is returned by a non-blocking RateLimitedNotifier when no
+// token is available.
+var ErrRateLimited = errors.New("notify: rate limited")
+
+// RateLimitedNotifier caps how often the embedded Notifier is called using
+// a token bucket: it holds up to burst tokens, refills at perSecond tokens
+// a second, and every notification spends one.
+type RateLimitedNotifier struct {
+	Notifier
+
+	nonBlocking bool
+	clock       Clock
+
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// RateLimitOption configures a RateLimitedNotifier.
+type RateLimitOption func(*RateLimitedNotifier)
+
+// NonBlocking makes Notify fail with ErrRateLimited instead of waiting
+// when no token is available.
+func NonBlocking() RateLimitOption {
+	return func(r *RateLimitedNotifier) {
+		r.nonBlocking = true
+	}
+}
+
+// WithRateLimitClock sets the clock the bucket refills by and waits on.
+// It defaults to SystemClock.
+func WithRateLimitClock(clock Clock) RateLimitOption {
+	return func(r *RateLimitedNotifier) {
+		r.clock = clock
+	}
+}
+
+// NewRateLimitedNotifier wraps n so that it's called at most perSecond
+// times a second on average, with bursts of up to burst calls.
+func NewRateLimitedNotifier(n Notifier, perSecond float64, burst int, opts ...RateLimitOption) *RateLimitedNotifier {
+	b := float64(max(burst, 1))
+	r := &RateLimitedNotifier{
+		Notifier: n,
+		rate:     perSecond,
+		burst:    b,
+		tokens:   b,
+		clock:    SystemClock,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	if r.clock == nil {
+		r.clock = SystemClock
+	}
+	r.last = r.clock.Now()
+
+	return r
+}
+
+// Notify waits for a token and then calls the embedded Notifier. It returns
+// ctx.Err() without calling it if ctx is done first, or ErrRateLimited
+// straight away in non-blocking mode.
+func (r *RateLimitedNotifier) Notify(ctx context.Context) error {
+	if err := r.wait(ctx); err != nil {
+		return err
+	}
+
+	return r.Notifier.Notify(ctx)
+}
+
+// Unwrap returns the notifier being rate limited.
+func (r *RateLimitedNotifier) Unwrap() Notifier {
+	return r.Notifier
+}
+
+// wait reserves a token, sleeping until it's available. A reservation
+// abandoned because ctx is done is returned to the bucket.
+func (r *RateLimitedNotifier) wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	now := r.clock.Now()
+	r.tokens = min(r.burst, r.tokens+now.Sub(r.last).Seconds()*r.rate)
+	r.last = now
+	if r.tokens < 1 && r.nonBlocking {
+		r.mu.Unlock()
+		return ErrRateLimited
+	}
+	r.tokens--
+	deficit := -r.tokens
+	r.mu.Unlock()
+
+	if deficit <= 0 {
+		return nil
+	}
+
+	// A bucket that never refills can only be waited on until ctx is done.
+	var ready <-chan time.Time
+	if r.rate > 0 {
+		ready = r.clock.After(time.Duration(deficit / r.rate * float64(time.Second)))
+	}
+
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+		r.mu.Lock()
+		r.tokens++
+		r.mu.Unlock()
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,129 @@
+package notify_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+func TestRateLimitedNotifierWaits(t *testing.T) {
+	mock := &notify.MockNotifier{}
+	r := notify.NewRateLimitedNotifier(mock, 2, 2)
+
+	start := time.Now()
+	for range 4 {
+		if err := r.Notify(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Two tokens are available at once and the other two take half a
+	// second each to refill.
+	if elapsed := time.Since(start); elapsed < 900*time.Millisecond {
+		t.Errorf("4 notifications at 2/s took %v, want about 1s", elapsed)
+	}
+	if mock.Calls() != 4 {
+		t.Errorf("calls = %d, want 4", mock.Calls())
+	}
+}
+
+func TestRateLimitedNotifierCancelledWhileWaiting(t *testing.T) {
+	mock := &notify.MockNotifier{}
+	r := notify.NewRateLimitedNotifier(mock, 0.1, 1)
+	if err := r.Notify(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := r.Notify(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Notify() = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Notify() returned after %v, want it to stop when ctx expired", elapsed)
+	}
+	if mock.Calls() != 1 {
+		t.Errorf("calls = %d, want the cancelled notification not sent", mock.Calls())
+	}
+}
+
+func TestRateLimitedNotifierSimClock(t *testing.T) {
+	start := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	clock := notify.NewSimClock(start)
+	mock := &notify.MockNotifier{}
+	r := notify.NewRateLimitedNotifier(mock, 5, 5, notify.WithRateLimitClock(clock))
+
+	var sentAt []time.Duration
+	for range 10 {
+		if err := r.Notify(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		sentAt = append(sentAt, clock.Now().Sub(start))
+	}
+
+	// The burst of five goes at once, then one every 200ms.
+	want := []time.Duration{0, 0, 0, 0, 0, 200, 400, 600, 800, 1000}
+	for i := range want {
+		if got := sentAt[i].Round(time.Millisecond); got != want[i]*time.Millisecond {
+			t.Errorf("notification %d sent at %v, want %v", i, got, want[i]*time.Millisecond)
+		}
+	}
+}
+
+func TestRateLimitedNotifierNonBlocking(t *testing.T) {
+	clock := notify.NewSimClock(time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC))
+	mock := &notify.MockNotifier{}
+	r := notify.NewRateLimitedNotifier(mock, 1, 2, notify.NonBlocking(), notify.WithRateLimitClock(clock))
+
+	tests := []struct {
+		advance time.Duration
+		wantErr error
+	}{
+		{0, nil},
+		{0, nil},
+		{0, notify.ErrRateLimited},
+		{500 * time.Millisecond, notify.ErrRateLimited},
+		{500 * time.Millisecond, nil},
+		{0, notify.ErrRateLimited},
+		{time.Hour, nil},
+		{0, nil},
+		{0, notify.ErrRateLimited},
+	}
+	for i, tt := range tests {
+		clock.Advance(tt.advance)
+		if err := r.Notify(context.Background()); !errors.Is(err, tt.wantErr) {
+			t.Errorf("call %d: Notify() = %v, want %v", i, err, tt.wantErr)
+		}
+	}
+	if mock.Calls() != 5 {
+		t.Errorf("calls = %d, want 5", mock.Calls())
+	}
+}
+
+func TestRateLimitedNotifierReturnsAbandonedToken(t *testing.T) {
+	mock := &notify.MockNotifier{}
+	r := notify.NewRateLimitedNotifier(mock, 10, 1)
+	if err := r.Notify(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Abandoning a wait must not spend the token it reserved, so the next
+	// caller waits no longer than it would have anyway.
+	for range 10 {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		if err := r.Notify(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Notify() = %v, want %v", err, context.DeadlineExceeded)
+		}
+		cancel()
+	}
+	start := time.Now()
+	if err := r.Notify(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Notify() waited %v after abandoned waits, want at most about 100ms", elapsed)
+	}
+}
@@ -0,0 +1,54 @@
+package notify
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+// Registry stores notifiers of a single type by key. The zero value is
+// ready to use and safe for concurrent use.
+type Registry[T Notifier] struct {
+	mu        sync.RWMutex
+	notifiers map[string]T
+}
+
+// Register stores n under key, replacing any notifier already stored
+// there.
+func (r *Registry[T]) Register(key string, n T) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.notifiers == nil {
+		r.notifiers = make(map[string]T)
+	}
+	r.notifiers[key] = n
+}
+
+// Get returns the notifier stored under key.
+func (r *Registry[T]) Get(key string) (T, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	n, ok := r.notifiers[key]
+	return n, ok
+}
+
+// NotifyAll notifies every registered notifier in key order and returns
+// any failures as a *BatchError. The registry isn't locked while
+// notifying, so notifiers may register or look up others.
+func (r *Registry[T]) NotifyAll(ctx context.Context) error {
+	r.mu.RLock()
+	keys := make([]string, 0, len(r.notifiers))
+	for key := range r.notifiers {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	ns := make([]Notifier, len(keys))
+	for i, key := range keys {
+		ns[i] = r.notifiers[key]
+	}
+	r.mu.RUnlock()
+
+	return SendNotifications(ctx, ns...)
+}
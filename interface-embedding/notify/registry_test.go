@@ -0,0 +1,83 @@
+package notify_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+func TestRegistryOverwrite(t *testing.T) {
+	var r notify.Registry[*notify.MockNotifier]
+	first, second := &notify.MockNotifier{Name: "first"}, &notify.MockNotifier{Name: "second"}
+	r.Register("ops", first)
+	r.Register("ops", second)
+
+	got, ok := r.Get("ops")
+	if !ok || got != second {
+		t.Errorf("Get(ops) = %v, %v, want the second registration", got, ok)
+	}
+	if _, ok := r.Get("missing"); ok {
+		t.Error("Get(missing) found a notifier")
+	}
+
+	if err := r.NotifyAll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if first.Calls() != 0 || second.Calls() != 1 {
+		t.Errorf("calls = %d, %d, want only the replacement notified", first.Calls(), second.Calls())
+	}
+}
+
+func TestRegistryNotifyAll(t *testing.T) {
+	errA, errC := errors.New("a failed"), errors.New("c failed")
+	var order notify.CallOrder
+	var r notify.Registry[*notify.MockNotifier]
+	for _, m := range []*notify.MockNotifier{
+		{Name: "c", Err: errC, Order: &order},
+		{Name: "a", Err: errA, Order: &order},
+		{Name: "b", Order: &order},
+	} {
+		r.Register(m.Name, m)
+	}
+
+	err := r.NotifyAll(context.Background())
+	if !errors.Is(err, errA) || !errors.Is(err, errC) {
+		t.Errorf("NotifyAll() = %v, want both failures", err)
+	}
+	var be *notify.BatchError
+	if !errors.As(err, &be) || len(be.Errors()) != 2 {
+		t.Errorf("NotifyAll() = %v, want a *BatchError with 2 failures", err)
+	}
+	if got := strings.Join(order.Names(), ","); got != "a,b,c" {
+		t.Errorf("notified in order %s, want a,b,c", got)
+	}
+}
+
+func TestRegistryConcurrent(t *testing.T) {
+	var r notify.Registry[notify.Notifier]
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			key := fmt.Sprint("n", i%5)
+			for range 50 {
+				r.Register(key, &notify.MockNotifier{})
+				r.Get(key)
+			}
+			r.NotifyAll(context.Background())
+		}()
+	}
+	wg.Wait()
+
+	for i := range 5 {
+		if _, ok := r.Get(fmt.Sprint("n", i)); !ok {
+			t.Errorf("n%d missing after concurrent registration", i)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package notify
+
+import "context"
+
+// requestIDKey is the context key under which WithRequestID stores the ID.
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of ctx carrying id, a request or
+// correlation ID for tracing. Notifications sent with the context append
+// it to their output line and log it as request_id. An empty id leaves
+// ctx as it is.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	if id == "" {
+		return ctx
+	}
+
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the ID set with WithRequestID, and whether
+// there is one.
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDKey{}).(string)
+	return id, ok
+}
@@ -0,0 +1,80 @@
+package notify_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+func TestRequestID(t *testing.T) {
+	ctx := notify.WithRequestID(context.Background(), "req-42")
+	if id, ok := notify.RequestIDFromContext(ctx); !ok || id != "req-42" {
+		t.Errorf("RequestIDFromContext() = %q, %v, want req-42", id, ok)
+	}
+	if id, ok := notify.RequestIDFromContext(context.Background()); ok || id != "" {
+		t.Errorf("RequestIDFromContext() without an ID = %q, %v, want none", id, ok)
+	}
+	if got := notify.WithRequestID(ctx, ""); got != ctx {
+		t.Error("WithRequestID() with an empty ID changed the context")
+	}
+}
+
+func TestNotifyRequestID(t *testing.T) {
+	tests := []struct {
+		name    string
+		id      string
+		n       func(opts ...notify.UserOption) (notify.Notifier, error)
+		wantOut string
+		wantLog string
+	}{
+		{"user with an ID", "req-42", func(opts ...notify.UserOption) (notify.Notifier, error) {
+			return notify.NewUser("Janet Jones", "janet@email.com", opts...)
+		}, "Sending user email to Janet Jones<janet@email.com> request_id=req-42\n", "req-42"},
+		{"user without", "", func(opts ...notify.UserOption) (notify.Notifier, error) {
+			return notify.NewUser("Janet Jones", "janet@email.com", opts...)
+		}, "Sending user email to Janet Jones<janet@email.com>\n", ""},
+		{"admin with an ID", "req-42", func(opts ...notify.UserOption) (notify.Notifier, error) {
+			var adminOpts []notify.AdminOption
+			for _, opt := range opts {
+				adminOpts = append(adminOpts, opt)
+			}
+			return notify.NewAdmin("Lisa Smith", "lisa@email.com", adminOpts...)
+		}, "Sending admin email to Lisa Smith<lisa@email.com> with level normal request_id=req-42\n", "req-42"},
+		{"sms with an ID", "req-42", func(opts ...notify.UserOption) (notify.Notifier, error) {
+			u, err := notify.NewUser("Janet Jones", "janet@email.com", opts...)
+			if err != nil {
+				return nil, err
+			}
+			return &notify.SMSUser{User: *u, Phone: "+15555550100"}, nil
+		}, "Sending user SMS to Janet Jones<+15555550100> request_id=req-42\n", ""},
+		{"ID sanitized", "req\n42", func(opts ...notify.UserOption) (notify.Notifier, error) {
+			return notify.NewUser("Janet Jones", "janet@email.com", opts...)
+		}, "Sending user email to Janet Jones<janet@email.com> request_id=req\\n42\n", "req\n42"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			h := &captureHandler{}
+			n, err := tt.n(notify.WithWriter(&buf), notify.WithLogger(slog.New(h)))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx := notify.WithRequestID(context.Background(), tt.id)
+			if err := n.Notify(ctx); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.wantOut {
+				t.Errorf("output = %q, want %q", got, tt.wantOut)
+			}
+			for _, r := range h.attrs() {
+				if got := r["request_id"]; got != tt.wantLog {
+					t.Errorf("logged request_id = %q, want %q", got, tt.wantLog)
+				}
+			}
+		})
+	}
+}
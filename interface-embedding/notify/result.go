@@ -0,0 +1,85 @@
+package notify
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// Result describes the outcome of one notification sent with Send.
+type Result struct {
+	Recipient string
+	Channel   string
+	StartedAt time.Time
+	Duration  time.Duration
+
+	// Attempt is how many times the notification was tried: more than one
+	// when a retrying wrapper such as RetryNotifier tried again, and zero
+	// when the context was done before anything was tried.
+	Attempt int
+
+	// DeliveredBy is the notifier that delivered when a FallbackNotifier
+	// chose between several, and nil otherwise. When several fallbacks
+	// deliver in one send, such as through a MultiNotifier, it is the
+	// first to finish.
+	DeliveredBy Notifier
+
+	Err error
+}
+
+// attemptsKey is the context key under which Send counts attempts.
+type attemptsKey struct{}
+
+// Send delivers through n like SendNotification, which it calls, and also
+// reports who was notified, when, for how long and in how many attempts.
+// The returned error is the same as Result.Err.
+func Send(ctx context.Context, n Notifier) (Result, error) {
+	var attempts atomic.Int64
+	var delivered atomic.Pointer[Notifier]
+	started := ctx.Err() == nil
+
+	r := Result{StartedAt: time.Now()}
+	r.Recipient, r.Channel = AddressOf(n)
+	ctx = context.WithValue(ctx, attemptsKey{}, &attempts)
+	ctx = context.WithValue(ctx, deliveredKey{}, &delivered)
+	r.Err = SendNotification(ctx, n)
+	r.Duration = time.Since(r.StartedAt)
+	if d := delivered.Load(); d != nil && r.Err == nil {
+		r.DeliveredBy = *d
+	}
+
+	r.Attempt = int(attempts.Load())
+	if r.Attempt == 0 && started {
+		r.Attempt = 1
+	}
+
+	return r, r.Err
+}
+
+// countAttempt records one delivery attempt against the Send call that ctx
+// came from, if any. Wrappers that try more than once call it before each
+// try.
+func countAttempt(ctx context.Context) {
+	if attempts, ok := ctx.Value(attemptsKey{}).(*atomic.Int64); ok {
+		attempts.Add(1)
+	}
+}
+
+// deliveredKey is the context key under which Send learns which notifier
+// delivered.
+type deliveredKey struct{}
+
+// deliveredBy records n as the notifier that delivered for the Send call
+// that ctx came from, unless one has been recorded already. Fallbacks
+// nested inside others finish first, so the innermost, which names the
+// notifier that actually delivered, wins.
+func deliveredBy(ctx context.Context, n Notifier) {
+	if delivered, ok := ctx.Value(deliveredKey{}).(*atomic.Pointer[Notifier]); ok {
+		delivered.CompareAndSwap(nil, &n)
+	}
+}
+
+// resultErrors returns the Err of each result, in order.
+func resultErrors(results []Result) []error {
+	return Map(results, func(r Result) error { return r.Err })
+}
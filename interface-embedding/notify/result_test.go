@@ -0,0 +1,161 @@
+package notify_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+func TestSend(t *testing.T) {
+	janet := &notify.User{Name: "Janet Jones", Email: "janet@email.com"}
+	permanent := notify.Permanent(errors.New("bad address"))
+
+	tests := []struct {
+		name         string
+		errs         []error
+		cancelled    bool
+		wantErr      error
+		wantAttempt  int
+		wantDuration time.Duration
+	}{
+		{"success", nil, false, nil, 1, 0},
+		{"retried success", []error{errFlaky, errFlaky}, false, nil, 3, 3 * time.Millisecond},
+		{"retries exhausted", []error{errFlaky, errFlaky, errFlaky}, false, errFlaky, 3, 3 * time.Millisecond},
+		{"permanent failure", []error{permanent}, false, permanent, 1, 0},
+		{"cancelled before start", nil, true, context.Canceled, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancelled {
+				cancel()
+			}
+			n := &notify.RetryNotifier{
+				Notifier:    &addressed{Notifier: &notify.MockNotifier{Errs: tt.errs}, to: janet},
+				MaxAttempts: 3,
+				BaseDelay:   time.Millisecond,
+				Sleep:       time.Sleep,
+			}
+
+			before := time.Now()
+			r, err := notify.Send(ctx, n)
+			if !errors.Is(err, tt.wantErr) || r.Err != err {
+				t.Fatalf("Send() = %v, Result.Err %v, want %v for both", err, r.Err, tt.wantErr)
+			}
+			if r.Recipient != "janet@email.com" || r.Channel != "email" {
+				t.Errorf("Result addressed to %q via %q, want janet@email.com via email", r.Recipient, r.Channel)
+			}
+			if r.Attempt != tt.wantAttempt {
+				t.Errorf("Result.Attempt = %d, want %d", r.Attempt, tt.wantAttempt)
+			}
+			if r.StartedAt.Before(before) || r.StartedAt.After(time.Now()) {
+				t.Errorf("Result.StartedAt = %v, want it during the call", r.StartedAt)
+			}
+			// The backoff waits 1ms then 2ms between the three attempts.
+			if r.Duration < tt.wantDuration {
+				t.Errorf("Result.Duration = %v, want at least %v", r.Duration, tt.wantDuration)
+			}
+			if r.DeliveredBy != nil {
+				t.Errorf("Result.DeliveredBy = %v, want nil without a fallback", r.DeliveredBy)
+			}
+		})
+	}
+}
+
+func TestSendMatchesSendNotification(t *testing.T) {
+	errDown := errors.New("server down")
+	tests := []struct {
+		name string
+		n    func(buf *bytes.Buffer) notify.Notifier
+	}{
+		{"user", func(buf *bytes.Buffer) notify.Notifier {
+			u, _ := notify.NewUser("Janet Jones", "janet@email.com", quiet(buf)...)
+			return u
+		}},
+		{"invalid user", func(buf *bytes.Buffer) notify.Notifier {
+			u := &notify.User{Name: "Janet Jones", Email: "janet"}
+			u.SetOutput(buf)
+			return u
+		}},
+		{"failing mock", func(*bytes.Buffer) notify.Notifier { return &notify.MockNotifier{Err: errDown} }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var legacyOut, sendOut bytes.Buffer
+			legacy := notify.SendNotification(context.Background(), tt.n(&legacyOut))
+			_, err := notify.Send(context.Background(), tt.n(&sendOut))
+
+			if (legacy == nil) != (err == nil) || (legacy != nil && legacy.Error() != err.Error()) {
+				t.Errorf("Send() = %v, SendNotification() = %v, want the same error", err, legacy)
+			}
+			if legacyOut.String() != sendOut.String() {
+				t.Errorf("Send() wrote %q, SendNotification() wrote %q, want the same output", sendOut.String(), legacyOut.String())
+			}
+		})
+	}
+}
+
+func TestSendBatchResults(t *testing.T) {
+	errDown := errors.New("server down")
+	var buf bytes.Buffer
+	janet, err := notify.NewUser("Janet Jones", "janet@email.com", quiet(&buf)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ns := []notify.Notifier{
+		janet,
+		&addressed{Notifier: &notify.MockNotifier{Err: errDown}, to: &notify.User{Email: "bob@email.com"}},
+		&notify.RetryNotifier{
+			Notifier:    &addressed{Notifier: &notify.MockNotifier{Errs: []error{errFlaky}}, to: &notify.User{Email: "lisa@email.com"}},
+			MaxAttempts: 2,
+			Sleep:       func(time.Duration) {},
+		},
+	}
+
+	results := notify.SendBatchResults(context.Background(), ns, 2)
+	want := []struct {
+		recipient string
+		attempt   int
+		err       error
+	}{
+		{"janet@email.com", 1, nil},
+		{"bob@email.com", 1, errDown},
+		{"lisa@email.com", 2, nil},
+	}
+	if len(results) != len(want) {
+		t.Fatalf("got %d results, want %d", len(results), len(want))
+	}
+	for i, w := range want {
+		r := results[i]
+		if r.Recipient != w.recipient || r.Attempt != w.attempt || !errors.Is(r.Err, w.err) || r.StartedAt.IsZero() {
+			t.Errorf("results[%d] = %+v, want %s after %d attempt(s) with error %v", i, r, w.recipient, w.attempt, w.err)
+		}
+	}
+
+	// Against legacy SendBatch the errors line up one for one.
+	for i, err := range notify.SendBatch(ns[:2], 2) {
+		if !errors.Is(err, want[i].err) {
+			t.Errorf("SendBatch()[%d] = %v, want %v", i, err, want[i].err)
+		}
+	}
+}
+
+func TestSendBatchResultsCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	ns := []notify.Notifier{&notify.User{Name: "Janet Jones", Email: "janet@email.com"}, &notify.MockNotifier{}}
+
+	for i, r := range notify.SendBatchResults(ctx, ns, 1) {
+		if r.Attempt != 0 || !errors.Is(r.Err, context.Canceled) {
+			t.Errorf("results[%d] = %+v, want an unstarted, cancelled result", i, r)
+		}
+	}
+	if r := notify.SendBatchResults(ctx, ns, 1)[0]; r.Recipient != "janet@email.com" {
+		t.Errorf("unstarted result addressed to %q, want janet@email.com", r.Recipient)
+	}
+}
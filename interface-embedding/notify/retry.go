@@ -0,0 +1,138 @@
+package notify
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// RetryNotifier retries the embedded Notifier when it fails. By default it
+// waits BaseDelay after the first failure and doubles the wait after each
+// one that follows. Errors that IsRetryable rejects, such as those marked
+// with Permanent, are returned immediately.
+type RetryNotifier struct {
+	Notifier
+	MaxAttempts int
+	BaseDelay   time.Duration
+
+	// Backoff returns how long to wait after the given failed attempt,
+	// counting from 1. It overrides BaseDelay when set.
+	Backoff func(attempt int) time.Duration
+
+	// Sleep waits between attempts. It defaults to waiting on Clock, or on
+	// a timer when Clock is nil too, giving up early once ctx is done. It
+	// can be replaced so tests don't have to wait; ctx is checked again
+	// when it returns.
+	Sleep func(time.Duration)
+
+	// Clock times the waits between attempts when Sleep is nil.
+	Clock Clock
+}
+
+// WithRetry wraps n so that it's attempted up to attempts times, backing
+// off exponentially from backoff between failures.
+func WithRetry(n Notifier, attempts int, backoff time.Duration) Notifier {
+	return &RetryNotifier{Notifier: n, MaxAttempts: attempts, BaseDelay: backoff}
+}
+
+// Notify calls the embedded Notifier until it succeeds, fails with an error
+// that isn't retryable, or MaxAttempts is reached, and returns the error
+// from the last attempt. If ctx is done while it waits between attempts,
+// it stops waiting and returns ctx.Err().
+func (r *RetryNotifier) Notify(ctx context.Context) error {
+	attempts := max(r.MaxAttempts, 1)
+	backoff := r.Backoff
+	if backoff == nil {
+		backoff = ExponentialBackoff(r.BaseDelay, false)
+	}
+
+	for attempt := 1; ; attempt++ {
+		countAttempt(ctx)
+		err := r.Notifier.Notify(ctx)
+		if err == nil || attempt == attempts || !IsRetryable(err) || ctx.Err() != nil {
+			return err
+		}
+
+		if err := r.wait(ctx, backoff(attempt)); err != nil {
+			return err
+		}
+	}
+}
+
+// wait pauses for d before the next attempt, returning ctx.Err() if ctx
+// is done first.
+func (r *RetryNotifier) wait(ctx context.Context, d time.Duration) error {
+	if r.Sleep != nil {
+		r.Sleep(d)
+		return ctx.Err()
+	}
+
+	var after <-chan time.Time
+	if r.Clock != nil {
+		after = r.Clock.After(d)
+	} else {
+		t := time.NewTimer(d)
+		defer t.Stop()
+		after = t.C
+	}
+	select {
+	case <-after:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Unwrap returns the notifier being retried.
+func (r *RetryNotifier) Unwrap() Notifier {
+	return r.Notifier
+}
+
+// FixedBackoff waits the same delay after every failed attempt.
+func FixedBackoff(delay time.Duration) func(attempt int) time.Duration {
+	return func(int) time.Duration {
+		return delay
+	}
+}
+
+// ExponentialBackoff waits base after the first failed attempt and doubles
+// the wait each time after that, up to the longest time.Duration rather
+// than overflowing. With jitter, each wait is picked at random
+// between half and all of that value so that many retrying callers don't
+// stay in lockstep.
+func ExponentialBackoff(base time.Duration, jitter bool) func(attempt int) time.Duration {
+	if jitter {
+		return JitterBackoff(base, SystemRand)
+	}
+
+	return func(attempt int) time.Duration {
+		return doubled(base, attempt)
+	}
+}
+
+// JitterBackoff is ExponentialBackoff with jitter drawn from rng, so that
+// a seeded Rand gives the same waits on every run.
+func JitterBackoff(base time.Duration, rng Rand) func(attempt int) time.Duration {
+	return func(attempt int) time.Duration {
+		d := doubled(base, attempt)
+		if d > 1 {
+			d = d/2 + time.Duration(rng.Int64N(int64(d/2)))
+		}
+
+		return d
+	}
+}
+
+// doubled returns base doubled attempt-1 times, saturating at the longest
+// time.Duration instead of overflowing into a negative one.
+func doubled(base time.Duration, attempt int) time.Duration {
+	shift := max(attempt-1, 0)
+	if base <= 0 || shift == 0 {
+		return base
+	}
+	if shift >= 63 || base > math.MaxInt64>>shift {
+		return math.MaxInt64
+	}
+
+	return base << shift
+}
@@ -0,0 +1,219 @@
+package notify_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+func TestRetryNotifier(t *testing.T) {
+	errFlaky := errors.New("flaky")
+
+	tests := []struct {
+		name         string
+		errs         []error
+		maxAttempts  int
+		wantAttempts int
+		wantSleeps   []time.Duration
+		wantErr      error
+	}{
+		{"fails twice then succeeds", []error{errFlaky, errFlaky, nil}, 5, 3,
+			[]time.Duration{10 * time.Millisecond, 20 * time.Millisecond}, nil},
+		{"first try", []error{nil}, 5, 1, nil, nil},
+		{"exhausted", []error{errFlaky, errFlaky, errFlaky, errFlaky}, 3, 3,
+			[]time.Duration{10 * time.Millisecond, 20 * time.Millisecond}, errFlaky},
+		{"permanent", []error{notify.Permanent(errFlaky), nil}, 5, 1, nil, errFlaky},
+		{"zero attempts tries once", []error{errFlaky}, 0, 1, nil, errFlaky},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &notify.MockNotifier{Errs: tt.errs}
+			var sleeps []time.Duration
+			r := &notify.RetryNotifier{
+				Notifier:    mock,
+				MaxAttempts: tt.maxAttempts,
+				BaseDelay:   10 * time.Millisecond,
+				Sleep:       func(d time.Duration) { sleeps = append(sleeps, d) },
+			}
+
+			err := r.Notify(context.Background())
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Notify() = %v, want %v", err, tt.wantErr)
+			}
+			if mock.Calls() != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", mock.Calls(), tt.wantAttempts)
+			}
+			if !slices.Equal(sleeps, tt.wantSleeps) {
+				t.Errorf("sleeps = %v, want %v", sleeps, tt.wantSleeps)
+			}
+		})
+	}
+}
+
+func TestRetryNotifierClock(t *testing.T) {
+	errFlaky := errors.New("flaky")
+	start := time.Date(2026, time.January, 1, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		backoff  func() func(attempt int) time.Duration
+		wantWait time.Duration
+	}{
+		{"exponential", func() func(int) time.Duration { return nil }, 10*time.Millisecond + 20*time.Millisecond},
+		{"seeded jitter", func() func(int) time.Duration {
+			return notify.JitterBackoff(10*time.Millisecond, notify.NewSeededRand(1))
+		}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Two runs with the same seed wait the same simulated time.
+			var waited []time.Duration
+			for range 2 {
+				clock := notify.NewSimClock(start)
+				r := &notify.RetryNotifier{
+					Notifier:    &notify.MockNotifier{Errs: []error{errFlaky, errFlaky, nil}},
+					MaxAttempts: 3,
+					BaseDelay:   10 * time.Millisecond,
+					Backoff:     tt.backoff(),
+					Clock:       clock,
+				}
+				if err := r.Notify(context.Background()); err != nil {
+					t.Fatalf("Notify() = %v", err)
+				}
+				waited = append(waited, clock.Now().Sub(start))
+			}
+			if waited[0] != waited[1] {
+				t.Errorf("runs waited %v and %v, want the same", waited[0], waited[1])
+			}
+			if tt.wantWait != 0 && waited[0] != tt.wantWait {
+				t.Errorf("waited %v on the clock, want %v", waited[0], tt.wantWait)
+			}
+			if waited[0] <= 0 || waited[0] > 30*time.Millisecond {
+				t.Errorf("waited %v on the clock, want up to 30ms", waited[0])
+			}
+		})
+	}
+}
+
+func TestRetryNotifierCancelledWhileWaiting(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	mock := &notify.MockNotifier{Err: errors.New("flaky")}
+	r := &notify.RetryNotifier{Notifier: mock, MaxAttempts: 3, BaseDelay: time.Hour}
+
+	time.AfterFunc(10*time.Millisecond, cancel)
+	done := make(chan error, 1)
+	go func() { done <- r.Notify(ctx) }()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Notify() = %v, want %v", err, context.Canceled)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Notify kept waiting after ctx was cancelled")
+	}
+	if mock.Calls() != 1 {
+		t.Errorf("attempts = %d, want 1", mock.Calls())
+	}
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	backoff := notify.ExponentialBackoff(time.Second, false)
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{3, 4 * time.Second},
+		{34, time.Second << 33},
+		{35, math.MaxInt64},
+		{64, math.MaxInt64},
+		{1000, math.MaxInt64},
+	}
+	for _, tt := range tests {
+		if got := backoff(tt.attempt); got != tt.want {
+			t.Errorf("backoff(%d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+
+	jitter := notify.JitterBackoff(time.Second, notify.NewSeededRand(1))
+	for _, attempt := range []int{1, 10, 64, 1000} {
+		if d := jitter(attempt); d <= 0 {
+			t.Errorf("jitter(%d) = %v, want a positive wait", attempt, d)
+		}
+	}
+}
+
+func TestWithRetry(t *testing.T) {
+	errFlaky := errors.New("flaky")
+	tests := []struct {
+		name         string
+		errs         []error
+		wantAttempts int
+		wantErr      error
+	}{
+		{"flaky", []error{errFlaky, errFlaky, nil}, 3, nil},
+		{"permanent", []error{notify.Permanent(errFlaky)}, 1, errFlaky},
+		{"wrapped permanent", []error{fmt.Errorf("send: %w", notify.ErrPermanent)}, 1, notify.ErrPermanent},
+		{"invalid recipient", []error{notify.ErrInvalidEmail}, 1, notify.ErrInvalidEmail},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &notify.MockNotifier{Errs: tt.errs}
+			err := notify.WithRetry(mock, 4, time.Microsecond).Notify(context.Background())
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Notify() = %v, want %v", err, tt.wantErr)
+			}
+			if mock.Calls() != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", mock.Calls(), tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestIsPermanent(t *testing.T) {
+	err := errors.New("bad request")
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{err, false},
+		{notify.Permanent(err), true},
+		{fmt.Errorf("wrapped: %w", notify.Permanent(err)), true},
+		{notify.ErrEmptyEmail, true},
+	}
+	for _, tt := range tests {
+		if got := notify.IsPermanent(tt.err); got != tt.want {
+			t.Errorf("IsPermanent(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+	if notify.Permanent(nil) != nil {
+		t.Error("Permanent(nil) != nil")
+	}
+	if !errors.Is(notify.Permanent(err), err) {
+		t.Error("Permanent(err) doesn't match err")
+	}
+}
+
+func TestJitterBackoff(t *testing.T) {
+	backoff := notify.ExponentialBackoff(100*time.Millisecond, true)
+	for attempt := 1; attempt <= 5; attempt++ {
+		full := 100 * time.Millisecond << (attempt - 1)
+		for range 20 {
+			if d := backoff(attempt); d < full/2 || d > full {
+				t.Fatalf("backoff(%d) = %v, want between %v and %v", attempt, d, full/2, full)
+			}
+		}
+	}
+	if got := notify.FixedBackoff(time.Second)(9); got != time.Second {
+		t.Errorf("FixedBackoff(1s)(9) = %v, want 1s", got)
+	}
+}
@@ -0,0 +1,46 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+)
+
+// ErrNoBackends is returned by a RoundRobinNotifier with no backends.
+var ErrNoBackends = errors.New("notify: round robin has no backends")
+
+// RoundRobinNotifier spreads notifications evenly across equivalent
+// Backends, such as several SMTP relays, sending each one through the
+// backend after the one used last. It is safe for concurrent use.
+type RoundRobinNotifier struct {
+	Backends []Notifier
+
+	// Failover, when set, moves on to the following backends in turn if
+	// the chosen one fails with an error that IsRetryable accepts.
+	Failover bool
+
+	next atomic.Uint64
+}
+
+// Notify delivers through the next backend. If every backend tried fails,
+// their errors are joined in the order they were tried.
+func (r *RoundRobinNotifier) Notify(ctx context.Context) error {
+	if len(r.Backends) == 0 {
+		return ErrNoBackends
+	}
+
+	start := int((r.next.Add(1) - 1) % uint64(len(r.Backends)))
+	var errs []error
+	for i := range len(r.Backends) {
+		err := r.Backends[(start+i)%len(r.Backends)].Notify(ctx)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, err)
+		if !r.Failover || !IsRetryable(err) || ctx.Err() != nil {
+			break
+		}
+	}
+
+	return errors.Join(errs...)
+}
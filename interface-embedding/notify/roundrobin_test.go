@@ -0,0 +1,107 @@
+package notify_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+func TestRoundRobinNotifier(t *testing.T) {
+	permanent := notify.Permanent(errors.New("bad address"))
+
+	tests := []struct {
+		name      string
+		errs      map[string]error
+		failover  bool
+		sends     int
+		wantOrder []string
+		wantErrs  []error
+	}{
+		{name: "cycles through backends", sends: 4,
+			wantOrder: []string{"a", "b", "c", "a"}},
+		{name: "failure without failover", errs: map[string]error{"a": errFlaky}, sends: 2,
+			wantOrder: []string{"a", "b"}, wantErrs: []error{errFlaky, nil}},
+		{name: "failover to the next backend", errs: map[string]error{"a": errFlaky}, failover: true, sends: 2,
+			wantOrder: []string{"a", "b", "b"}, wantErrs: []error{nil, nil}},
+		{name: "failover wraps around", errs: map[string]error{"c": errFlaky}, failover: true, sends: 3,
+			wantOrder: []string{"a", "b", "c", "a"}, wantErrs: []error{nil, nil, nil}},
+		{name: "no failover on permanent errors", errs: map[string]error{"a": permanent}, failover: true, sends: 1,
+			wantOrder: []string{"a"}, wantErrs: []error{permanent}},
+		{name: "every backend fails", errs: map[string]error{"a": errFlaky, "b": errFlaky, "c": errFlaky}, failover: true, sends: 1,
+			wantOrder: []string{"a", "b", "c"}, wantErrs: []error{errFlaky}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := new(notify.CallOrder)
+			rr := &notify.RoundRobinNotifier{Failover: tt.failover}
+			for _, name := range []string{"a", "b", "c"} {
+				rr.Backends = append(rr.Backends, &notify.MockNotifier{Name: name, Err: tt.errs[name], Order: order})
+			}
+
+			for i := range tt.sends {
+				err := rr.Notify(context.Background())
+				if tt.wantErrs != nil && !errors.Is(err, tt.wantErrs[i]) {
+					t.Errorf("send %d: Notify() = %v, want %v", i, err, tt.wantErrs[i])
+				}
+			}
+			if got := order.Names(); !slices.Equal(got, tt.wantOrder) {
+				t.Errorf("backends called %q, want %q", got, tt.wantOrder)
+			}
+		})
+	}
+}
+
+func TestRoundRobinNotifierJoinsErrors(t *testing.T) {
+	errA, errB := &notify.NotificationError{Retryable: true, Err: errors.New("a down")}, &notify.NotificationError{Retryable: true, Err: errors.New("b down")}
+	rr := &notify.RoundRobinNotifier{
+		Backends: []notify.Notifier{&notify.MockNotifier{Err: errA}, &notify.MockNotifier{Err: errB}},
+		Failover: true,
+	}
+	err := rr.Notify(context.Background())
+	if !errors.Is(err, errA) || !errors.Is(err, errB) {
+		t.Errorf("Notify() = %v, want both backends' errors", err)
+	}
+}
+
+func TestRoundRobinNotifierNoBackends(t *testing.T) {
+	var rr notify.RoundRobinNotifier
+	if err := rr.Notify(context.Background()); !errors.Is(err, notify.ErrNoBackends) {
+		t.Errorf("Notify() = %v, want %v", err, notify.ErrNoBackends)
+	}
+}
+
+func TestRoundRobinNotifierConcurrent(t *testing.T) {
+	const goroutines, perGoroutine = 8, 300
+	mocks := []*notify.MockNotifier{{}, {}, {}}
+	rr := &notify.RoundRobinNotifier{}
+	for _, m := range mocks {
+		rr.Backends = append(rr.Backends, m)
+	}
+
+	var wg sync.WaitGroup
+	for range goroutines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range perGoroutine {
+				if err := rr.Notify(context.Background()); err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	// The shared counter hands out backends strictly in turn, so the
+	// split is exact however the goroutines interleave.
+	want := goroutines * perGoroutine / len(mocks)
+	for i, m := range mocks {
+		if m.Calls() != want {
+			t.Errorf("backend %d hit %d times, want %d", i, m.Calls(), want)
+		}
+	}
+}
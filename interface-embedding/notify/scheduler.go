@@ -0,0 +1,147 @@
+package notify
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Scheduler sends notifications at a future time or on a repeating
+// interval, driven by a Clock. Each registration runs on its own goroutine
+// until the scheduler is stopped.
+type Scheduler struct {
+	// OnError, when set before anything is scheduled, receives the errors
+	// from scheduled sends.
+	OnError func(error)
+
+	clock  Clock
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	stopped bool
+	wg      sync.WaitGroup
+}
+
+// NewScheduler returns a Scheduler driven by clock, or by SystemClock when
+// clock is nil.
+func NewScheduler(clock Clock) *Scheduler {
+	if clock == nil {
+		clock = SystemClock
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Scheduler{clock: clock, ctx: ctx, cancel: cancel}
+}
+
+// Every sends n once per interval, starting one interval from now. If a
+// send runs past one or more ticks, the missed ticks are skipped rather
+// than sent back to back. Like time.NewTicker, it panics if interval is
+// not positive.
+func (s *Scheduler) Every(n Notifier, interval time.Duration) {
+	if interval <= 0 {
+		panic("notify: non-positive interval for Scheduler.Every")
+	}
+	s.spawn(func() {
+		next := s.clock.Now().Add(interval)
+		for s.sleepUntil(next) {
+			s.send(n)
+
+			now := s.clock.Now()
+			next = next.Add(interval)
+			for !next.After(now) {
+				next = next.Add(interval)
+			}
+		}
+	})
+}
+
+// At sends n once at t.
+func (s *Scheduler) At(n Notifier, t time.Time) {
+	s.spawn(func() {
+		if s.sleepUntil(t) {
+			s.send(n)
+		}
+	})
+}
+
+// Stop cancels every registration, including sends in progress, and waits
+// for their goroutines to exit. Nothing scheduled afterwards runs.
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	s.stopped = true
+	s.mu.Unlock()
+
+	s.cancel()
+	s.wg.Wait()
+}
+
+// Start implements Lifecycle. A Scheduler runs each registration as soon
+// as it is made, so there is nothing to start.
+func (s *Scheduler) Start(ctx context.Context) error {
+	return nil
+}
+
+// Shutdown is Stop, giving up with ctx.Err() if ctx is done before every
+// goroutine has exited. Later calls return nil once they have.
+func (s *Scheduler) Shutdown(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.Stop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// spawn runs fn on a goroutine tracked by Stop, unless already stopped.
+func (s *Scheduler) spawn(fn func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopped {
+		return
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		fn()
+	}()
+}
+
+// sleepUntil waits until t and reports false if the scheduler was stopped
+// first.
+func (s *Scheduler) sleepUntil(t time.Time) bool {
+	select {
+	case <-s.clock.After(t.Sub(s.clock.Now())):
+		return s.ctx.Err() == nil
+	case <-s.ctx.Done():
+		return false
+	}
+}
+
+// send delivers n and reports any error to OnError.
+func (s *Scheduler) send(n Notifier) {
+	if err := SendNotification(s.ctx, n); err != nil && s.OnError != nil {
+		s.OnError(err)
+	}
+}
+
+// Schedule sends n once at the given time on a timer of its own, without
+// needing a Scheduler. Calling the returned cancel before then prevents
+// the send; afterwards it does nothing. Errors from the send are passed to
+// onError when it is non-nil.
+func Schedule(n Notifier, at time.Time, onError func(error)) (cancel func()) {
+	t := time.AfterFunc(time.Until(at), func() {
+		if err := SendNotification(context.Background(), n); err != nil && onError != nil {
+			onError(err)
+		}
+	})
+
+	return func() { t.Stop() }
+}
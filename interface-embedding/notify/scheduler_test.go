@@ -0,0 +1,217 @@
+package notify_test
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+// checkNoLeaks fails the test if goroutines started during it are still
+// running a few seconds after it ends.
+func checkNoLeaks(t *testing.T) {
+	t.Helper()
+	before := runtime.NumGoroutine()
+	t.Cleanup(func() {
+		deadline := time.Now().Add(5 * time.Second)
+		for runtime.NumGoroutine() > before {
+			if time.Now().After(deadline) {
+				buf := make([]byte, 1<<16)
+				t.Errorf("%d goroutines leaked:\n%s", runtime.NumGoroutine()-before, buf[:runtime.Stack(buf, true)])
+				return
+			}
+			time.Sleep(time.Millisecond)
+		}
+	})
+}
+
+func TestSchedulerEvery(t *testing.T) {
+	checkNoLeaks(t)
+	clock := newFakeClock()
+	s := notify.NewScheduler(clock)
+	defer s.Stop()
+	mock := &notify.MockNotifier{}
+	s.Every(mock, 2*time.Second)
+
+	tests := []struct {
+		advance time.Duration
+		want    int
+	}{
+		{time.Second, 0},
+		{time.Second, 1},
+		{2 * time.Second, 2},
+		{1999 * time.Millisecond, 2},
+		{time.Millisecond, 3},
+	}
+	for _, tt := range tests {
+		if !clock.BlockUntil(1) {
+			t.Fatal("scheduler never waited on the clock")
+		}
+		clock.Advance(tt.advance)
+		waitFor(t, "the scheduled send", func() bool { return mock.Calls() >= tt.want })
+		if mock.Calls() != tt.want {
+			t.Fatalf("calls = %d after %v, want %d", mock.Calls(), clock.Now().Sub(time.Date(2026, time.January, 1, 9, 0, 0, 0, time.UTC)), tt.want)
+		}
+	}
+}
+
+func TestSchedulerSkipsMissedTicks(t *testing.T) {
+	checkNoLeaks(t)
+	clock := newFakeClock()
+	s := notify.NewScheduler(clock)
+	defer s.Stop()
+
+	// The first send takes 5s, past the ticks at 4s and 6s.
+	var sentAt []time.Time
+	slow := notify.NotifierFunc(func(context.Context) error {
+		sentAt = append(sentAt, clock.Now())
+		if len(sentAt) == 1 {
+			clock.Advance(5 * time.Second)
+		}
+		return nil
+	})
+	start := clock.Now()
+	s.Every(slow, 2*time.Second)
+
+	clock.BlockUntil(1)
+	clock.Advance(2 * time.Second)
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+	clock.BlockUntil(1)
+	s.Stop()
+
+	if len(sentAt) != 2 || sentAt[1].Sub(start) != 8*time.Second {
+		t.Errorf("sent at %v, want 2s and then 8s with the missed ticks skipped", sentAt)
+	}
+}
+
+func TestSchedulerAt(t *testing.T) {
+	checkNoLeaks(t)
+	clock := newFakeClock()
+	s := notify.NewScheduler(clock)
+	defer s.Stop()
+	errDown := errors.New("down")
+	errs := make(chan error, 1)
+	s.OnError = func(err error) { errs <- err }
+
+	s.At(&notify.MockNotifier{Err: errDown}, clock.Now().Add(time.Minute))
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+
+	select {
+	case err := <-errs:
+		if !errors.Is(err, errDown) {
+			t.Errorf("OnError got %v, want %v", err, errDown)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("At never sent")
+	}
+}
+
+func TestSchedulerStop(t *testing.T) {
+	checkNoLeaks(t)
+	clock := newFakeClock()
+	s := notify.NewScheduler(clock)
+	mock := &notify.MockNotifier{}
+	for range 5 {
+		s.Every(mock, time.Second)
+	}
+	s.At(mock, clock.Now().Add(time.Hour))
+	clock.BlockUntil(6)
+
+	s.Stop()
+	s.Every(mock, time.Second)
+	clock.Advance(2 * time.Hour)
+	if mock.Calls() != 0 {
+		t.Errorf("calls = %d after Stop, want 0", mock.Calls())
+	}
+	if err := s.Shutdown(context.Background()); err != nil {
+		t.Errorf("Shutdown() after Stop = %v", err)
+	}
+}
+
+func TestSchedulerEveryRejectsNonPositiveInterval(t *testing.T) {
+	checkNoLeaks(t)
+	for _, interval := range []time.Duration{0, -time.Second} {
+		t.Run(interval.String(), func(t *testing.T) {
+			s := notify.NewScheduler(newFakeClock())
+			defer s.Stop()
+
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Every(%v) didn't panic", interval)
+				}
+			}()
+			s.Every(&notify.MockNotifier{}, interval)
+		})
+	}
+}
+
+func TestSchedule(t *testing.T) {
+	errDown := errors.New("server down")
+
+	tests := []struct {
+		name    string
+		err     error
+		delay   time.Duration
+		wantErr error
+	}{
+		{"fires", nil, 10 * time.Millisecond, nil},
+		{"reports errors", errDown, 10 * time.Millisecond, errDown},
+		{"past time fires at once", nil, -time.Hour, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sent := make(chan struct{})
+			errs := make(chan error, 1)
+			n := notify.NotifierFunc(func(context.Context) error {
+				close(sent)
+				return tt.err
+			})
+
+			start := time.Now()
+			notify.Schedule(n, start.Add(tt.delay), func(err error) { errs <- err })
+			select {
+			case <-sent:
+			case <-time.After(5 * time.Second):
+				t.Fatal("Schedule never sent")
+			}
+			if elapsed := time.Since(start); elapsed < tt.delay {
+				t.Errorf("sent after %v, want at least %v", elapsed, tt.delay)
+			}
+			if tt.wantErr == nil {
+				return
+			}
+			select {
+			case err := <-errs:
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("onError got %v, want %v", err, tt.wantErr)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("onError never called")
+			}
+		})
+	}
+}
+
+func TestScheduleCancel(t *testing.T) {
+	mock := &notify.MockNotifier{Err: errors.New("server down")}
+	cancel := notify.Schedule(mock, time.Now().Add(20*time.Millisecond), nil)
+	cancel()
+
+	time.Sleep(50 * time.Millisecond)
+	if mock.Calls() != 0 {
+		t.Errorf("calls = %d after cancel, want 0", mock.Calls())
+	}
+	// Cancelling again, or after the time has passed, is harmless.
+	cancel()
+}
+
+func TestScheduleNilOnError(t *testing.T) {
+	mock := &notify.MockNotifier{Err: errors.New("server down")}
+	notify.Schedule(mock, time.Now(), nil)
+	waitFor(t, "the scheduled send", func() bool { return mock.Calls() == 1 })
+}
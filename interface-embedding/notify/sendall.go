@@ -0,0 +1,159 @@
+package notify
+
+import (
+	"context"
+	"runtime"
+	"time"
+)
+
+// SendOption configures SendAll.
+type SendOption func(*sendAllConfig)
+
+type sendAllConfig struct {
+	dedup   bool
+	workers int
+	timeout time.Duration
+}
+
+// WithDedup makes SendAll send each notification once: notifiers whose
+// DedupKey matches an earlier one in the slice are skipped with
+// ErrDuplicateSuppressed.
+func WithDedup() SendOption {
+	return func(c *sendAllConfig) {
+		c.dedup = true
+	}
+}
+
+// WithConcurrency sets how many notifications SendAll sends at once. It
+// defaults to GOMAXPROCS.
+func WithConcurrency(workers int) SendOption {
+	return func(c *sendAllConfig) {
+		c.workers = workers
+	}
+}
+
+// WithSendTimeout bounds each send SendAll makes, as WithTimeout does.
+func WithSendTimeout(d time.Duration) SendOption {
+	return func(c *sendAllConfig) {
+		c.timeout = d
+	}
+}
+
+// OutcomeStatus is what became of one notification in a Report.
+type OutcomeStatus int
+
+const (
+	// OutcomeSent means the notification was delivered.
+	OutcomeSent OutcomeStatus = iota
+
+	// OutcomeFailed means the notification was tried and failed.
+	OutcomeFailed
+
+	// OutcomeSkipped means the notification was never tried, because it
+	// was a duplicate or ctx was done before its turn.
+	OutcomeSkipped
+)
+
+// String returns "sent", "failed" or "skipped".
+func (s OutcomeStatus) String() string {
+	switch s {
+	case OutcomeSent:
+		return "sent"
+	case OutcomeFailed:
+		return "failed"
+	case OutcomeSkipped:
+		return "skipped"
+	default:
+		return "unknown"
+	}
+}
+
+// Outcome is the Result of one notification in a Report and what it came
+// to. Skipped outcomes have a zero Attempt and an Err saying why.
+type Outcome struct {
+	Result
+	Status OutcomeStatus
+}
+
+// Report summarizes a SendAll call. Outcomes has one entry per notifier,
+// in the order they were given, and the counts add up to its length.
+type Report struct {
+	Sent     int
+	Failed   int
+	Skipped  int
+	Outcomes []Outcome
+}
+
+// Err returns the first failure in the report, or nil when nothing failed.
+// Skipped notifications aren't failures.
+func (r Report) Err() error {
+	for _, o := range r.Outcomes {
+		if o.Status == OutcomeFailed {
+			return o.Err
+		}
+	}
+
+	return nil
+}
+
+// SendAll delivers through every notifier and reports what happened to
+// each. Once ctx is done no further notifications are started, and those
+// left over are reported as skipped with ctx.Err(); those already started
+// finish, bounded by any WithSendTimeout, so the report is complete
+// whenever SendAll returns.
+func SendAll(ctx context.Context, notifiers []Notifier, opts ...SendOption) Report {
+	cfg := sendAllConfig{workers: runtime.GOMAXPROCS(0)}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.workers <= 0 {
+		cfg.workers = runtime.GOMAXPROCS(0)
+	}
+
+	outcomes := make([]Outcome, len(notifiers))
+	var (
+		send  []Notifier
+		index []int
+		seen  = make(map[string]bool)
+	)
+	for i, n := range notifiers {
+		if cfg.dedup {
+			key := DedupKey(n)
+			if seen[key] {
+				outcomes[i] = Outcome{Result: unstarted(n, ErrDuplicateSuppressed), Status: OutcomeSkipped}
+				continue
+			}
+			seen[key] = true
+		}
+		if cfg.timeout > 0 {
+			n = WithTimeout(n, cfg.timeout)
+		}
+		send = append(send, n)
+		index = append(index, i)
+	}
+
+	for j, r := range sendPool(ctx, context.WithoutCancel(ctx), send, cfg.workers) {
+		o := Outcome{Result: r, Status: OutcomeSent}
+		switch {
+		case r.StartedAt.IsZero():
+			o.Status = OutcomeSkipped
+		case r.Err != nil:
+			o.Status = OutcomeFailed
+		}
+		outcomes[index[j]] = o
+	}
+
+	report := Report{Outcomes: outcomes}
+	for _, o := range outcomes {
+		switch o.Status {
+		case OutcomeSent:
+			report.Sent++
+		case OutcomeFailed:
+			report.Failed++
+		case OutcomeSkipped:
+			report.Skipped++
+		}
+	}
+
+	return report
+}
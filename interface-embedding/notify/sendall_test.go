@@ -0,0 +1,182 @@
+package notify_test
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+func TestSendAll(t *testing.T) {
+	errDown := errors.New("server down")
+	users := discardUsers(t, 3)
+	failing := &addressed{Notifier: &notify.MockNotifier{Err: errDown}, to: &notify.User{Email: "bob@email.com"}}
+
+	tests := []struct {
+		name       string
+		notifiers  []notify.Notifier
+		opts       []notify.SendOption
+		want       []notify.OutcomeStatus
+		wantErr    error
+		wantCounts [3]int // sent, failed, skipped
+	}{
+		{
+			name:       "clean run",
+			notifiers:  users,
+			want:       []notify.OutcomeStatus{notify.OutcomeSent, notify.OutcomeSent, notify.OutcomeSent},
+			wantCounts: [3]int{3, 0, 0},
+		},
+		{
+			name:       "some failures",
+			notifiers:  []notify.Notifier{users[0], failing, users[1], failing},
+			want:       []notify.OutcomeStatus{notify.OutcomeSent, notify.OutcomeFailed, notify.OutcomeSent, notify.OutcomeFailed},
+			wantErr:    errDown,
+			wantCounts: [3]int{2, 2, 0},
+		},
+		{
+			name:       "duplicates sent without dedup",
+			notifiers:  []notify.Notifier{users[0], users[0]},
+			want:       []notify.OutcomeStatus{notify.OutcomeSent, notify.OutcomeSent},
+			wantCounts: [3]int{2, 0, 0},
+		},
+		{
+			name:       "duplicates skipped with dedup",
+			notifiers:  []notify.Notifier{users[0], users[1], users[0], users[1], users[2]},
+			opts:       []notify.SendOption{notify.WithDedup()},
+			want:       []notify.OutcomeStatus{notify.OutcomeSent, notify.OutcomeSent, notify.OutcomeSkipped, notify.OutcomeSkipped, notify.OutcomeSent},
+			wantCounts: [3]int{3, 0, 2},
+		},
+		{
+			name:       "timeout",
+			notifiers:  []notify.Notifier{users[0], blocking},
+			opts:       []notify.SendOption{notify.WithSendTimeout(10 * time.Millisecond)},
+			want:       []notify.OutcomeStatus{notify.OutcomeSent, notify.OutcomeFailed},
+			wantErr:    notify.ErrNotifyTimeout,
+			wantCounts: [3]int{1, 1, 0},
+		},
+		{
+			name: "empty",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := notify.SendAll(context.Background(), tt.notifiers, tt.opts...)
+
+			var got []notify.OutcomeStatus
+			for _, o := range report.Outcomes {
+				got = append(got, o.Status)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("statuses = %v, want %v", got, tt.want)
+			}
+			if counts := [3]int{report.Sent, report.Failed, report.Skipped}; counts != tt.wantCounts {
+				t.Errorf("sent, failed, skipped = %v, want %v", counts, tt.wantCounts)
+			}
+			if err := report.Err(); !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Errorf("Err() = %v, want %v", err, tt.wantErr)
+			}
+
+			// Outcomes line up with the notifiers they report on.
+			for i, o := range report.Outcomes {
+				recipient, _ := notify.AddressOf(tt.notifiers[i])
+				if o.Recipient != recipient {
+					t.Errorf("outcome %d is for %q, want %q", i, o.Recipient, recipient)
+				}
+				switch o.Status {
+				case notify.OutcomeSkipped:
+					if !errors.Is(o.Err, notify.ErrDuplicateSuppressed) || o.Attempt != 0 {
+						t.Errorf("outcome %d = %+v, want an unattempted duplicate", i, o)
+					}
+				case notify.OutcomeSent:
+					if o.Err != nil || o.Attempt != 1 || o.StartedAt.IsZero() {
+						t.Errorf("outcome %d = %+v, want one successful attempt", i, o)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestSendAllCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var started atomic.Int64
+	ns := make([]notify.Notifier, 10)
+	for i, u := range discardUsers(t, len(ns)) {
+		ns[i] = &addressed{Notifier: notify.NotifierFunc(func(ctx context.Context) error {
+			if started.Add(1) == 3 {
+				cancel()
+			}
+			return ctx.Err()
+		}), to: u.(notify.Addresser)}
+	}
+
+	report := notify.SendAll(ctx, ns, notify.WithConcurrency(1))
+	if report.Sent != 3 || report.Failed != 0 || report.Skipped != 7 {
+		t.Fatalf("sent, failed, skipped = %d, %d, %d, want 3, 0, 7", report.Sent, report.Failed, report.Skipped)
+	}
+	if len(report.Outcomes) != len(ns) {
+		t.Fatalf("report has %d outcomes, want %d", len(report.Outcomes), len(ns))
+	}
+	for i, o := range report.Outcomes[3:] {
+		recipient, _ := notify.AddressOf(ns[i+3])
+		if o.Status != notify.OutcomeSkipped || !errors.Is(o.Err, context.Canceled) || o.Attempt != 0 || o.Recipient != recipient {
+			t.Errorf("outcome %d = %+v, want %s skipped with %v", i+3, o, recipient, context.Canceled)
+		}
+	}
+	if err := report.Err(); err != nil {
+		t.Errorf("Err() = %v, want nil: skipped notifications aren't failures", err)
+	}
+}
+
+func TestSendAllConcurrency(t *testing.T) {
+	tests := []struct {
+		name    string
+		workers int
+		max     int64
+	}{
+		{"one", 1, 1},
+		{"four", 4, 4},
+		{"defaults to GOMAXPROCS", 0, int64(runtime.GOMAXPROCS(0))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var g gauge
+			ns := make([]notify.Notifier, 16)
+			for i := range ns {
+				ns[i] = g.notifier(nil)
+			}
+
+			report := notify.SendAll(context.Background(), ns, notify.WithConcurrency(tt.workers))
+			if report.Sent != len(ns) {
+				t.Errorf("sent %d, want %d", report.Sent, len(ns))
+			}
+			if peak := g.peak.Load(); peak > tt.max {
+				t.Errorf("%d sends ran at once, want at most %d", peak, tt.max)
+			}
+		})
+	}
+}
+
+func TestOutcomeStatusString(t *testing.T) {
+	tests := []struct {
+		s    notify.OutcomeStatus
+		want string
+	}{
+		{notify.OutcomeSent, "sent"},
+		{notify.OutcomeFailed, "failed"},
+		{notify.OutcomeSkipped, "skipped"},
+		{notify.OutcomeStatus(42), "unknown"},
+	}
+	for _, tt := range tests {
+		if got := tt.s.String(); got != tt.want {
+			t.Errorf("OutcomeStatus(%d).String() = %q, want %q", int(tt.s), got, tt.want)
+		}
+	}
+}
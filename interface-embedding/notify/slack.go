@@ -0,0 +1,78 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// SlackNotifier posts a message to a Slack channel through an incoming
+// webhook.
+type SlackNotifier struct {
+	WebhookURL string
+	Channel    string
+	Text       string
+
+	// Client sends the webhook request. It defaults to http.DefaultClient
+	// and can be replaced to point at a test server.
+	Client *http.Client
+}
+
+// slackPayload is the JSON body accepted by Slack incoming webhooks.
+type slackPayload struct {
+	Channel string `json:"channel"`
+	Text    string `json:"text"`
+}
+
+// Notify posts the message and treats any non-2xx response as a failure.
+func (s *SlackNotifier) Notify(ctx context.Context) error {
+	body, err := json.Marshal(slackPayload{Channel: s.Channel, Text: s.Text})
+	if err != nil {
+		return s.fail(err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return s.fail(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return s.retryable(err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 500 {
+		return s.retryable(fmt.Errorf("webhook responded with status %d", resp.StatusCode))
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return s.fail(fmt.Errorf("webhook responded with status %d", resp.StatusCode))
+	}
+
+	return nil
+}
+
+// Address reports the Slack channel and the Slack channel kind.
+func (s *SlackNotifier) Address() (recipient, channel string) {
+	return s.Channel, "slack"
+}
+
+// fail wraps err in a NotificationError for the notifier's channel.
+func (s *SlackNotifier) fail(err error) error {
+	return &NotificationError{Recipient: s.Channel, Channel: "slack", Err: err}
+}
+
+// retryable is fail for transient errors, such as a network failure or a
+// 5xx response.
+func (s *SlackNotifier) retryable(err error) error {
+	return &NotificationError{Recipient: s.Channel, Channel: "slack", Retryable: true, Err: err}
+}
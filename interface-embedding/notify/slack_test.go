@@ -0,0 +1,78 @@
+package notify_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+func TestSlackNotifier(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        int
+		wantErr       bool
+		wantRetryable bool
+	}{
+		{"ok", http.StatusOK, false, false},
+		{"no content", http.StatusNoContent, false, false},
+		{"bad request", http.StatusBadRequest, true, false},
+		{"server error", http.StatusBadGateway, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var method, contentType string
+			var payload map[string]string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				method, contentType = r.Method, r.Header.Get("Content-Type")
+				body, _ := io.ReadAll(r.Body)
+				if err := json.Unmarshal(body, &payload); err != nil {
+					t.Errorf("request body %q isn't JSON: %v", body, err)
+				}
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			s := &notify.SlackNotifier{WebhookURL: srv.URL, Channel: "#ops", Text: "disk full", Client: srv.Client()}
+			err := s.Notify(context.Background())
+
+			if method != http.MethodPost || contentType != "application/json" {
+				t.Errorf("request = %s with Content-Type %q, want POST application/json", method, contentType)
+			}
+			if payload["channel"] != "#ops" || payload["text"] != "disk full" {
+				t.Errorf("payload = %v, want channel #ops and text %q", payload, "disk full")
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Notify() = %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil {
+				return
+			}
+			if !strings.Contains(err.Error(), strconv.Itoa(tt.status)) {
+				t.Errorf("Notify() = %v, want it to include status %d", err, tt.status)
+			}
+			if got := notify.IsRetryable(err); got != tt.wantRetryable {
+				t.Errorf("IsRetryable(%v) = %v, want %v", err, got, tt.wantRetryable)
+			}
+		})
+	}
+}
+
+func TestSlackNotifierUnreachable(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	url := srv.URL
+	srv.Close()
+
+	err := (&notify.SlackNotifier{WebhookURL: url, Channel: "#ops"}).Notify(context.Background())
+	var ne *notify.NotificationError
+	if !errors.As(err, &ne) || !ne.Retryable || ne.Channel != "slack" {
+		t.Errorf("Notify() = %v, want a retryable slack *NotificationError", err)
+	}
+}
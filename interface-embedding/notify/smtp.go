@@ -0,0 +1,354 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// ErrSMTPConnect is returned when the SMTP server can't be reached or the
+// connection fails before the message is accepted.
+var ErrSMTPConnect = errors.New("notify: smtp connection failed")
+
+// ErrSMTPAuth is returned when the SMTP server rejects the credentials.
+var ErrSMTPAuth = errors.New("notify: smtp authentication failed")
+
+// ErrSMTPRejected is returned when the SMTP server answers with a permanent
+// 5xx failure, such as an unknown mailbox.
+var ErrSMTPRejected = errors.New("notify: smtp server rejected the message")
+
+// SMTPDialer opens connections to an SMTP server. *net.Dialer implements
+// it, and tests can supply one that connects to a fake server.
+type SMTPDialer interface {
+	DialContext(ctx context.Context, network, address string) (net.Conn, error)
+}
+
+// SMTPNotifier emails a user through an SMTP server, sending an RFC 5322
+// message that is plain text, or multipart/alternative when there is an
+// HTML body too.
+type SMTPNotifier struct {
+	// Addr is the server's host and port, such as "smtp.email.com:587".
+	Addr string
+	From string
+	To   *User
+
+	// Subject defaults to "Notification". Body and HTML default to the
+	// plain-text and HTML versions of the user's own notification; see
+	// User.Body. An empty HTML sends plain text only.
+	Subject string
+	Body    string
+	HTML    string
+
+	// Auth, when set, authenticates after any STARTTLS.
+	Auth smtp.Auth
+
+	// TLSConfig, when set, requires the connection to be upgraded with
+	// STARTTLS. Its ServerName defaults to Addr's host.
+	TLSConfig *tls.Config
+
+	// Timeout bounds each send, from dialing to QUIT. Zero means no limit
+	// beyond the context passed to Notify.
+	Timeout time.Duration
+
+	// Dialer connects to Addr. It defaults to a *net.Dialer.
+	Dialer SMTPDialer
+
+	// Clock stamps the Date header. It defaults to SystemClock.
+	Clock Clock
+}
+
+// Notify builds the message and sends it. Connection problems, rejected
+// credentials and 5xx replies are reported as ErrSMTPConnect, ErrSMTPAuth
+// and ErrSMTPRejected respectively, wrapped in a NotificationError.
+func (s *SMTPNotifier) Notify(ctx context.Context) error {
+	if s.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.Timeout)
+		defer cancel()
+	}
+
+	msg, err := s.message(ctx)
+	if err != nil {
+		return s.fail(err)
+	}
+
+	return s.fail(s.send(ctx, msg))
+}
+
+// Address reports the user's email address and the email channel.
+func (s *SMTPNotifier) Address() (recipient, channel string) {
+	return s.To.Address()
+}
+
+// send delivers msg over a single SMTP session.
+func (s *SMTPNotifier) send(ctx context.Context, msg []byte) error {
+	host, _, err := net.SplitHostPort(s.Addr)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrSMTPConnect, err)
+	}
+
+	dialer := s.Dialer
+	if dialer == nil {
+		dialer = &net.Dialer{}
+	}
+	conn, err := dialer.DialContext(ctx, "tcp", s.Addr)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrSMTPConnect, err)
+	}
+	defer conn.Close()
+
+	// net/smtp doesn't take a context, so the deadline is applied to the
+	// connection instead.
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		return smtpError(ErrSMTPConnect, err)
+	}
+	defer c.Close()
+
+	if s.TLSConfig != nil {
+		if ok, _ := c.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("%w: server does not support STARTTLS", ErrSMTPConnect)
+		}
+		cfg := s.TLSConfig.Clone()
+		if cfg.ServerName == "" {
+			cfg.ServerName = host
+		}
+		if err := c.StartTLS(cfg); err != nil {
+			return smtpError(ErrSMTPConnect, err)
+		}
+	}
+	if s.Auth != nil {
+		if err := c.Auth(s.Auth); err != nil {
+			return fmt.Errorf("%w: %w", ErrSMTPAuth, err)
+		}
+	}
+
+	if err := c.Mail(s.From); err != nil {
+		return smtpError(ErrSMTPConnect, err)
+	}
+	if err := c.Rcpt(s.To.Email); err != nil {
+		return smtpError(ErrSMTPConnect, err)
+	}
+	w, err := c.Data()
+	if err != nil {
+		return smtpError(ErrSMTPConnect, err)
+	}
+	if _, err := w.Write(msg); err != nil {
+		return smtpError(ErrSMTPConnect, err)
+	}
+	if err := w.Close(); err != nil {
+		return smtpError(ErrSMTPConnect, err)
+	}
+
+	if err := c.Quit(); err != nil {
+		return smtpError(ErrSMTPConnect, err)
+	}
+
+	return nil
+}
+
+// smtpError wraps err in ErrSMTPRejected when the server replied with a
+// 5xx code, and in fallback otherwise.
+func smtpError(fallback, err error) error {
+	var reply *textproto.Error
+	if errors.As(err, &reply) && reply.Code >= 500 {
+		return fmt.Errorf("%w: %w", ErrSMTPRejected, err)
+	}
+
+	return fmt.Errorf("%w: %w", fallback, err)
+}
+
+// message renders the RFC 5322 message: headers, a blank line, then the
+// body, with every line ending in CRLF. Any limits in ctx apply to the
+// subject and the plain-text body; the HTML body isn't truncated, since
+// cutting markup would break it. Whatever the limits, the message keeps
+// to SMTP's 998-octet line length: the subject is capped and folded, and
+// bodies with longer lines are sent quoted-printable.
+func (s *SMTPNotifier) message(ctx context.Context) ([]byte, error) {
+	from, err := NormalizeEmail(s.From)
+	if err != nil {
+		return nil, err
+	}
+	to, err := NormalizeEmail(s.To.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	subject := s.Subject
+	if subject == "" {
+		subject = "Notification"
+	}
+	if strings.ContainsAny(subject, "\r\n") {
+		return nil, errors.New("subject must be a single line")
+	}
+	if subject, err = limitSubject(ctx, subject); err != nil {
+		return nil, err
+	}
+	subject = TruncateRunes(subject, smtpMaxSubjectRunes)
+
+	body, html := s.Body, s.HTML
+	if body == "" {
+		b, err := s.To.Body()
+		if err != nil {
+			return nil, err
+		}
+		body = b.Plain
+		if html == "" {
+			html = b.HTML
+		}
+	}
+	if body, err = limitBody(ctx, body); err != nil {
+		return nil, err
+	}
+
+	clock := s.Clock
+	if clock == nil {
+		clock = SystemClock
+	}
+	id, err := messageID(from)
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", to)
+	b.WriteString(foldHeader("Subject", mime.QEncoding.Encode("utf-8", subject)))
+	fmt.Fprintf(&b, "Date: %s\r\n", clock.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&b, "Message-ID: %s\r\n", id)
+	b.WriteString("MIME-Version: 1.0\r\n")
+
+	if html == "" {
+		b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+		if hasLongLine(body) {
+			b.WriteString("Content-Transfer-Encoding: quoted-printable\r\n")
+		}
+		b.WriteString("\r\n")
+		writeText(&b, body)
+		return b.Bytes(), nil
+	}
+
+	// The parts go from plainest to richest, so clients show the last one
+	// they can display.
+	mw := multipart.NewWriter(&b)
+	fmt.Fprintf(&b, "Content-Type: multipart/alternative; boundary=%q\r\n", mw.Boundary())
+	b.WriteString("\r\n")
+	for _, part := range []struct{ contentType, text string }{
+		{"text/plain; charset=utf-8", body},
+		{"text/html; charset=utf-8", html},
+	} {
+		header := textproto.MIMEHeader{"Content-Type": {part.contentType}}
+		if hasLongLine(part.text) {
+			header.Set("Content-Transfer-Encoding", "quoted-printable")
+		}
+		w, err := mw.CreatePart(header)
+		if err != nil {
+			return nil, err
+		}
+		writeText(w, part.text)
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+// smtpMaxLine is the most octets SMTP allows on a line, not counting the
+// CRLF.
+const smtpMaxLine = 998
+
+// smtpMaxSubjectRunes caps subjects so that even one with no spaces to
+// fold at fits on a single header line.
+const smtpMaxSubjectRunes = 900
+
+// foldHeader formats a header line, folding value at spaces so that lines
+// stay within the recommended 78 characters where its words allow.
+func foldHeader(name, value string) string {
+	var b strings.Builder
+	b.WriteString(name + ":")
+	width := b.Len()
+	for i, word := range strings.Split(value, " ") {
+		if i > 0 && width+1+len(word) > 78 {
+			b.WriteString("\r\n")
+			width = 0
+		}
+		b.WriteString(" " + word)
+		width += 1 + len(word)
+	}
+	b.WriteString("\r\n")
+
+	return b.String()
+}
+
+// hasLongLine reports whether text has a line too long to send over SMTP
+// as it is.
+func hasLongLine(text string) bool {
+	for line := range strings.Lines(text) {
+		if len(strings.TrimRight(line, "\r\n")) > smtpMaxLine {
+			return true
+		}
+	}
+
+	return false
+}
+
+// writeText writes text to w with every line ending in CRLF, encoded as
+// quoted-printable when hasLongLine says it must be. Writes to the message
+// buffer can't fail, so errors are ignored.
+func writeText(w io.Writer, text string) {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	if hasLongLine(text) {
+		if !strings.HasSuffix(text, "\n") {
+			text += "\n"
+		}
+		qp := quotedprintable.NewWriter(w)
+		io.WriteString(qp, text)
+		qp.Close()
+		return
+	}
+
+	for line := range strings.Lines(text) {
+		io.WriteString(w, strings.TrimSuffix(line, "\n")+"\r\n")
+	}
+}
+
+// messageID returns a random Message-ID in the sender's domain.
+func messageID(from string) (string, error) {
+	var buf [16]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		return "", err
+	}
+	domain := from[strings.LastIndexByte(from, '@')+1:]
+
+	return "<" + hex.EncodeToString(buf[:]) + "@" + domain + ">", nil
+}
+
+// fail wraps a non-nil err in a NotificationError for the recipient. Only
+// connection failures are retryable; rejected credentials and messages
+// fail the same way every time.
+func (s *SMTPNotifier) fail(err error) error {
+	if err == nil {
+		return nil
+	}
+	retryable := errors.Is(err, ErrSMTPConnect) && !errors.Is(err, context.Canceled)
+
+	return &NotificationError{Recipient: s.To.Email, Channel: "email", Retryable: retryable, Err: err}
+}
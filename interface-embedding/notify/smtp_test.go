@@ -0,0 +1,377 @@
+package notify_test
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+// fakeSMTP is an in-process SMTP server that accepts one session at a time
+// and records what the client sent. Replies can be overridden per command
+// verb, such as "RCPT", to script failures, and a silent server never
+// greets the client.
+type fakeSMTP struct {
+	replies map[string]string
+	silent  bool
+	addr    string
+
+	mu       sync.Mutex
+	commands []string
+	data     string
+}
+
+// startFakeSMTP starts s listening on a local port until the test ends.
+func startFakeSMTP(t *testing.T, s *fakeSMTP) *fakeSMTP {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.addr = ln.Addr().String()
+
+	var wg sync.WaitGroup
+	t.Cleanup(func() {
+		ln.Close()
+		wg.Wait()
+	})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			s.serve(conn)
+		}
+	}()
+
+	return s
+}
+
+func (s *fakeSMTP) serve(conn net.Conn) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	if s.silent {
+		// Never greet, so the client waits until its deadline.
+		conn.Read(make([]byte, 1))
+		return
+	}
+
+	tc := textproto.NewConn(conn)
+	tc.PrintfLine("220 fake.email.com ESMTP")
+	for {
+		line, err := tc.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, _, _ := strings.Cut(line, " ")
+		verb = strings.ToUpper(verb)
+		s.mu.Lock()
+		s.commands = append(s.commands, line)
+		s.mu.Unlock()
+
+		if reply, ok := s.replies[verb]; ok {
+			tc.PrintfLine("%s", reply)
+			if verb == "QUIT" {
+				return
+			}
+			continue
+		}
+		switch verb {
+		case "EHLO":
+			tc.PrintfLine("250-fake.email.com")
+			tc.PrintfLine("250 AUTH PLAIN")
+		case "AUTH":
+			tc.PrintfLine("235 2.7.0 authenticated")
+		case "DATA":
+			tc.PrintfLine("354 go ahead")
+			data, err := tc.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.data = string(data)
+			s.mu.Unlock()
+			tc.PrintfLine("250 2.0.0 queued")
+		case "QUIT":
+			tc.PrintfLine("221 bye")
+			return
+		default:
+			tc.PrintfLine("250 ok")
+		}
+	}
+}
+
+// session returns the commands received and the message data.
+func (s *fakeSMTP) session() ([]string, string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.commands, s.data
+}
+
+func TestSMTPNotifierWire(t *testing.T) {
+	srv := startFakeSMTP(t, &fakeSMTP{})
+	to := &notify.User{Name: "Janet Jones", Email: "janet@email.com"}
+	n := &notify.SMTPNotifier{
+		Addr:    srv.addr,
+		From:    "alerts@email.com",
+		To:      to,
+		Subject: "Disk full",
+		Body:    "The disk on db1 is full.\nPlease free some space.",
+		Auth:    smtp.PlainAuth("", "alerts", "secret", "127.0.0.1"),
+		Clock:   newFakeClock(),
+	}
+
+	if err := n.Notify(context.Background()); err != nil {
+		t.Fatalf("Notify() = %v", err)
+	}
+
+	commands, data := srv.session()
+	wantCommands := []string{"EHLO", "AUTH PLAIN", "MAIL FROM:<alerts@email.com>", "RCPT TO:<janet@email.com>", "DATA", "QUIT"}
+	if len(commands) != len(wantCommands) {
+		t.Fatalf("commands = %q, want %q", commands, wantCommands)
+	}
+	for i, want := range wantCommands {
+		if !strings.HasPrefix(commands[i], want) {
+			t.Errorf("command %d = %q, want it to start %q", i, commands[i], want)
+		}
+	}
+
+	id := regexp.MustCompile(`(?m)^Message-ID: <[0-9a-f]{32}@email\.com>$`)
+	if !id.MatchString(data) {
+		t.Errorf("message has no Message-ID in the sender's domain:\n%s", data)
+	}
+	want := "From: alerts@email.com\n" +
+		"To: janet@email.com\n" +
+		"Subject: Disk full\n" +
+		"Date: Thu, 01 Jan 2026 09:00:00 +0000\n" +
+		"Message-ID: ID\n" +
+		"MIME-Version: 1.0\n" +
+		"Content-Type: text/plain; charset=utf-8\n" +
+		"\n" +
+		"The disk on db1 is full.\n" +
+		"Please free some space.\n"
+	if got := id.ReplaceAllString(data, "Message-ID: ID"); got != want {
+		t.Errorf("message =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestSMTPNotifierDefaultBody(t *testing.T) {
+	srv := startFakeSMTP(t, &fakeSMTP{})
+	n := &notify.SMTPNotifier{
+		Addr: srv.addr,
+		From: "alerts@email.com",
+		To:   &notify.User{Name: "Janet Jones", Email: "janet@email.com"},
+	}
+
+	if err := n.Notify(context.Background()); err != nil {
+		t.Fatalf("Notify() = %v", err)
+	}
+	_, data := srv.session()
+	if !strings.Contains(data, "\nSubject: Notification\n") || !strings.HasSuffix(data, "\n\nSending user email to Janet Jones<janet@email.com>\n") {
+		t.Errorf("message doesn't default the subject and body:\n%s", data)
+	}
+}
+
+func TestSMTPNotifierErrors(t *testing.T) {
+	refused, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	refusedAddr := refused.Addr().String()
+	refused.Close()
+
+	tests := []struct {
+		name      string
+		replies   map[string]string
+		silent    bool
+		addr      string
+		configure func(*notify.SMTPNotifier)
+		want      error
+		retryable bool
+	}{
+		{name: "connection refused", addr: refusedAddr, want: notify.ErrSMTPConnect, retryable: true},
+		{name: "bad address", addr: "no-port", want: notify.ErrSMTPConnect, retryable: true},
+		{name: "auth rejected", replies: map[string]string{"AUTH": "535 5.7.8 bad credentials"},
+			configure: func(n *notify.SMTPNotifier) { n.Auth = smtp.PlainAuth("", "alerts", "wrong", "127.0.0.1") },
+			want:      notify.ErrSMTPAuth},
+		{name: "mailbox unknown", replies: map[string]string{"RCPT": "550 5.1.1 no such user"}, want: notify.ErrSMTPRejected},
+		{name: "message rejected", replies: map[string]string{"DATA": "554 5.6.0 spam"}, want: notify.ErrSMTPRejected},
+		{name: "temporary failure", replies: map[string]string{"MAIL": "451 4.3.0 try later"}, want: notify.ErrSMTPConnect, retryable: true},
+		{name: "no STARTTLS", configure: func(n *notify.SMTPNotifier) { n.TLSConfig = &tls.Config{} },
+			want: notify.ErrSMTPConnect, retryable: true},
+		{name: "timeout", silent: true, configure: func(n *notify.SMTPNotifier) { n.Timeout = 50 * time.Millisecond },
+			want: notify.ErrSMTPConnect, retryable: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr := tt.addr
+			if addr == "" {
+				addr = startFakeSMTP(t, &fakeSMTP{replies: tt.replies, silent: tt.silent}).addr
+			}
+			n := &notify.SMTPNotifier{
+				Addr: addr,
+				From: "alerts@email.com",
+				To:   &notify.User{Name: "Janet Jones", Email: "janet@email.com"},
+				Body: "hello",
+			}
+			if tt.configure != nil {
+				tt.configure(n)
+			}
+
+			err := n.Notify(context.Background())
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Notify() = %v, want %v", err, tt.want)
+			}
+			for _, other := range []error{notify.ErrSMTPConnect, notify.ErrSMTPAuth, notify.ErrSMTPRejected} {
+				if other != tt.want && errors.Is(err, other) {
+					t.Errorf("Notify() = %v, also matches %v", err, other)
+				}
+			}
+			if got := notify.IsRetryable(err); got != tt.retryable {
+				t.Errorf("IsRetryable(%v) = %v, want %v", err, got, tt.retryable)
+			}
+		})
+	}
+}
+
+// countingDialer dials with a real dialer and counts the connections.
+type countingDialer struct {
+	net.Dialer
+	mu    sync.Mutex
+	dials int
+}
+
+func (d *countingDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	d.mu.Lock()
+	d.dials++
+	d.mu.Unlock()
+
+	return d.Dialer.DialContext(ctx, network, address)
+}
+
+func TestSMTPNotifierDialer(t *testing.T) {
+	srv := startFakeSMTP(t, &fakeSMTP{})
+	dialer := &countingDialer{}
+	n := &notify.SMTPNotifier{
+		Addr:   srv.addr,
+		From:   "alerts@email.com",
+		To:     &notify.User{Name: "Janet Jones", Email: "janet@email.com"},
+		Body:   "hello",
+		Dialer: dialer,
+	}
+
+	for range 2 {
+		if err := n.Notify(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if dialer.dials != 2 {
+		t.Errorf("dialer used %d times, want once per send", dialer.dials)
+	}
+}
+
+func TestSMTPNotifierInvalidMessage(t *testing.T) {
+	tests := []struct {
+		name string
+		n    notify.SMTPNotifier
+	}{
+		{"bad from", notify.SMTPNotifier{From: "alerts", To: &notify.User{Email: "janet@email.com"}, Body: "x"}},
+		{"bad to", notify.SMTPNotifier{From: "alerts@email.com", To: &notify.User{Email: "janet"}, Body: "x"}},
+		{"multi-line subject", notify.SMTPNotifier{From: "alerts@email.com", To: &notify.User{Email: "janet@email.com"}, Subject: "a\r\nBcc: x@y.z", Body: "x"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dialer := &countingDialer{}
+			tt.n.Addr, tt.n.Dialer = "127.0.0.1:1", dialer
+			err := tt.n.Notify(context.Background())
+			if err == nil || notify.IsRetryable(err) {
+				t.Errorf("Notify() = %v, want a permanent error", err)
+			}
+			if dialer.dials != 0 {
+				t.Errorf("dialed %d times for an invalid message, want 0", dialer.dials)
+			}
+		})
+	}
+}
+
+func TestSMTPNotifierMultipart(t *testing.T) {
+	tests := []struct {
+		name      string
+		to        func(t *testing.T) *notify.User
+		html      string
+		wantPlain string
+		wantHTML  string
+	}{
+		{"explicit HTML", func(t *testing.T) *notify.User {
+			return &notify.User{Name: "Janet Jones", Email: "janet@email.com"}
+		}, "<p>The disk is <b>full</b>.</p>", "The disk is full.", "<p>The disk is <b>full</b>.</p>"},
+		{"user's HTML template", func(t *testing.T) *notify.User {
+			u, err := notify.NewUser("Janet <i>Jones</i>", "janet@email.com", notify.WithHTMLTemplate("<p>Hi {{.Name}}</p>"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			return u
+		}, "", "Sending user email to Janet <i>Jones</i><janet@email.com>", "<p>Hi Janet &lt;i&gt;Jones&lt;/i&gt;</p>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := startFakeSMTP(t, &fakeSMTP{})
+			n := &notify.SMTPNotifier{Addr: srv.addr, From: "alerts@email.com", To: tt.to(t), HTML: tt.html}
+			if tt.html != "" {
+				n.Body = tt.wantPlain
+			}
+			if err := n.Notify(context.Background()); err != nil {
+				t.Fatalf("Notify() = %v", err)
+			}
+
+			_, data := srv.session()
+			msg, err := mail.ReadMessage(strings.NewReader(data))
+			if err != nil {
+				t.Fatalf("ReadMessage() = %v\n%s", err, data)
+			}
+			mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+			if err != nil || mediaType != "multipart/alternative" || params["boundary"] == "" {
+				t.Fatalf("Content-Type = %q, want multipart/alternative with a boundary", msg.Header.Get("Content-Type"))
+			}
+
+			mr := multipart.NewReader(msg.Body, params["boundary"])
+			want := []struct{ contentType, body string }{
+				{"text/plain; charset=utf-8", tt.wantPlain},
+				{"text/html; charset=utf-8", tt.wantHTML},
+			}
+			for i, w := range want {
+				part, err := mr.NextPart()
+				if err != nil {
+					t.Fatalf("part %d: %v", i, err)
+				}
+				body, _ := io.ReadAll(part)
+				if ct := part.Header.Get("Content-Type"); ct != w.contentType {
+					t.Errorf("part %d Content-Type = %q, want %q", i, ct, w.contentType)
+				}
+				if got := strings.TrimRight(string(body), "\r\n"); got != w.body {
+					t.Errorf("part %d body = %q, want %q", i, got, w.body)
+				}
+			}
+			if _, err := mr.NextPart(); err != io.EOF {
+				t.Errorf("NextPart() after the HTML part = %v, want io.EOF", err)
+			}
+		})
+	}
+}
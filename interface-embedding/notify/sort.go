@@ -0,0 +1,33 @@
+package notify
+
+import (
+	"cmp"
+	"slices"
+)
+
+// SortByName sorts users by name, then by email, keeping users that tie
+// on both in their original order.
+func SortByName(users []*User) {
+	slices.SortStableFunc(users, func(a, b *User) int {
+		return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.Email, b.Email))
+	})
+}
+
+// SortAdminsByLevel sorts admins from most to least privileged, as ranked
+// by Level.Rank, so unknown levels come last. Admins with the same level
+// keep their original order.
+func SortAdminsByLevel(admins []*Admin) {
+	slices.SortStableFunc(admins, func(a, b *Admin) int {
+		return cmp.Compare(b.Level.Rank(), a.Level.Rank())
+	})
+}
+
+// TopN returns the n most privileged admins, ordered as SortAdminsByLevel
+// would order them, without reordering admins itself. It returns them all
+// when there are fewer than n.
+func TopN(admins []*Admin, n int) []*Admin {
+	sorted := slices.Clone(admins)
+	SortAdminsByLevel(sorted)
+
+	return sorted[:min(max(n, 0), len(sorted))]
+}
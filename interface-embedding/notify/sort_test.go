@@ -0,0 +1,105 @@
+package notify_test
+
+import (
+	"slices"
+	"testing"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+// emails returns the email of each user, in order.
+func emails(users []*notify.User) []string {
+	return notify.Map(users, func(u *notify.User) string { return u.Email })
+}
+
+// adminEmails returns the email of each admin, in order.
+func adminEmails(admins []*notify.Admin) []string {
+	return notify.Map(admins, func(a *notify.Admin) string { return a.Email })
+}
+
+func admin(email string, level notify.Level) *notify.Admin {
+	return &notify.Admin{User: notify.User{Name: email, Email: email}, Level: level}
+}
+
+func TestSortByName(t *testing.T) {
+	user := func(name, email string) *notify.User { return &notify.User{Name: name, Email: email} }
+
+	tests := []struct {
+		name  string
+		users []*notify.User
+		want  []int // positions in users, in sorted order
+	}{
+		{"nil", nil, []int{}},
+		{"by name", []*notify.User{user("Lisa", "l@email.com"), user("Bob", "b@email.com"), user("Janet", "j@email.com")}, []int{1, 2, 0}},
+		{"ties broken by email", []*notify.User{user("Janet", "z@email.com"), user("Janet", "a@email.com")}, []int{1, 0}},
+		{"full ties stable", []*notify.User{user("Janet", "j@email.com"), user("Bob", "b@email.com"), user("Janet", "j@email.com")}, []int{1, 0, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := notify.Map(tt.want, func(i int) *notify.User { return tt.users[i] })
+			notify.SortByName(tt.users)
+			if !slices.Equal(tt.users, want) {
+				t.Errorf("SortByName() = %q, want %q", emails(tt.users), emails(want))
+			}
+		})
+	}
+}
+
+func TestSortAdminsByLevel(t *testing.T) {
+	tests := []struct {
+		name   string
+		admins []*notify.Admin
+		want   []string
+	}{
+		{"empty", []*notify.Admin{}, []string{}},
+		{"root, super, normal", []*notify.Admin{admin("n@email.com", notify.LevelNormal), admin("r@email.com", notify.LevelRoot), admin("s@email.com", notify.LevelSuper)},
+			[]string{"r@email.com", "s@email.com", "n@email.com"}},
+		{"ties keep their order", []*notify.Admin{admin("s1@email.com", notify.LevelSuper), admin("r@email.com", notify.LevelRoot), admin("s2@email.com", notify.LevelSuper), admin("s3@email.com", notify.LevelSuper)},
+			[]string{"r@email.com", "s1@email.com", "s2@email.com", "s3@email.com"}},
+		{"unknown levels last and stable", []*notify.Admin{admin("x1@email.com", "intern"), admin("n@email.com", notify.LevelNormal), admin("x2@email.com", ""), admin("x3@email.com", "god")},
+			[]string{"n@email.com", "x1@email.com", "x2@email.com", "x3@email.com"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notify.SortAdminsByLevel(tt.admins)
+			if got := adminEmails(tt.admins); !slices.Equal(got, tt.want) {
+				t.Errorf("SortAdminsByLevel() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTopN(t *testing.T) {
+	admins := []*notify.Admin{
+		admin("n@email.com", notify.LevelNormal),
+		admin("s1@email.com", notify.LevelSuper),
+		admin("r@email.com", notify.LevelRoot),
+		admin("s2@email.com", notify.LevelSuper),
+	}
+
+	tests := []struct {
+		name string
+		n    int
+		want []string
+	}{
+		{"top one", 1, []string{"r@email.com"}},
+		{"ties in original order", 3, []string{"r@email.com", "s1@email.com", "s2@email.com"}},
+		{"more than there are", 10, []string{"r@email.com", "s1@email.com", "s2@email.com", "n@email.com"}},
+		{"zero", 0, []string{}},
+		{"negative", -1, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := slices.Clone(admins)
+			if got := adminEmails(notify.TopN(admins, tt.n)); !slices.Equal(got, tt.want) {
+				t.Errorf("TopN(%d) = %q, want %q", tt.n, got, tt.want)
+			}
+			if !slices.Equal(admins, before) {
+				t.Error("TopN() reordered its input")
+			}
+		})
+	}
+	if got := notify.TopN(nil, 3); len(got) != 0 {
+		t.Errorf("TopN(nil) = %v, want none", got)
+	}
+}
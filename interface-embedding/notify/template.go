@@ -0,0 +1,461 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"maps"
+	"math"
+	"os"
+	"reflect"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// Templates that render the same text as the English catalog messages, as
+// a starting point for WithTemplate.
+const (
+	DefaultUserTemplate  = "Sending user email to {{.Name}}<{{.Email}}>"
+	DefaultAdminTemplate = "Sending admin email to {{.Name}}<{{.Email}}> with level {{.Level}}"
+)
+
+// defaultFuncs are available to every message template.
+var defaultFuncs = template.FuncMap{
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+// UserOption configures a User in NewUser, or the embedded User in
+// NewAdmin.
+type UserOption func(*User) error
+
+// WithTemplate renders notifications from text instead of the catalog.
+// The template is parsed immediately so syntax errors are reported by the
+// constructor.
+func WithTemplate(text string) UserOption {
+	return func(u *User) error {
+		if _, err := u.parse(text); err != nil {
+			return err
+		}
+		u.tmpl = text
+
+		return nil
+	}
+}
+
+// WithHTMLTemplate also renders notifications as HTML from text, with the
+// same data as WithTemplate. It uses html/template, so the user's name and
+// other values are escaped for HTML. Notify still writes the plain-text
+// message; the HTML is what SMTPNotifier adds as a multipart alternative.
+func WithHTMLTemplate(text string) UserOption {
+	return func(u *User) error {
+		t, err := htmltemplate.New("html").
+			Funcs(htmltemplate.FuncMap(defaultFuncs)).
+			Funcs(htmltemplate.FuncMap(u.funcs)).
+			Option("missingkey=error").
+			Parse(text)
+		if err != nil {
+			return fmt.Errorf("notify: parse html template: %w", err)
+		}
+		if err := limitHTMLRanges(t); err != nil {
+			return fmt.Errorf("notify: parse html template: %w", err)
+		}
+		u.html, u.htmlErr = t, nil
+
+		return nil
+	}
+}
+
+// SetHTMLTemplate sets the template Body renders the HTML version of the
+// notification from, for a template that has already been parsed. It is
+// executed with the same data as WithTemplate, so the name is escaped for
+// HTML. A nil t removes the HTML body, leaving plain text only. It must
+// not be called while the user is being notified.
+//
+// The user keeps a copy of t with its ranges limited as WithTemplate's
+// are, so t itself is left as it was. Since html/template can't copy a
+// template once it has been executed, t mustn't have been; if it has, Body
+// reports the error.
+func (u *User) SetHTMLTemplate(t *htmltemplate.Template) {
+	u.html, u.htmlErr = nil, nil
+	if t == nil {
+		return
+	}
+
+	c, err := t.Clone()
+	if err == nil {
+		err = limitHTMLRanges(c)
+	}
+	if err != nil {
+		u.htmlErr = fmt.Errorf("notify: set html template: %w", err)
+		return
+	}
+	u.html = c
+}
+
+// WithFuncs makes additional functions available to message templates.
+// Options are applied in order, so WithFuncs must come before a
+// WithTemplate that uses the functions it adds.
+func WithFuncs(funcs template.FuncMap) UserOption {
+	return func(u *User) error {
+		merged := template.FuncMap{}
+		maps.Copy(merged, u.funcs)
+		maps.Copy(merged, funcs)
+		u.funcs = merged
+
+		return nil
+	}
+}
+
+// parse compiles text with the default and user-supplied functions. Keys
+// that are missing from the data are reported as errors rather than
+// rendered as "<no value>".
+func (u *User) parse(text string) (*template.Template, error) {
+	t, err := template.New("message").
+		Funcs(defaultFuncs).
+		Funcs(u.funcs).
+		Option("missingkey=error").
+		Parse(text)
+	if err == nil {
+		err = limitTextRanges(t)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("notify: parse template: %w", err)
+	}
+
+	return t, nil
+}
+
+// render executes text against data.
+func (u *User) render(text string, data map[string]any) (string, error) {
+	t, err := u.parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	return executeText(t, data)
+}
+
+// Render executes the template text with the user's Name, passed through
+// Sanitize, and Email.
+func (u *User) Render(text string) (string, error) {
+	return u.render(text, u.data())
+}
+
+// Render executes the template text with the admin's sanitized Name,
+// Email, and Level. Name and Email are promoted from the embedded User.
+func (a *Admin) Render(text string) (string, error) {
+	return a.render(text, a.data())
+}
+
+// data is what the user's templates are executed against.
+func (u *User) data() map[string]any {
+	return map[string]any{
+		"Name":  Sanitize(u.Name),
+		"Email": u.Email,
+	}
+}
+
+// data is the user's template data plus the admin's Level.
+func (a *Admin) data() map[string]any {
+	d := a.User.data()
+	d["Level"] = a.Level
+
+	return d
+}
+
+// Body is a notification rendered for email. HTML is empty when the
+// recipient has no HTML template.
+type Body struct {
+	Plain string
+	HTML  string
+}
+
+// Body renders the user's notification as plain text, as Notify writes
+// it, and as HTML when an HTML template was set with WithHTMLTemplate or
+// SetHTMLTemplate.
+func (u *User) Body() (Body, error) {
+	plain, err := u.message()
+	if err != nil {
+		return Body{}, err
+	}
+
+	return u.body(plain, u.data())
+}
+
+// Body is User.Body for the admin's notification, with Level available to
+// the HTML template.
+func (a *Admin) Body() (Body, error) {
+	plain, err := a.message()
+	if err != nil {
+		return Body{}, err
+	}
+
+	return a.body(plain, a.data())
+}
+
+// body pairs plain with the HTML template executed against data.
+func (u *User) body(plain string, data map[string]any) (Body, error) {
+	if u.htmlErr != nil {
+		return Body{}, u.htmlErr
+	}
+	if u.html == nil {
+		return Body{Plain: plain}, nil
+	}
+
+	// Each execution gets its own copy, so that it can be given its own
+	// range budget; the copy held by the user is never executed, since
+	// html/template can't copy one that has been.
+	t, err := u.html.Clone()
+	if err != nil {
+		return Body{}, fmt.Errorf("notify: render html template: %w", err)
+	}
+	var b renderBuffer
+	budget := rangeBudget(maxRangeIterations)
+	if err := t.Funcs(htmltemplate.FuncMap{rangeLimitFunc: budget.spend}).Execute(&b, data); err != nil {
+		return Body{}, fmt.Errorf("notify: render html template: %w", err)
+	}
+
+	return Body{Plain: plain, HTML: b.String()}, nil
+}
+
+// maxRenderBytes bounds what one template execution may produce. Templates
+// can loop, so without a bound a template from an untrusted source could
+// exhaust memory.
+const maxRenderBytes = 1 << 20
+
+// renderBuffer collects template output, failing once it would pass
+// maxRenderBytes, which stops the execution. The builder isn't embedded,
+// so that its WriteString can't bypass the check.
+type renderBuffer struct {
+	b strings.Builder
+}
+
+func (r *renderBuffer) Write(p []byte) (int, error) {
+	if r.b.Len()+len(p) > maxRenderBytes {
+		return 0, fmt.Errorf("%w: template output exceeds %d bytes", ErrPayloadTooLarge, maxRenderBytes)
+	}
+
+	return r.b.Write(p)
+}
+
+// String returns what has been written.
+func (r *renderBuffer) String() string {
+	return r.b.String()
+}
+
+// maxRangeIterations bounds the iterations of all the {{range}} actions in
+// one template execution together. Output is already bounded, but a range
+// with an empty body, as in {{range 1000000000}}{{end}}, writes nothing
+// and would only burn CPU. Ranges multiply when nested or reached through
+// {{template}}, so the budget is shared rather than per range.
+const maxRangeIterations = 10_000
+
+// rangeLimitFunc is the template function that limitRanges appends to
+// every range pipeline. It is bound to a fresh rangeBudget for each
+// execution.
+const rangeLimitFunc = "notifyRangeLimit"
+
+// rangeBudget is how many range iterations an execution has left.
+type rangeBudget int64
+
+// spend takes the iterations ranging over v will make from the budget and
+// returns v unchanged, or fails the execution if the budget can't cover
+// them. Values whose length isn't known up front, such as channels, are
+// let through.
+func (b *rangeBudget) spend(v any) (any, error) {
+	var n int64
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n = max(rv.Int(), 0)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n = int64(min(rv.Uint(), math.MaxInt64))
+	case reflect.Array, reflect.Slice, reflect.Map:
+		n = int64(rv.Len())
+	}
+	if n > int64(*b) {
+		return nil, fmt.Errorf("%w: ranges iterate more than %d times", ErrTemplateTooExpensive, maxRangeIterations)
+	}
+	*b -= rangeBudget(n)
+
+	return v, nil
+}
+
+// ErrTemplateTooExpensive is returned when executing a template would
+// iterate more than maxRangeIterations times. Like ErrPayloadTooLarge, it
+// is permanent.
+var ErrTemplateTooExpensive = Permanent(errors.New("notify: template too expensive"))
+
+// limitTextRanges applies limitRanges to t and every template defined
+// with it.
+func limitTextRanges(t *template.Template) error {
+	for _, d := range t.Templates() {
+		if err := limitRanges(d.Tree, d.Tree.Root); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// limitHTMLRanges is limitTextRanges for an HTML template.
+func limitHTMLRanges(t *htmltemplate.Template) error {
+	for _, d := range t.Templates() {
+		if d.Tree == nil {
+			continue
+		}
+		if err := limitRanges(d.Tree, d.Tree.Root); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// limitRanges rewrites each {{range P}} under n as {{range P |
+// notifyRangeLimit}}, so that the range's value is charged to the
+// execution's budget before it iterates. A range over an integer literal
+// larger than the whole budget is rejected straight away.
+func limitRanges(tree *parse.Tree, n parse.Node) error {
+	var branch *parse.BranchNode
+	switch n := n.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, c := range n.Nodes {
+			if err := limitRanges(tree, c); err != nil {
+				return err
+			}
+		}
+		return nil
+	case *parse.IfNode:
+		branch = &n.BranchNode
+	case *parse.WithNode:
+		branch = &n.BranchNode
+	case *parse.RangeNode:
+		branch = &n.BranchNode
+		if err := checkRangeLiteral(tree, n); err != nil {
+			return err
+		}
+		// The command has no tree of its own, so errors in it are
+		// reported against the range's template.
+		n.Pipe.Cmds = append(n.Pipe.Cmds, &parse.CommandNode{
+			NodeType: parse.NodeCommand,
+			Pos:      n.Pipe.Pos,
+			Args:     []parse.Node{parse.NewIdentifier(rangeLimitFunc).SetTree(tree).SetPos(n.Pipe.Pos)},
+		})
+	default:
+		return nil
+	}
+
+	if err := limitRanges(tree, branch.List); err != nil {
+		return err
+	}
+
+	return limitRanges(tree, branch.ElseList)
+}
+
+// checkRangeLiteral rejects a range whose pipeline is an integer literal
+// beyond maxRangeIterations, which could never execute.
+func checkRangeLiteral(tree *parse.Tree, r *parse.RangeNode) error {
+	if len(r.Pipe.Cmds) != 1 || len(r.Pipe.Cmds[0].Args) != 1 {
+		return nil
+	}
+	num, ok := r.Pipe.Cmds[0].Args[0].(*parse.NumberNode)
+	if !ok || (num.IsInt && num.Int64 <= maxRangeIterations) || (!num.IsInt && !num.IsUint) {
+		return nil
+	}
+	location, _ := tree.ErrorContext(r)
+
+	return fmt.Errorf("%w: %s: range over %s is more than %d iterations", ErrTemplateTooExpensive, location, num.Text, maxRangeIterations)
+}
+
+// executeText executes t against data. Like body, it executes a copy,
+// bound to its own range budget, so t can be shared.
+func executeText(t *template.Template, data any) (string, error) {
+	t, err := t.Clone()
+	if err != nil {
+		return "", fmt.Errorf("notify: render template: %w", err)
+	}
+	var b renderBuffer
+	budget := rangeBudget(maxRangeIterations)
+	if err := t.Funcs(template.FuncMap{rangeLimitFunc: budget.spend}).Execute(&b, data); err != nil {
+		return "", fmt.Errorf("notify: render template: %w", err)
+	}
+
+	return b.String(), nil
+}
+
+// MessageTemplate is a parsed message body, such as
+// "Hi {{.Name}}, you are level {{.Level}}".
+type MessageTemplate struct {
+	t *template.Template
+}
+
+// ParseMessageTemplate parses text into a MessageTemplate. Executing it
+// against data that lacks a referenced field or key is an error.
+func ParseMessageTemplate(text string) (*MessageTemplate, error) {
+	t, err := template.New("message").
+		Funcs(defaultFuncs).
+		Option("missingkey=error").
+		Parse(text)
+	if err == nil {
+		err = limitTextRanges(t)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("notify: parse template: %w", err)
+	}
+
+	return &MessageTemplate{t: t}, nil
+}
+
+// Render executes the template against data and returns the result.
+func (m *MessageTemplate) Render(data any) (string, error) {
+	return executeText(m.t, data)
+}
+
+// TemplatedNotifier renders Template against Data and writes the result to
+// Out, which defaults to os.Stdout. Data is usually a *User or *Admin, so
+// the template can use their fields directly.
+type TemplatedNotifier struct {
+	Template *MessageTemplate
+	Data     any
+	Out      io.Writer
+}
+
+// Notify renders the message and writes it on its own line. Nothing is
+// written if rendering fails.
+func (t *TemplatedNotifier) Notify(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	msg, err := t.Template.Render(t.Data)
+	if err != nil {
+		return err
+	}
+	if msg, err = limitBody(ctx, msg); err != nil {
+		return err
+	}
+
+	out := t.Out
+	if out == nil {
+		out = os.Stdout
+	}
+	_, err = fmt.Fprintln(out, msg)
+
+	return err
+}
+
+// Address reports the address of Data when it has one.
+func (t *TemplatedNotifier) Address() (recipient, channel string) {
+	if n, ok := t.Data.(Notifier); ok {
+		return AddressOf(n)
+	}
+
+	return "unknown", "unknown"
+}
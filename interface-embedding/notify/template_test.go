@@ -0,0 +1,423 @@
+package notify_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	htmltemplate "html/template"
+	"strings"
+	"sync"
+	"testing"
+	"text/template"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+func TestWithTemplateParseError(t *testing.T) {
+	for _, text := range []string{"{{.Name", "{{if}}", "{{nosuchfunc .Name}}"} {
+		if _, err := notify.NewUser("Janet", "janet@email.com", notify.WithTemplate(text)); err == nil {
+			t.Errorf("NewUser(WithTemplate(%q)) succeeded, want a parse error", text)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	user, err := notify.NewUser("Janet Jones", "janet@email.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	admin, err := notify.NewAdmin("Lisa Smith", "lisa@email.com", notify.WithLevel(notify.LevelRoot))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		render  func(string) (string, error)
+		text    string
+		want    string
+		wantErr bool
+	}{
+		{"user default", user.Render, notify.DefaultUserTemplate, "Sending user email to Janet Jones<janet@email.com>", false},
+		{"admin default", admin.Render, notify.DefaultAdminTemplate, "Sending admin email to Lisa Smith<lisa@email.com> with level root", false},
+		{"promoted fields", admin.Render, "{{.Name}} {{.Email}}", "Lisa Smith lisa@email.com", false},
+		{"urgent", admin.Render, "URGENT {{upper .Name}}: level {{.Level}}", "URGENT LISA SMITH: level root", false},
+		{"normal", admin.Render, "fyi {{lower .Name}}", "fyi lisa smith", false},
+		{"missing field", user.Render, "{{.Level}}", "", true},
+		{"parse error", user.Render, "{{.Name", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.render(tt.text)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Render(%q) error = %v, want error %v", tt.text, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Render(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+			if strings.Contains(got, "<no value>") {
+				t.Errorf("Render(%q) = %q, rendered a missing key", tt.text, got)
+			}
+		})
+	}
+}
+
+func TestWithFuncs(t *testing.T) {
+	var buf bytes.Buffer
+	shout := template.FuncMap{"shout": func(s string) string { return strings.ToUpper(s) + "!" }}
+	a, err := notify.NewAdmin("Lisa", "lisa@email.com", quietAdmin(&buf,
+		notify.WithFuncs(shout),
+		notify.WithTemplate("{{shout .Name}} ({{.Level}})"),
+		notify.WithLevel(notify.LevelSuper),
+	)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := a.Notify(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), "LISA! (super)\n"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestNotifyTemplateError(t *testing.T) {
+	var buf bytes.Buffer
+	u, err := notify.NewUser("Janet", "janet@email.com", append(quiet(&buf), notify.WithTemplate("{{.Level}}"))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = u.Notify(context.Background())
+	var ne *notify.NotificationError
+	if !errors.As(err, &ne) {
+		t.Errorf("Notify() = %v, want a *NotificationError", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("Notify() wrote %q despite the template failing", buf.String())
+	}
+}
+
+func TestTemplatedNotifier(t *testing.T) {
+	admin := &notify.Admin{User: notify.User{Name: "Janet", Email: "janet@email.com"}, Level: notify.LevelSuper}
+	tests := []struct {
+		name    string
+		text    string
+		data    any
+		want    string
+		wantErr bool
+	}{
+		{"struct fields", "Hi {{.Name}}, you are level {{.Level}}", admin, "Hi Janet, you are level super\n", false},
+		{"map keys", "Hi {{.Name}}", map[string]string{"Name": "Bob"}, "Hi Bob\n", false},
+		{"unknown field", "Hi {{.Nickname}}", admin, "", true},
+		{"missing key", "Hi {{.Name}}", map[string]string{}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := notify.ParseMessageTemplate(tt.text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			err = (&notify.TemplatedNotifier{Template: tmpl, Data: tt.data, Out: &buf}).Notify(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Notify() = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), "render template") {
+				t.Errorf("Notify() = %v, want a render error", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := notify.ParseMessageTemplate("{{.Name"); err == nil {
+		t.Error("ParseMessageTemplate() accepted an unterminated action")
+	}
+}
+
+func TestBody(t *testing.T) {
+	const name = `Janet <b>"JJ"</b> Jones`
+
+	tests := []struct {
+		name      string
+		build     func() (interface{ Body() (notify.Body, error) }, error)
+		wantPlain string
+		wantHTML  string
+	}{
+		{"plain only without an HTML template", func() (interface{ Body() (notify.Body, error) }, error) {
+			return notify.NewUser(name, "janet@email.com")
+		}, `Sending user email to Janet <b>"JJ"</b> Jones<janet@email.com>`, ""},
+		{"user HTML escaped", func() (interface{ Body() (notify.Body, error) }, error) {
+			return notify.NewUser(name, "janet@email.com", notify.WithHTMLTemplate(`<p>Hello {{.Name}}</p>`))
+		}, `Sending user email to Janet <b>"JJ"</b> Jones<janet@email.com>`,
+			`<p>Hello Janet &lt;b&gt;&#34;JJ&#34;&lt;/b&gt; Jones</p>`},
+		{"admin level available", func() (interface{ Body() (notify.Body, error) }, error) {
+			return notify.NewAdmin(name, "janet@email.com", notify.WithHTMLTemplate(`<p>Hello {{.Name}}, {{.Level}}</p>`), notify.WithLevel(notify.LevelRoot))
+		}, `Sending admin email to Janet <b>"JJ"</b> Jones<janet@email.com> with level root`,
+			`<p>Hello Janet &lt;b&gt;&#34;JJ&#34;&lt;/b&gt; Jones, root</p>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := tt.build()
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := n.Body()
+			if err != nil {
+				t.Fatalf("Body() = %v", err)
+			}
+			if b.Plain != tt.wantPlain {
+				t.Errorf("Plain = %q, want %q", b.Plain, tt.wantPlain)
+			}
+			if b.HTML != tt.wantHTML {
+				t.Errorf("HTML = %q, want %q", b.HTML, tt.wantHTML)
+			}
+		})
+	}
+}
+
+func TestWithHTMLTemplateErrors(t *testing.T) {
+	if _, err := notify.NewUser("Janet", "janet@email.com", notify.WithHTMLTemplate("<p>{{.Name</p>")); err == nil {
+		t.Error("NewUser() with a malformed HTML template succeeded, want a parse error")
+	}
+	u, err := notify.NewUser("Janet", "janet@email.com", notify.WithHTMLTemplate("<p>{{.Phone}}</p>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := u.Body(); err == nil {
+		t.Error("Body() with a missing key succeeded, want an error")
+	}
+}
+
+func TestNotifyWritesPlainText(t *testing.T) {
+	var buf bytes.Buffer
+	u, err := notify.NewUser("Janet <b>Jones</b>", "janet@email.com", append(quiet(&buf), notify.WithHTMLTemplate("<p>{{.Name}}</p>"))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := u.Notify(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), "Sending user email to Janet <b>Jones</b><janet@email.com>\n"; got != want {
+		t.Errorf("output = %q, want the plain-text message %q", got, want)
+	}
+}
+
+func TestSetHTMLTemplate(t *testing.T) {
+	const name = `<script>alert("hi")</script>`
+	page := htmltemplate.Must(htmltemplate.New("page").Parse(`<p>Hello {{.Name}} &lt;{{.Email}}&gt;</p>`))
+
+	tests := []struct {
+		name     string
+		tmpl     *htmltemplate.Template
+		wantHTML string
+	}{
+		{"script escaped", page, `<p>Hello &lt;script&gt;alert(&#34;hi&#34;)&lt;/script&gt; &lt;janet@email.com&gt;</p>`},
+		{"nil leaves plain text only", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := notify.NewUser(name, "janet@email.com", notify.WithHTMLTemplate("<p>earlier</p>"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			u.SetHTMLTemplate(tt.tmpl)
+
+			b, err := u.Body()
+			if err != nil {
+				t.Fatalf("Body() = %v", err)
+			}
+			if b.HTML != tt.wantHTML {
+				t.Errorf("HTML = %q, want %q", b.HTML, tt.wantHTML)
+			}
+			if strings.Contains(b.HTML, "<script>") {
+				t.Errorf("HTML = %q, want the name escaped", b.HTML)
+			}
+			if !strings.Contains(b.Plain, "janet@email.com") || (b.HTML != "" && !strings.Contains(b.HTML, "janet@email.com")) {
+				t.Errorf("bodies %q and %q, want both addressed to janet@email.com", b.Plain, b.HTML)
+			}
+		})
+	}
+}
+
+func TestTemplateRangeLimit(t *testing.T) {
+	recursive := `{{define "r"}}{{range 10}}{{template "r" .}}{{end}}{{end}}{{template "r" .}}`
+
+	tests := []struct {
+		name    string
+		text    string
+		want    string
+		wantErr bool
+	}{
+		{"small range", "{{range 3}}{{.}}{{end}}", "012", false},
+		{"empty body", "{{range 1000000000}}{{end}}", "", true},
+		{"literal through a variable", "{{$n := 1000000000}}{{range $n}}{{end}}", "", true},
+		{"nested", "{{range 100}}{{range 100}}{{range 100}}{{end}}{{end}}{{end}}", "", true},
+		{"nested within budget", "{{range 10}}{{range 10}}{{end}}{{end}}done", "done", false},
+		{"in else branch", "{{if false}}{{else}}{{range $i, $v := 1000000000}}{{end}}{{end}}", "", true},
+		{"via recursion", recursive, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := notify.NewUser("Janet Jones", "janet@email.com")
+			if err != nil {
+				t.Fatal(err)
+			}
+			renders := map[string]func() (string, error){
+				"Render": func() (string, error) { return u.Render(tt.text) },
+				"MessageTemplate": func() (string, error) {
+					m, err := notify.ParseMessageTemplate(tt.text)
+					if err != nil {
+						return "", err
+					}
+					return m.Render(nil)
+				},
+				"Body": func() (string, error) {
+					if err := notify.WithHTMLTemplate(tt.text)(u); err != nil {
+						return "", err
+					}
+					b, err := u.Body()
+					return b.HTML, err
+				},
+			}
+			for name, render := range renders {
+				got, err := render()
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("%s(%q) error = %v, want error %v", name, tt.text, err, tt.wantErr)
+				}
+				if err != nil && !errors.Is(err, notify.ErrTemplateTooExpensive) {
+					t.Errorf("%s(%q) = %v, want it to match %v", name, tt.text, err, notify.ErrTemplateTooExpensive)
+				}
+				if got != tt.want {
+					t.Errorf("%s(%q) = %q, want %q", name, tt.text, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestTemplateRangeLimitData(t *testing.T) {
+	m, err := notify.ParseMessageTemplate("{{range .}}{{end}}{{len .}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		data    any
+		want    string
+		wantErr bool
+	}{
+		{"slice", make([]int, 100), "100", false},
+		{"map", map[int]bool{1: true, 2: true}, "2", false},
+		{"huge slice", make([]struct{}, 1_000_000), "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Each execution has a budget of its own, so rendering
+			// again doesn't run out.
+			for range 3 {
+				got, err := m.Render(tt.data)
+				if (err != nil) != tt.wantErr || got != tt.want {
+					t.Fatalf("Render() = %q, %v, want %q and error %v", got, err, tt.want, tt.wantErr)
+				}
+			}
+		})
+	}
+}
+
+func TestRejectsRangeOverLargeLiteral(t *testing.T) {
+	_, err := notify.NewUser("Janet Jones", "janet@email.com", notify.WithTemplate("{{range 1000000000}}{{end}}"))
+	if !errors.Is(err, notify.ErrTemplateTooExpensive) || !strings.Contains(err.Error(), "message:1") {
+		t.Errorf("NewUser() = %v, want a parse error at the range matching %v", err, notify.ErrTemplateTooExpensive)
+	}
+}
+
+func TestSetHTMLTemplateLeavesCallersTemplate(t *testing.T) {
+	page := htmltemplate.Must(htmltemplate.New("page").Parse(`{{range 3}}{{.}}{{end}}`))
+	u, err := notify.NewUser("Janet Jones", "janet@email.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	u.SetHTMLTemplate(page)
+	if b, err := u.Body(); err != nil || b.HTML != "012" {
+		t.Errorf("Body() = %q, %v, want 012", b.HTML, err)
+	}
+
+	var buf bytes.Buffer
+	if err := page.Execute(&buf, nil); err != nil || buf.String() != "012" {
+		t.Errorf("caller's Execute() = %q, %v, want the template as they parsed it", buf.String(), err)
+	}
+
+	// page has now been executed, so html/template won't copy it.
+	u.SetHTMLTemplate(page)
+	if _, err := u.Body(); err == nil {
+		t.Error("Body() = nil, want the error from copying an executed template")
+	}
+	u.SetHTMLTemplate(nil)
+	if b, err := u.Body(); err != nil || b.HTML != "" {
+		t.Errorf("Body() = %q, %v, want plain text only once the template is removed", b.HTML, err)
+	}
+}
+
+func TestBodyConcurrent(t *testing.T) {
+	u, err := notify.NewUser("Janet Jones", "janet@email.com", notify.WithHTMLTemplate(`{{range 5}}<p>{{$.Name}}</p>{{end}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 20 {
+				if b, err := u.Body(); err != nil || strings.Count(b.HTML, "<p>Janet Jones</p>") != 5 {
+					t.Errorf("Body() = %q, %v, want five paragraphs", b.HTML, err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func FuzzRenderTemplate(f *testing.F) {
+	for _, seed := range []string{
+		notify.DefaultUserTemplate,
+		"{{.Name}} {{upper .Email}}",
+		"{{range 1000000000}}{{end}}",
+		`{{define "r"}}{{template "r" .}}{{end}}{{template "r" .}}`,
+		`{{printf "%999999d" 1}}{{printf "%999999d" 1}}`,
+		"<script>{{.Name}}</script>",
+		`<a href="{{.Name}}">{{.Email}}</a>`,
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, text string) {
+		u, err := notify.NewUser(`<script>alert("hi")</script>`, "janet@email.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := u.Render(text)
+		if err != nil && got != "" {
+			t.Errorf("Render(%q) = %q, %v, want no output with an error", text, got, err)
+		}
+		if len(got) > 1<<20 {
+			t.Errorf("Render(%q) made %d bytes, more than the render limit", text, len(got))
+		}
+
+		if err := notify.WithHTMLTemplate(text)(u); err != nil {
+			return
+		}
+		b, err := u.Body()
+		if err == nil && len(b.HTML) > 1<<20 {
+			t.Errorf("Body() for %q made %d bytes, more than the render limit", text, len(b.HTML))
+		}
+	})
+}
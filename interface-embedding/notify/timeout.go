@@ -0,0 +1,64 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ErrNotifyTimeout is returned when a notifier wrapped with WithTimeout
+// doesn't finish in time. It wraps context.DeadlineExceeded.
+var ErrNotifyTimeout = fmt.Errorf("notify: notification timed out: %w", context.DeadlineExceeded)
+
+// WithTimeout wraps n in a TimeoutNotifier that gives up after d. A d of
+// zero or less sets no limit.
+func WithTimeout(n Notifier, d time.Duration) Notifier {
+	return &TimeoutNotifier{Notifier: n, Timeout: d}
+}
+
+// TimeoutNotifier bounds how long the embedded Notifier may take: each
+// Notify returns ErrNotifyTimeout, which matches context.DeadlineExceeded,
+// once Timeout has passed, even if the notifier ignores its context.
+//
+// The wrapped call keeps running in its own goroutine after a timeout,
+// with a cancelled context, and its late result is discarded. That
+// goroutine exits as soon as the call returns; it never blocks waiting for
+// a reader.
+//
+// A Timeout of zero or less means no limit: Notify calls the embedded
+// Notifier directly, bounded only by ctx.
+type TimeoutNotifier struct {
+	Notifier
+	Timeout time.Duration
+}
+
+// Notify calls the embedded Notifier and waits for it or the deadline,
+// whichever comes first. If ctx itself is cancelled first, its error is
+// returned instead of ErrNotifyTimeout.
+func (t *TimeoutNotifier) Notify(ctx context.Context) error {
+	if t.Timeout <= 0 {
+		return t.Notifier.Notify(ctx)
+	}
+
+	ctx, cancel := context.WithTimeoutCause(ctx, t.Timeout, ErrNotifyTimeout)
+	defer cancel()
+
+	// done is buffered so the goroutine can always deliver its result and
+	// exit, even after Notify has stopped waiting.
+	done := make(chan error, 1)
+	go func() {
+		done <- t.Notifier.Notify(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	}
+}
+
+// Unwrap returns the notifier being timed.
+func (t *TimeoutNotifier) Unwrap() Notifier {
+	return t.Notifier
+}
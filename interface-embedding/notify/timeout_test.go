@@ -0,0 +1,140 @@
+package notify_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+// slowNotifier takes delay to finish, or stops early when its context is
+// done if it honours it.
+type slowNotifier struct {
+	delay     time.Duration
+	honourCtx bool
+	err       error
+}
+
+func (s *slowNotifier) Notify(ctx context.Context) error {
+	if !s.honourCtx {
+		time.Sleep(s.delay)
+		return s.err
+	}
+
+	select {
+	case <-time.After(s.delay):
+		return s.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestWithTimeout(t *testing.T) {
+	errDown := errors.New("server down")
+
+	tests := []struct {
+		name string
+		n    *slowNotifier
+		want error
+	}{
+		{"fast", &slowNotifier{}, nil},
+		{"fast failure", &slowNotifier{err: errDown}, errDown},
+		{"slow, honours ctx", &slowNotifier{delay: time.Minute, honourCtx: true}, notify.ErrNotifyTimeout},
+		{"slow, ignores ctx", &slowNotifier{delay: 200 * time.Millisecond, err: errDown}, notify.ErrNotifyTimeout},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkNoLeaks(t)
+
+			err := notify.WithTimeout(tt.n, 50*time.Millisecond).Notify(context.Background())
+			if !errors.Is(err, tt.want) || (tt.want == nil) != (err == nil) {
+				t.Errorf("Notify() = %v, want %v", err, tt.want)
+			}
+			if errors.Is(tt.want, notify.ErrNotifyTimeout) && !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("Notify() = %v, want it to match %v", err, context.DeadlineExceeded)
+			}
+		})
+	}
+}
+
+func TestTimeoutNotifierNoLimit(t *testing.T) {
+	for _, timeout := range []time.Duration{0, -time.Second} {
+		t.Run(timeout.String(), func(t *testing.T) {
+			checkNoLeaks(t)
+			var deadline bool
+			n := &notify.TimeoutNotifier{
+				Notifier: notify.NotifierFunc(func(ctx context.Context) error {
+					time.Sleep(20 * time.Millisecond)
+					_, deadline = ctx.Deadline()
+					return nil
+				}),
+				Timeout: timeout,
+			}
+
+			if err := n.Notify(context.Background()); err != nil {
+				t.Errorf("Notify() = %v, want nil", err)
+			}
+			if deadline {
+				t.Error("the wrapped notifier's context has a deadline, want none")
+			}
+		})
+	}
+}
+
+func TestWithTimeoutDiscardsLateResult(t *testing.T) {
+	checkNoLeaks(t)
+	release := make(chan struct{})
+	var late atomic.Bool
+	n := notify.NotifierFunc(func(ctx context.Context) error {
+		<-release
+		late.Store(true)
+		return errors.New("late failure")
+	})
+
+	err := notify.WithTimeout(n, 10*time.Millisecond).Notify(context.Background())
+	if !errors.Is(err, notify.ErrNotifyTimeout) {
+		t.Fatalf("Notify() = %v, want %v", err, notify.ErrNotifyTimeout)
+	}
+
+	// Releasing the abandoned call lets its goroutine finish; checkNoLeaks
+	// fails the test if it stays blocked delivering the late result.
+	close(release)
+	waitFor(t, "the abandoned call to return", late.Load)
+}
+
+func TestWithTimeoutParentCancelled(t *testing.T) {
+	checkNoLeaks(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	n := &slowNotifier{delay: time.Minute, honourCtx: true}
+
+	time.AfterFunc(10*time.Millisecond, cancel)
+	if err := notify.WithTimeout(n, time.Minute).Notify(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Notify() = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestWithTimeoutConcurrent(t *testing.T) {
+	checkNoLeaks(t)
+	fast := notify.WithTimeout(&slowNotifier{}, time.Second)
+	slow := notify.WithTimeout(&slowNotifier{delay: time.Minute, honourCtx: true}, 5*time.Millisecond)
+
+	var wg sync.WaitGroup
+	for i := range 20 {
+		n, want := fast, error(nil)
+		if i%2 == 1 {
+			n, want = slow, notify.ErrNotifyTimeout
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := n.Notify(context.Background()); !errors.Is(err, want) {
+				t.Errorf("Notify() = %v, want %v", err, want)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,82 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// ErrNoSubscribers is returned when publishing to a topic nobody has
+// subscribed to.
+var ErrNoSubscribers = errors.New("notify: no subscribers")
+
+// TopicRegistry lets notifiers subscribe to named topics and notifies
+// every subscriber when a topic is published. The zero value is ready to
+// use and safe for concurrent use.
+type TopicRegistry struct {
+	mu     sync.Mutex
+	nextID int
+	topics map[string][]subscription
+}
+
+// subscription is one notifier subscribed to a topic.
+type subscription struct {
+	id int
+	n  Notifier
+}
+
+// Subscribe adds n to topic and returns a function that removes it again.
+// Calling the function more than once has no further effect.
+func (r *TopicRegistry) Subscribe(topic string, n Notifier) (unsubscribe func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.topics == nil {
+		r.topics = make(map[string][]subscription)
+	}
+	r.nextID++
+	id := r.nextID
+	r.topics[topic] = append(r.topics[topic], subscription{id: id, n: n})
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { r.remove(topic, id) })
+	}
+}
+
+// Publish notifies every subscriber of topic in subscription order and
+// returns any failures as a *BatchError. Subscribers are read before any
+// are notified, so subscribing or unsubscribing during a publish takes
+// effect from the next one.
+func (r *TopicRegistry) Publish(ctx context.Context, topic string) error {
+	r.mu.Lock()
+	subs := r.topics[topic]
+	ns := make([]Notifier, len(subs))
+	for i, s := range subs {
+		ns[i] = s.n
+	}
+	r.mu.Unlock()
+
+	if len(ns) == 0 {
+		return fmt.Errorf("%w to topic %q", ErrNoSubscribers, topic)
+	}
+
+	return SendNotifications(ctx, ns...)
+}
+
+// remove deletes the subscription with the given id from topic.
+func (r *TopicRegistry) remove(topic string, id int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	subs := slices.DeleteFunc(r.topics[topic], func(s subscription) bool {
+		return s.id == id
+	})
+	if len(subs) == 0 {
+		delete(r.topics, topic)
+		return
+	}
+	r.topics[topic] = subs
+}
@@ -0,0 +1,85 @@
+package notify_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+func TestTopicRegistry(t *testing.T) {
+	var r notify.TopicRegistry
+	a, b := &notify.MockNotifier{Name: "a"}, &notify.MockNotifier{Name: "b"}
+	unsubA := r.Subscribe("deploys", a)
+	r.Subscribe("deploys", b)
+	r.Subscribe("outages", b)
+
+	if err := r.Publish(context.Background(), "deploys"); err != nil {
+		t.Fatal(err)
+	}
+	unsubA()
+	unsubA()
+	if err := r.Publish(context.Background(), "deploys"); err != nil {
+		t.Fatal(err)
+	}
+	if a.Calls() != 1 || b.Calls() != 2 {
+		t.Errorf("calls = %d, %d, want a to stop receiving after unsubscribing", a.Calls(), b.Calls())
+	}
+
+	tests := []struct {
+		topic   string
+		wantErr error
+	}{
+		{"outages", nil},
+		{"unknown", notify.ErrNoSubscribers},
+	}
+	for _, tt := range tests {
+		if err := r.Publish(context.Background(), tt.topic); !errors.Is(err, tt.wantErr) {
+			t.Errorf("Publish(%q) = %v, want %v", tt.topic, err, tt.wantErr)
+		}
+	}
+}
+
+func TestTopicRegistryUnsubscribeDuringPublish(t *testing.T) {
+	var r notify.TopicRegistry
+	var unsub func()
+	self := notify.NotifierFunc(func(context.Context) error {
+		unsub()
+		return nil
+	})
+	unsub = r.Subscribe("t", self)
+
+	if err := r.Publish(context.Background(), "t"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Publish(context.Background(), "t"); !errors.Is(err, notify.ErrNoSubscribers) {
+		t.Errorf("Publish() after self-unsubscribe = %v, want %v", err, notify.ErrNoSubscribers)
+	}
+}
+
+func TestTopicRegistryConcurrent(t *testing.T) {
+	var r notify.TopicRegistry
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			topic := fmt.Sprint("t", i%3)
+			for range 50 {
+				unsub := r.Subscribe(topic, &notify.MockNotifier{})
+				r.Publish(context.Background(), topic)
+				unsub()
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i := range 3 {
+		if err := r.Publish(context.Background(), fmt.Sprint("t", i)); !errors.Is(err, notify.ErrNoSubscribers) {
+			t.Errorf("Publish(t%d) = %v, want every subscriber gone", i, err)
+		}
+	}
+}
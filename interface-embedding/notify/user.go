@@ -0,0 +1,367 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"log/slog"
+	"maps"
+	"net/mail"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+	"unicode"
+)
+
+// ErrEmptyName is returned when a recipient is constructed without a name.
+// Like the other recipient errors below, it matches ErrInvalidRecipient.
+var ErrEmptyName = invalidRecipient(errors.New("notify: name is required"))
+
+// ErrEmptyEmail is returned when a recipient is constructed without an email.
+var ErrEmptyEmail = invalidRecipient(errors.New("notify: email is required"))
+
+// ErrInvalidEmail is returned when a recipient's email can't be parsed as
+// an address.
+var ErrInvalidEmail = invalidRecipient(errors.New("notify: invalid email address"))
+
+// User represents a person who receives notifications by email.
+type User struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+
+	out      io.Writer
+	locale   string
+	localeID string // locale lowercased once for catalog lookups
+	catalog  Catalog
+	tmpl     string
+	html     *htmltemplate.Template
+	htmlErr  error // why SetHTMLTemplate couldn't use its template
+	funcs    template.FuncMap
+	logger   *slog.Logger
+	location *time.Location
+	labels   map[string]string
+
+	attachments   []Attachment
+	maxAttachment int
+}
+
+// NewUser returns a User after trimming name and email, checking that name
+// is present, and normalizing email with NormalizeEmail. It then applies
+// opts in order. Users default to the en-US locale. Building a User
+// literal skips these checks, but Notify still refuses to send to an
+// invalid address.
+func NewUser(name, email string, opts ...UserOption) (*User, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, ErrEmptyName
+	}
+	email, err := NormalizeEmail(email)
+	if err != nil {
+		return nil, err
+	}
+
+	u := &User{Name: name, Email: email, locale: DefaultLocale, localeID: defaultLocaleID}
+	for _, opt := range opts {
+		if err := opt(u); err != nil {
+			return nil, err
+		}
+	}
+
+	return u, nil
+}
+
+// NormalizeEmail trims surrounding space from email, checks that it is a
+// single bare address such as "janet@email.com", and lowercases its
+// domain. Addresses with a display name, like "Janet <janet@email.com>",
+// are rejected.
+func NormalizeEmail(email string) (string, error) {
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return "", ErrEmptyEmail
+	}
+	if isDotAtomAddress(email) {
+		// Notify revalidates on every send, so the common case of a plain
+		// address skips the full parser, and its allocations.
+		i := strings.LastIndexByte(email, '@')
+		if domain := email[i+1:]; strings.ToLower(domain) != domain {
+			return email[:i] + "@" + strings.ToLower(domain), nil
+		}
+		return email, nil
+	}
+
+	addr, err := mail.ParseAddress(email)
+	if err != nil {
+		return "", fmt.Errorf("%w %q: %v", ErrInvalidEmail, email, err)
+	}
+	if addr.Name != "" || addr.Address != email {
+		return "", fmt.Errorf("%w %q: must be a bare address", ErrInvalidEmail, email)
+	}
+
+	i := strings.LastIndexByte(email, '@')
+	return email[:i] + "@" + strings.ToLower(email[i+1:]), nil
+}
+
+// isDotAtomAddress reports whether email is a plain ASCII address of the
+// form local@domain, where both parts are RFC 5322 dot-atoms: runs of
+// atext characters separated by single dots. net/mail accepts every such
+// address unchanged.
+func isDotAtomAddress(email string) bool {
+	local, domain, ok := strings.Cut(email, "@")
+	return ok && isDotAtom(local) && isDotAtom(domain)
+}
+
+// isDotAtom reports whether s is one or more runs of atext separated by
+// single dots.
+func isDotAtom(s string) bool {
+	if s == "" || s[0] == '.' || s[len(s)-1] == '.' {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '.':
+			if s[i-1] == '.' {
+				return false
+			}
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		case strings.IndexByte("!#$%&'*+-/=?^_`{|}~", c) >= 0:
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// Sanitize makes s safe to print on a single line: newlines, carriage
+// returns and other control characters are replaced with Go escapes such
+// as \n, so a name like "Janet\nFAKE LOG" can't forge an extra log line.
+// Strings without control characters are returned unchanged.
+func Sanitize(s string) string {
+	if !strings.ContainsFunc(s, isUnsafeRune) {
+		return s
+	}
+
+	var b strings.Builder
+	for _, r := range s {
+		if isUnsafeRune(r) {
+			q := strconv.QuoteRune(r)
+			b.WriteString(q[1 : len(q)-1])
+			continue
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+// isUnsafeRune reports whether r could break a line of output: a control
+// character or a Unicode line or paragraph separator.
+func isUnsafeRune(r rune) bool {
+	return unicode.IsControl(r) || r == '\u2028' || r == '\u2029'
+}
+
+// Notify sends the user an email.
+func (u *User) Notify(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return &NotificationError{Recipient: u.Email, Channel: "email", Err: err}
+	}
+	if _, err := NormalizeEmail(u.Email); err != nil {
+		return &NotificationError{Recipient: u.Email, Channel: "email", Err: err}
+	}
+
+	buf := lineBufs.Get().(*[]byte)
+	defer lineBufs.Put(buf)
+
+	line, err := u.appendMessage((*buf)[:0])
+	if err != nil {
+		return &NotificationError{Recipient: u.Email, Channel: "email", Err: err}
+	}
+
+	line, err = limitLine(ctx, u.appendAttachments(line))
+	*buf = line
+	if err != nil {
+		return &NotificationError{Recipient: u.Email, Channel: "email", Err: err}
+	}
+
+	if err := u.writeBuf(ctx, buf); err != nil {
+		return &NotificationError{Recipient: u.Email, Channel: "email", Retryable: true, Err: err}
+	}
+	u.logSent(ctx,
+		slog.String("name", u.Name), slog.String("email", u.Email), slog.String("channel", "email"))
+	return nil
+}
+
+// message renders the user's notification from the template set with
+// WithTemplate, or from the catalog in the user's locale.
+func (u *User) message() (string, error) {
+	if u.tmpl != "" {
+		return u.Render(u.tmpl)
+	}
+
+	return u.localize(KeyUserEmail, Sanitize(u.Name), u.Email)
+}
+
+// appendMessage is message, appending the notification to buf.
+func (u *User) appendMessage(buf []byte) ([]byte, error) {
+	if u.tmpl != "" {
+		msg, err := u.Render(u.tmpl)
+		return append(buf, msg...), err
+	}
+
+	return u.appendLocalized(buf, KeyUserEmail, Sanitize(u.Name), u.Email)
+}
+
+// writeMu serializes notification output, so recipients sharing a writer
+// can be notified from several goroutines at once.
+var writeMu sync.Mutex
+
+// SetOutput sets where Notify writes its messages. A nil w restores the
+// default of os.Stdout.
+func (u *User) SetOutput(w io.Writer) {
+	u.out = w
+}
+
+// WithWriter sets where Notify writes its messages instead of os.Stdout,
+// such as a *bytes.Buffer in tests. Admins pick it up through the embedded
+// User.
+func WithWriter(w io.Writer) UserOption {
+	return func(u *User) error {
+		u.SetOutput(w)
+		return nil
+	}
+}
+
+// lineBufs holds scratch buffers for lines of output, so sending doesn't
+// allocate a new line for every message.
+var lineBufs = sync.Pool{
+	New: func() any {
+		buf := make([]byte, 0, 256)
+		return &buf
+	},
+}
+
+// writeLine writes msg and a newline to the user's output in a single
+// write. When ctx carries a request ID, it is appended to the line as
+// request_id=ID.
+func (u *User) writeLine(ctx context.Context, msg string) error {
+	buf := lineBufs.Get().(*[]byte)
+	defer lineBufs.Put(buf)
+
+	*buf = append((*buf)[:0], msg...)
+	return u.writeBuf(ctx, buf)
+}
+
+// writeBuf is writeLine for a message already built in buf, which it
+// extends with the request ID and newline.
+func (u *User) writeBuf(ctx context.Context, buf *[]byte) error {
+	w := u.out
+	if w == nil {
+		w = os.Stdout
+	}
+
+	if id, ok := RequestIDFromContext(ctx); ok {
+		*buf = append(append(*buf, " request_id="...), Sanitize(id)...)
+	}
+	*buf = append(*buf, '\n')
+
+	writeMu.Lock()
+	defer writeMu.Unlock()
+
+	_, err := w.Write(*buf)
+	return err
+}
+
+// WithLogger sets the logger that records each notification. Users
+// without one log to slog.Default.
+func WithLogger(l *slog.Logger) UserOption {
+	return func(u *User) error {
+		u.logger = l
+		return nil
+	}
+}
+
+// logSent records a sent notification with attrs, adding the request ID
+// from ctx when there is one.
+func (u *User) logSent(ctx context.Context, attrs ...slog.Attr) {
+	if id, ok := RequestIDFromContext(ctx); ok {
+		attrs = append(attrs, slog.String("request_id", id))
+	}
+	u.log().LogAttrs(ctx, slog.LevelInfo, "notification sent", attrs...)
+}
+
+// log returns the user's logger, falling back to slog.Default.
+func (u *User) log() *slog.Logger {
+	if u.logger != nil {
+		return u.logger
+	}
+
+	return slog.Default()
+}
+
+// Address reports the user's email address and the email channel.
+func (u *User) Address() (recipient, channel string) {
+	return u.Email, "email"
+}
+
+// RecipientID identifies the person behind the user, whichever channel
+// they are reached on: their email as normalized by NormalizeEmail, or
+// the email as given when it can't be normalized. Types embedding User,
+// such as Admin and SMSUser, share it.
+func (u *User) RecipientID() string {
+	if email, err := NormalizeEmail(u.Email); err == nil {
+		return email
+	}
+
+	return u.Email
+}
+
+// NotificationKey identifies the user's notification by channel and
+// address, so one person is recognized however often they appear.
+func (u *User) NotificationKey() string {
+	return "email:" + u.Email
+}
+
+// Clone returns an independent copy of the user, so a copy can be changed
+// for one send without affecting the original. The template functions,
+// labels and attachment list are copied; the output writer, logger,
+// catalog and attachment contents are shared on purpose, since they refer
+// to resources rather than to the user.
+func (u *User) Clone() *User {
+	c := *u
+	c.funcs = maps.Clone(u.funcs)
+	c.labels = maps.Clone(u.labels)
+	c.attachments = slices.Clone(u.attachments)
+
+	return &c
+}
+
+// Equal reports whether u and other have the same name and email,
+// ignoring settings such as the writer, logger and locale. Two nil users
+// are equal; a nil and a non-nil user are not.
+func (u *User) Equal(other *User) bool {
+	if u == nil || other == nil {
+		return u == other
+	}
+
+	return u.Name == other.Name && u.Email == other.Email
+}
+
+// Redacted describes the user for logs, as in
+// "Janet Jones <j***t@email.com>": the name is sanitized and the email
+// masked with MaskEmail.
+func (u *User) Redacted() string {
+	return Sanitize(u.Name) + " <" + MaskEmail(u.Email) + ">"
+}
+
+// String describes the user, as in "user Janet Jones <j***t@email.com>".
+// It uses the redacted form so printing a user never leaks their address.
+func (u *User) String() string {
+	return "user " + u.Redacted()
+}
@@ -0,0 +1,708 @@
+package notify_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/mail"
+	"strings"
+	"sync"
+	"testing"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+// quiet returns options that keep a recipient's output and logs out of
+// the test log, writing the output to buf instead.
+func quiet(buf *bytes.Buffer) []notify.UserOption {
+	return []notify.UserOption{
+		notify.WithWriter(buf),
+		notify.WithLogger(slog.New(slog.DiscardHandler)),
+	}
+}
+
+// quietAdmin is quiet for NewAdmin, followed by opts.
+func quietAdmin(buf *bytes.Buffer, opts ...notify.AdminOption) []notify.AdminOption {
+	var all []notify.AdminOption
+	for _, opt := range quiet(buf) {
+		all = append(all, opt)
+	}
+
+	return append(all, opts...)
+}
+
+func TestNewUserErrors(t *testing.T) {
+	tests := []struct {
+		name, user, email string
+		want              error
+	}{
+		{"empty name", "", "janet@email.com", notify.ErrEmptyName},
+		{"blank name", "  ", "janet@email.com", notify.ErrEmptyName},
+		{"empty email", "Janet", "", notify.ErrEmptyEmail},
+		{"blank email", "Janet", " \t", notify.ErrEmptyEmail},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := notify.NewUser(tt.user, tt.email)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("NewUser(%q, %q) error = %v, want %v", tt.user, tt.email, err, tt.want)
+			}
+			if u != nil {
+				t.Errorf("NewUser(%q, %q) = %+v, want nil", tt.user, tt.email, u)
+			}
+		})
+	}
+}
+
+func TestUserNotify(t *testing.T) {
+	var buf bytes.Buffer
+	u, err := notify.NewUser("Janet Jones", "janet@email.com", quiet(&buf)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := u.Notify(context.Background()); err != nil {
+		t.Fatalf("Notify() = %v", err)
+	}
+	if got, want := buf.String(), "Sending user email to Janet Jones<janet@email.com>\n"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestWithWriter(t *testing.T) {
+	tests := []struct {
+		name  string
+		build func(w *bytes.Buffer) (notify.Notifier, error)
+		want  string
+	}{
+		{"user", func(w *bytes.Buffer) (notify.Notifier, error) {
+			return notify.NewUser("Janet Jones", "janet@email.com", quiet(w)...)
+		}, "Sending user email to Janet Jones<janet@email.com>\n"},
+		{"admin", func(w *bytes.Buffer) (notify.Notifier, error) {
+			return notify.NewAdmin("Lisa Smith", "lisa@email.com", quietAdmin(w)...)
+		}, "Sending admin email to Lisa Smith<lisa@email.com> with level normal\n"},
+		{"admin SetOutput", func(w *bytes.Buffer) (notify.Notifier, error) {
+			a, err := notify.NewAdmin("Lisa Smith", "lisa@email.com", quietAdmin(new(bytes.Buffer), notify.WithLevel(notify.LevelRoot))...)
+			if a != nil {
+				a.SetOutput(w)
+			}
+			return a, err
+		}, "Sending admin email to Lisa Smith<lisa@email.com> with level root\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			n, err := tt.build(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := n.Notify(context.Background()); err != nil {
+				t.Fatalf("Notify() = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNotifyCancelled(t *testing.T) {
+	var buf bytes.Buffer
+	u, err := notify.NewUser("Janet Jones", "janet@email.com", quiet(&buf)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := notify.NewAdmin("Lisa Smith", "lisa@email.com", notify.WithWriter(&buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name string
+		n    notify.Notifier
+	}{
+		{"user", u},
+		{"admin", a},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.n.Notify(ctx); !errors.Is(err, context.Canceled) {
+				t.Errorf("Notify() = %v, want %v", err, context.Canceled)
+			}
+		})
+	}
+	if buf.Len() != 0 {
+		t.Errorf("cancelled notifications wrote %q", buf.String())
+	}
+}
+
+func TestString(t *testing.T) {
+	janet := notify.User{Name: "Janet Jones", Email: "janet@email.com"}
+	tests := []struct {
+		name string
+		v    fmt.Stringer
+		want string
+	}{
+		{"user", &janet, "user Janet Jones <j***t@email.com>"},
+		{"admin", &notify.Admin{User: janet, Level: notify.LevelSuper}, "admin[super] Janet Jones <j***t@email.com>"},
+		{"admin without level", &notify.Admin{User: janet}, "admin[] Janet Jones <j***t@email.com>"},
+		{"control characters", &notify.User{Name: "Janet\nFAKE", Email: "janet@email.com"}, `user Janet\nFAKE <j***t@email.com>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.v.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+			if got := fmt.Sprint(tt.v); got != tt.want {
+				t.Errorf("fmt.Sprint() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewUserValidatesEmail(t *testing.T) {
+	tests := []struct {
+		name, user, email string
+		wantErr           error
+	}{
+		{"valid", "Janet Jones", "janet@email.com", nil},
+		{"missing @", "Janet Jones", "janet.email.com", notify.ErrInvalidEmail},
+		{"missing domain", "Janet Jones", "janet@", notify.ErrInvalidEmail},
+		{"empty name", "", "janet@email.com", notify.ErrEmptyName},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := notify.NewUser(tt.user, tt.email)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewUser(%q, %q) error = %v, want %v", tt.user, tt.email, err, tt.wantErr)
+			}
+			if err != nil {
+				if !errors.Is(err, notify.ErrInvalidRecipient) {
+					t.Errorf("NewUser(%q, %q) error = %v, want it to match ErrInvalidRecipient", tt.user, tt.email, err)
+				}
+				return
+			}
+			if u.Name != tt.user || u.Email != tt.email {
+				t.Errorf("NewUser() = %q <%s>, want %q <%s>", u.Name, u.Email, tt.user, tt.email)
+			}
+		})
+	}
+}
+
+func TestUserLiteralStillNotifies(t *testing.T) {
+	var buf bytes.Buffer
+	u := &notify.User{Name: "Janet", Email: "janet@email.com"}
+	u.SetOutput(&buf)
+	if err := u.Notify(context.Background()); err != nil {
+		t.Fatalf("Notify() = %v", err)
+	}
+
+	bad := &notify.User{Name: "Janet", Email: "janet"}
+	bad.SetOutput(&buf)
+	if err := bad.Notify(context.Background()); !errors.Is(err, notify.ErrInvalidEmail) {
+		t.Errorf("Notify() with a malformed literal = %v, want %v", err, notify.ErrInvalidEmail)
+	}
+}
+
+// captureHandler is a slog.Handler that keeps every record it handles.
+type captureHandler struct {
+	mu      sync.Mutex
+	records []slog.Record
+}
+
+func (h *captureHandler) Enabled(context.Context, slog.Level) bool { return true }
+func (h *captureHandler) WithAttrs([]slog.Attr) slog.Handler       { return h }
+func (h *captureHandler) WithGroup(string) slog.Handler            { return h }
+
+func (h *captureHandler) Handle(_ context.Context, r slog.Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.records = append(h.records, r.Clone())
+
+	return nil
+}
+
+// attrs returns the attributes of every record handled, one map per
+// record, with values formatted as strings and the message and level
+// under "@msg" and "@level".
+func (h *captureHandler) attrs() []map[string]string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var all []map[string]string
+	for _, r := range h.records {
+		m := map[string]string{"@msg": r.Message, "@level": r.Level.String()}
+		r.Attrs(func(a slog.Attr) bool {
+			m[a.Key] = a.Value.String()
+			return true
+		})
+		all = append(all, m)
+	}
+
+	return all
+}
+
+func TestNotifyLogs(t *testing.T) {
+	tests := []struct {
+		name string
+		n    func(notify.UserOption) (notify.Notifier, error)
+		want map[string]string
+	}{
+		{
+			name: "user",
+			n: func(opt notify.UserOption) (notify.Notifier, error) {
+				return notify.NewUser("Janet", "janet@email.com", notify.WithWriter(io.Discard), opt)
+			},
+			want: map[string]string{"@msg": "notification sent", "@level": "INFO", "name": "Janet", "email": "janet@email.com", "channel": "email"},
+		},
+		{
+			name: "admin",
+			n: func(opt notify.UserOption) (notify.Notifier, error) {
+				return notify.NewAdmin("Lisa", "lisa@email.com", notify.WithWriter(io.Discard), opt)
+			},
+			want: map[string]string{"@msg": "notification sent", "@level": "INFO", "name": "Lisa", "email": "lisa@email.com", "channel": "email", "level": "normal"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &captureHandler{}
+			n, err := tt.n(notify.WithLogger(slog.New(h)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := n.Notify(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			records := h.attrs()
+			if len(records) != 1 {
+				t.Fatalf("logged %d records, want 1", len(records))
+			}
+			for k, want := range tt.want {
+				if got := records[0][k]; got != want {
+					t.Errorf("%s = %q, want %q", k, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestNotifyLogsToDefault(t *testing.T) {
+	h := &captureHandler{}
+	saved := slog.Default()
+	slog.SetDefault(slog.New(h))
+	t.Cleanup(func() { slog.SetDefault(saved) })
+
+	u := &notify.User{Name: "Janet", Email: "janet@email.com"}
+	u.SetOutput(io.Discard)
+	if err := u.Notify(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if records := h.attrs(); len(records) != 1 || records[0]["email"] != "janet@email.com" {
+		t.Errorf("default logger got %v, want one record for janet@email.com", records)
+	}
+}
+
+func TestNormalizeEmail(t *testing.T) {
+	tests := []struct {
+		in, want string
+		wantErr  error
+	}{
+		{"janet@email.com", "janet@email.com", nil},
+		{"  janet@email.com\t", "janet@email.com", nil},
+		{"Janet@EMAIL.Com", "Janet@email.com", nil},
+		{"janet+news@email.co.uk", "janet+news@email.co.uk", nil},
+		{"o'brien@email.com", "o'brien@email.com", nil},
+		{`"janet jones"@email.com`, "", notify.ErrInvalidEmail},
+		{"josé@correo.es", "josé@correo.es", nil},
+		{"用户@例子.广告", "用户@例子.广告", nil},
+		{"janet@BÜCHER.de", "janet@bücher.de", nil},
+		{"janet@xn--bcher-kva.de", "janet@xn--bcher-kva.de", nil},
+		{"", "", notify.ErrEmptyEmail},
+		{"   ", "", notify.ErrEmptyEmail},
+		{"janet", "", notify.ErrInvalidEmail},
+		{"janet@", "", notify.ErrInvalidEmail},
+		{"@email.com", "", notify.ErrInvalidEmail},
+		{"janet@@email.com", "", notify.ErrInvalidEmail},
+		{"jan et@email.com", "", notify.ErrInvalidEmail},
+		{"janet..jones@email.com", "", notify.ErrInvalidEmail},
+		{"Janet <janet@email.com>", "", notify.ErrInvalidEmail},
+		{"<janet@email.com>", "", notify.ErrInvalidEmail},
+		{"janet@email.com, bob@email.com", "", notify.ErrInvalidEmail},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := notify.NormalizeEmail(tt.in)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NormalizeEmail(%q) error = %v, want %v", tt.in, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizeEmail(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewAdminNormalizesEmail(t *testing.T) {
+	a, err := notify.NewAdmin(" Lisa ", " Lisa@EMAIL.com ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Name != "Lisa" || a.Email != "Lisa@email.com" {
+		t.Errorf("NewAdmin() = %q <%s>, want %q <%s>", a.Name, a.Email, "Lisa", "Lisa@email.com")
+	}
+	if _, err := notify.NewAdmin("Lisa", "Lisa <lisa@email.com>"); !errors.Is(err, notify.ErrInvalidEmail) {
+		t.Errorf("NewAdmin() with a display name error = %v, want %v", err, notify.ErrInvalidEmail)
+	}
+}
+
+func TestNotifyRevalidatesZeroValue(t *testing.T) {
+	var buf bytes.Buffer
+	var u notify.User
+	u.SetOutput(&buf)
+	if err := u.Notify(context.Background()); !errors.Is(err, notify.ErrEmptyEmail) {
+		t.Errorf("Notify() on a zero User = %v, want %v", err, notify.ErrEmptyEmail)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("Notify() wrote %q for an invalid user", buf.String())
+	}
+}
+
+func TestConcurrentNotifySharedWriter(t *testing.T) {
+	// bytes.Buffer isn't safe for concurrent use on its own, so this only
+	// passes under -race if Notify serializes its writes.
+	var buf bytes.Buffer
+	var ns []notify.Notifier
+	for i := range 10 {
+		u, err := notify.NewUser(fmt.Sprint("user", i), fmt.Sprintf("user%d@email.com", i), quiet(&buf)...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		a, err := notify.NewAdmin(fmt.Sprint("admin", i), fmt.Sprintf("admin%d@email.com", i), quietAdmin(&buf)...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ns = append(ns, u, a)
+	}
+
+	var wg sync.WaitGroup
+	for _, n := range ns {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 20 {
+				n.Notify(context.Background())
+			}
+		}()
+	}
+	wg.Wait()
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 400 {
+		t.Fatalf("got %d lines, want 400", len(lines))
+	}
+	for _, line := range lines {
+		if !strings.HasPrefix(line, "Sending user email to user") && !strings.HasPrefix(line, "Sending admin email to admin") {
+			t.Fatalf("interleaved line %q", line)
+		}
+	}
+}
+
+func TestUserClone(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(c *notify.User) error
+	}{
+		{"email", func(c *notify.User) error { c.Email = "bob@email.com"; return nil }},
+		{"name", func(c *notify.User) error { c.Name = "Bob Brown"; return nil }},
+		{"labels", func(c *notify.User) error { return notify.WithLabels(map[string]string{"team": "ops"})(c) }},
+		{"attachments", func(c *notify.User) error {
+			return c.Attach(notify.Attachment{Filename: "extra.txt", Content: []byte("x")})
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			opts := append(quiet(&buf), notify.WithLabels(map[string]string{"team": "billing"}))
+			u, err := notify.NewUser("Janet Jones", "janet@email.com", opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := u.Attach(notify.Attachment{Filename: "receipt.txt", Content: []byte("paid")}); err != nil {
+				t.Fatal(err)
+			}
+
+			c := u.Clone()
+			if err := tt.mutate(c); err != nil {
+				t.Fatal(err)
+			}
+			if u.Name != "Janet Jones" || u.Email != "janet@email.com" {
+				t.Errorf("original = %s<%s>, want it unchanged", u.Name, u.Email)
+			}
+			if got := u.Labels(); len(got) != 1 || got["team"] != "billing" {
+				t.Errorf("original labels = %v, want team=billing", got)
+			}
+			if got := u.Attachments(); len(got) != 1 || got[0].Filename != "receipt.txt" {
+				t.Errorf("original attachments = %v, want only receipt.txt", got)
+			}
+		})
+	}
+}
+
+func TestUserCloneSharesWriter(t *testing.T) {
+	var buf bytes.Buffer
+	u, err := notify.NewUser("Janet Jones", "janet@email.com", quiet(&buf)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := u.Clone()
+	c.Email = "bob@email.com"
+	if err := c.Notify(context.Background()); err != nil {
+		t.Fatalf("Notify() = %v", err)
+	}
+	if got, want := buf.String(), "Sending user email to Janet Jones<bob@email.com>\n"; got != want {
+		t.Errorf("original's writer got %q, want the clone's output %q", got, want)
+	}
+
+	// Redirecting the clone leaves the original's writer alone.
+	var other bytes.Buffer
+	c.SetOutput(&other)
+	buf.Reset()
+	if err := u.Notify(context.Background()); err != nil {
+		t.Fatalf("Notify() = %v", err)
+	}
+	if buf.Len() == 0 || other.Len() != 0 {
+		t.Errorf("original wrote %q and the clone's writer got %q, want only the original's", buf.String(), other.String())
+	}
+}
+
+func TestUserEqual(t *testing.T) {
+	var buf bytes.Buffer
+	configured, err := notify.NewUser("Janet Jones", "janet@email.com", append(quiet(&buf), notify.WithLocale("fr-CA"))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	janet := &notify.User{Name: "Janet Jones", Email: "janet@email.com"}
+
+	tests := []struct {
+		name string
+		a, b *notify.User
+		want bool
+	}{
+		{"equal", janet, &notify.User{Name: "Janet Jones", Email: "janet@email.com"}, true},
+		{"same pointer", janet, janet, true},
+		{"ignores writer, logger and locale", janet, configured, true},
+		{"differing email", janet, &notify.User{Name: "Janet Jones", Email: "jj@email.com"}, false},
+		{"differing name", janet, &notify.User{Name: "Janet Smith", Email: "janet@email.com"}, false},
+		{"both nil", nil, nil, true},
+		{"nil receiver", nil, janet, false},
+		{"nil argument", janet, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.a.Equal(tt.b); got != tt.want {
+				t.Errorf("Equal() = %v, want %v", got, tt.want)
+			}
+			if got := tt.b.Equal(tt.a); got != tt.want {
+				t.Errorf("Equal() reversed = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"plain", "Janet Jones", "Janet Jones"},
+		{"unicode", "Zoë Ångström 李", "Zoë Ångström 李"},
+		{"empty", "", ""},
+		{"newline", "Janet\nFAKE LOG", `Janet\nFAKE LOG`},
+		{"carriage return", "Janet\r\nFAKE LOG", `Janet\r\nFAKE LOG`},
+		{"tab and escape", "Janet\t\x1b[31m", `Janet\t\x1b[31m`},
+		{"nul and delete", "a\x00b\x7f", `a\x00b\x7f`},
+		{"line separators", "a\u2028b\u2029c", `a\u2028b\u2029c`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := notify.Sanitize(tt.in); got != tt.want {
+				t.Errorf("Sanitize(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNotifySanitizesName(t *testing.T) {
+	const forged = "Janet\nFAKE LOG level=root\r"
+
+	tests := []struct {
+		name  string
+		build func(buf *bytes.Buffer) (notify.Notifier, error)
+		want  string
+	}{
+		{"user", func(buf *bytes.Buffer) (notify.Notifier, error) {
+			return notify.NewUser(forged, "janet@email.com", quiet(buf)...)
+		}, `Sending user email to Janet\nFAKE LOG level=root<janet@email.com>` + "\n"},
+		{"admin", func(buf *bytes.Buffer) (notify.Notifier, error) {
+			return notify.NewAdmin(forged, "janet@email.com", quietAdmin(buf)...)
+		}, `Sending admin email to Janet\nFAKE LOG level=root<janet@email.com> with level normal` + "\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			n, err := tt.build(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := n.Notify(context.Background()); err != nil {
+				t.Fatalf("Notify() = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+			if lines := strings.Count(buf.String(), "\n"); lines != 1 {
+				t.Errorf("output has %d lines, want 1", lines)
+			}
+		})
+	}
+}
+
+func TestRedacted(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{ Redacted() string }
+		want string
+	}{
+		{"user", &notify.User{Name: "Janet Jones", Email: "janet@email.com"}, "Janet Jones <j***t@email.com>"},
+		{"admin through embedding", &notify.Admin{User: notify.User{Name: "Lisa Smith", Email: "lisa@email.com"}, Level: notify.LevelRoot},
+			"Lisa Smith <l***a@email.com>"},
+		{"two-character local part", &notify.User{Name: "Jo", Email: "jo@email.com"}, "Jo <j***@email.com>"},
+		{"one-character local part", &notify.User{Name: "J", Email: "j@email.com"}, "J <***@email.com>"},
+		{"unicode local part", &notify.User{Name: "Zoë", Email: "zoë@email.com"}, "Zoë <z***ë@email.com>"},
+		{"control characters", &notify.User{Name: "Janet\nFAKE", Email: "janet@email.com"}, `Janet\nFAKE <j***t@email.com>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.v.Redacted(); got != tt.want {
+				t.Errorf("Redacted() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNotifyReformatsAfterChanges(t *testing.T) {
+	var buf bytes.Buffer
+	u, err := notify.NewUser("Janet Jones", "janet@email.com", quiet(&buf)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clone := u.Clone()
+	clone.SetOutput(&buf)
+
+	steps := []struct {
+		name   string
+		change func()
+		n      notify.Notifier
+		want   string
+	}{
+		{"first send", func() {}, u, "Sending user email to Janet Jones<janet@email.com>\n"},
+		{"same again", func() {}, u, "Sending user email to Janet Jones<janet@email.com>\n"},
+		{"new name", func() { u.Name = "Janet Smith" }, u, "Sending user email to Janet Smith<janet@email.com>\n"},
+		{"new email", func() { u.Email = "jsmith@email.com" }, u, "Sending user email to Janet Smith<jsmith@email.com>\n"},
+		{"clone keeps its own fields", func() {}, clone, "Sending user email to Janet Jones<janet@email.com>\n"},
+		{"new locale", func() { notify.WithLocale("fr-FR")(u) }, u, "Envoi d'un e-mail utilisateur à Janet Smith<jsmith@email.com>\n"},
+		{"new catalog", func() {
+			notify.WithCatalog(notify.Catalog{"en": {notify.KeyUserEmail: "Hi %s (%s)"}})(u)
+			notify.WithLocale("en")(u)
+		}, u, "Hi Janet Smith (jsmith@email.com)\n"},
+		{"clone after the original changed", func() {}, clone, "Sending user email to Janet Jones<janet@email.com>\n"},
+	}
+	for _, s := range steps {
+		s.change()
+		buf.Reset()
+		if err := s.n.Notify(context.Background()); err != nil {
+			t.Fatalf("%s: Notify() = %v", s.name, err)
+		}
+		if got := buf.String(); got != s.want {
+			t.Errorf("%s: output = %q, want %q", s.name, got, s.want)
+		}
+	}
+}
+
+func TestConcurrentNotifyClones(t *testing.T) {
+	var buf lockedBuffer
+	u, err := notify.NewUser("Janet Jones", "janet@email.com", notify.WithWriter(&buf), notify.WithLogger(slog.New(slog.DiscardHandler)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Clones share the formatted-message cache; each must still print its
+	// own address.
+	var wg sync.WaitGroup
+	for i := range 4 {
+		c := u.Clone()
+		c.Email = fmt.Sprintf("user%d@email.com", i)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 50 {
+				c.Notify(context.Background())
+			}
+		}()
+	}
+	wg.Wait()
+
+	for line := range strings.Lines(buf.String()) {
+		if !strings.HasPrefix(line, "Sending user email to Janet Jones<user") {
+			t.Fatalf("unexpected line %q", line)
+		}
+	}
+	for i := range 4 {
+		if got := strings.Count(buf.String(), fmt.Sprintf("<user%d@email.com>", i)); got != 50 {
+			t.Errorf("user%d notified %d times, want 50", i, got)
+		}
+	}
+}
+
+func FuzzParseEmail(f *testing.F) {
+	for _, seed := range []string{
+		"janet@email.com",
+		"  Janet@EMAIL.com ",
+		"Janet <janet@email.com>",
+		`"janet jones"@email.com`,
+		"janet@[127.0.0.1]",
+		"janet\x00@email.com",
+		"=?utf-8?q?janet?= <janet@email.com>",
+		"@", "", "janet@", "@email.com",
+		strings.Repeat("a", 10000) + "@email.com",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, email string) {
+		got, err := notify.NormalizeEmail(email)
+		if err != nil {
+			if !errors.Is(err, notify.ErrEmptyEmail) && !errors.Is(err, notify.ErrInvalidEmail) {
+				t.Errorf("NormalizeEmail(%q) = %v, want ErrEmptyEmail or ErrInvalidEmail", email, err)
+			}
+			return
+		}
+
+		// A normalized address is a bare address that normalizes to itself.
+		addr, err := mail.ParseAddress(got)
+		if err != nil || addr.Name != "" || addr.Address != got {
+			t.Errorf("NormalizeEmail(%q) = %q, which doesn't parse back as a bare address: %v", email, got, err)
+		}
+		if again, err := notify.NormalizeEmail(got); err != nil || again != got {
+			t.Errorf("NormalizeEmail(%q) = %q, %v, want it unchanged", got, again, err)
+		}
+	})
+}
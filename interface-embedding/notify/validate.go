@@ -0,0 +1,123 @@
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+// ErrNotValidatable is reported by DryRun for notifiers that can't check
+// themselves, so they aren't mistaken for ones that passed.
+var ErrNotValidatable = errors.New("notify: notifier is not validatable")
+
+// Validatable is implemented by notifiers that can check they are
+// well-formed without sending anything.
+type Validatable interface {
+	Validate() error
+}
+
+// DryRun validates every notifier without sending, looking through any
+// wrappers for a Validatable. The returned slice has one entry per
+// notifier, in order, holding nil for each that is ready to send and
+// ErrNotValidatable for each that can't be checked.
+func DryRun(notifiers []Notifier) []error {
+	errs := make([]error, len(notifiers))
+	for i, n := range notifiers {
+		errs[i] = validate(n)
+	}
+
+	return errs
+}
+
+// validate calls Validate on n or the first notifier it wraps that
+// implements Validatable.
+func validate(n Notifier) error {
+	for n != nil {
+		if v, ok := n.(Validatable); ok {
+			return v.Validate()
+		}
+		w, ok := n.(Wrapper)
+		if !ok {
+			break
+		}
+		n = w.Unwrap()
+	}
+
+	recipient, _ := AddressOf(n)
+	return fmt.Errorf("%w: %T for %s", ErrNotValidatable, n, recipient)
+}
+
+// Validate checks that the user has a name and a usable email, and that
+// their message renders.
+func (u *User) Validate() error {
+	if u.Name == "" {
+		return &NotificationError{Recipient: u.Email, Channel: "email", Err: ErrEmptyName}
+	}
+	if _, err := NormalizeEmail(u.Email); err != nil {
+		return &NotificationError{Recipient: u.Email, Channel: "email", Err: err}
+	}
+	if _, err := u.message(); err != nil {
+		return &NotificationError{Recipient: u.Email, Channel: "email", Err: err}
+	}
+
+	return nil
+}
+
+// Validate checks the admin's name, email, level and CC addresses, and
+// that their message renders. It can't reuse User.Validate, which would
+// render the user's message rather than the admin's.
+func (a *Admin) Validate() error {
+	if a.Name == "" {
+		return &NotificationError{Recipient: a.Email, Channel: "email", Err: ErrEmptyName}
+	}
+	if _, err := NormalizeEmail(a.Email); err != nil {
+		return &NotificationError{Recipient: a.Email, Channel: "email", Err: err}
+	}
+	if err := a.Level.Validate(); err != nil {
+		return &NotificationError{Recipient: a.Email, Channel: "email", Err: err}
+	}
+	for _, cc := range a.CC {
+		if _, err := NormalizeEmail(cc); err != nil {
+			return &NotificationError{Recipient: a.Email, Channel: "email", Err: err}
+		}
+	}
+	if err := a.checkSelfCC(); err != nil {
+		return &NotificationError{Recipient: a.Email, Channel: "email", Err: err}
+	}
+	if _, err := a.message(); err != nil {
+		return &NotificationError{Recipient: a.Email, Channel: "email", Err: err}
+	}
+
+	return nil
+}
+
+// Validate checks that the phone number is in E.164 format.
+func (s *SMSUser) Validate() error {
+	if !e164.MatchString(s.Phone) {
+		err := invalidRecipient(fmt.Errorf("phone %q is not in E.164 format", s.Phone))
+		return &NotificationError{Recipient: s.Phone, Channel: "sms", Err: err}
+	}
+
+	return nil
+}
+
+// Validate checks that the webhook is an https URL and a channel is set.
+func (s *SlackUser) Validate() error {
+	u, err := url.Parse(s.WebhookURL)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		err := invalidRecipient(fmt.Errorf("webhook %q is not an https URL", s.WebhookURL))
+		return &NotificationError{Recipient: s.Channel, Channel: "slack", Err: err}
+	}
+	if s.Channel == "" {
+		err := invalidRecipient(errors.New("channel is required"))
+		return &NotificationError{Recipient: s.Name, Channel: "slack", Err: err}
+	}
+
+	return nil
+}
+
+// Validate checks the delegate's admin and the user they act for, who is
+// the fallback recipient.
+func (d *Delegate) Validate() error {
+	return errors.Join(d.Admin.Validate(), d.OnBehalfOf.Validate())
+}
@@ -0,0 +1,98 @@
+package notify_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+func TestDryRun(t *testing.T) {
+	janet := notify.User{Name: "Janet Jones", Email: "janet@email.com"}
+	hook := "https://hooks.slack.com/services/T000/B000/XXXX"
+
+	tests := []struct {
+		name string
+		n    notify.Notifier
+		want error
+	}{
+		{"user", &janet, nil},
+		{"user without a name", &notify.User{Email: "janet@email.com"}, notify.ErrEmptyName},
+		{"user with a bad email", &notify.User{Name: "Janet", Email: "janet"}, notify.ErrInvalidEmail},
+		{"admin", &notify.Admin{User: janet, Level: notify.LevelRoot}, nil},
+		{"admin with a bad level", &notify.Admin{User: janet, Level: "god"}, notify.ErrUnknownLevel},
+		{"admin copied on their own email", &notify.Admin{User: janet, Level: notify.LevelRoot, CC: []string{janet.Email}}, notify.ErrSelfCC},
+		{"admin with a bad CC", &notify.Admin{User: janet, Level: notify.LevelRoot, CC: []string{"oncall"}}, notify.ErrInvalidEmail},
+		{"sms", &notify.SMSUser{User: janet, Phone: "+15555550100"}, nil},
+		{"sms with a bad phone", &notify.SMSUser{User: janet, Phone: "555-0100"}, notify.ErrInvalidRecipient},
+		{"slack", &notify.SlackUser{User: janet, WebhookURL: hook, Channel: "#alerts"}, nil},
+		{"slack over http", &notify.SlackUser{User: janet, WebhookURL: "http://hooks.slack.com/x", Channel: "#alerts"}, notify.ErrInvalidRecipient},
+		{"slack without a channel", &notify.SlackUser{User: janet, WebhookURL: hook}, notify.ErrInvalidRecipient},
+		{"wrapped", notify.WithTimeout(&notify.User{Name: "Janet", Email: "janet"}, time.Second), notify.ErrInvalidEmail},
+		{"func", notify.NotifierFunc(func(context.Context) error { return nil }), notify.ErrNotValidatable},
+		{"mock", &notify.MockNotifier{}, notify.ErrNotValidatable},
+	}
+
+	var ns []notify.Notifier
+	for _, tt := range tests {
+		ns = append(ns, tt.n)
+	}
+	errs := notify.DryRun(ns)
+	if len(errs) != len(tests) {
+		t.Fatalf("DryRun() returned %d errors, want %d", len(errs), len(tests))
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !errors.Is(errs[i], tt.want) {
+				t.Errorf("DryRun()[%d] = %v, want %v", i, errs[i], tt.want)
+			}
+		})
+	}
+}
+
+func TestDryRunSendsNothing(t *testing.T) {
+	var buf bytes.Buffer
+	u, err := notify.NewUser("Janet Jones", "janet@email.com", quiet(&buf)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mock := &notify.MockNotifier{}
+
+	notify.DryRun([]notify.Notifier{u, mock})
+	if buf.Len() != 0 || mock.Calls() != 0 {
+		t.Errorf("DryRun() sent: output %q, %d mock calls", buf.String(), mock.Calls())
+	}
+}
+
+// TestChannelsNotifyValidates checks that the SMS and Slack notifiers
+// refuse to send exactly what Validate rejects.
+func TestChannelsNotifyValidates(t *testing.T) {
+	janet := notify.User{Name: "Janet Jones", Email: "janet@email.com"}
+
+	tests := []struct {
+		name string
+		n    interface {
+			notify.Notifier
+			notify.Validatable
+		}
+	}{
+		{"sms", &notify.SMSUser{User: janet, Phone: "0100"}},
+		{"slack scheme", &notify.SlackUser{User: janet, WebhookURL: "ftp://hooks.slack.com/x", Channel: "#alerts"}},
+		{"slack host", &notify.SlackUser{User: janet, WebhookURL: "https:///x", Channel: "#alerts"}},
+		{"slack channel", &notify.SlackUser{User: janet, WebhookURL: "https://hooks.slack.com/x"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := tt.n.Validate()
+			if want == nil {
+				t.Fatal("Validate() = nil, want an error")
+			}
+			if got := tt.n.Notify(context.Background()); got == nil || got.Error() != want.Error() || !notify.IsPermanent(got) {
+				t.Errorf("Notify() = %v, want the permanent Validate error %v", got, want)
+			}
+		})
+	}
+}
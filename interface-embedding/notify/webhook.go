@@ -0,0 +1,177 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// WebhookNotifier posts a signed JSON description of a notification to a
+// URL. Server errors and network failures are retried with capped
+// exponential backoff; 4xx responses are permanent and returned at once.
+type WebhookNotifier struct {
+	URL string
+	To  *User
+
+	// Level is included in the payload when set, for admin notifications.
+	Level Level
+
+	// Secret signs each body with HMAC-SHA256. The signature is sent in
+	// the X-Signature header as described by SignWebhook.
+	Secret []byte
+
+	// MaxAttempts defaults to 3. BaseDelay, which defaults to 100ms, is
+	// the wait after the first failure; each later wait doubles, up to
+	// MaxDelay, which defaults to 5s.
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+
+	// Client sends the request. It defaults to http.DefaultClient and can
+	// be replaced to point at a test server.
+	Client *http.Client
+
+	// Clock stamps the payload and times the waits between attempts. It
+	// defaults to SystemClock.
+	Clock Clock
+}
+
+// webhookSnippetSize is how much of an error response's body is quoted in
+// the error.
+const webhookSnippetSize = 512
+
+// webhookMaxBodyBytes is the largest payload WebhookNotifier posts, well
+// within what common receivers accept. A JSON payload can't be truncated
+// without breaking it, so larger ones are rejected.
+const webhookMaxBodyBytes = 1 << 20
+
+// webhookPayload is the JSON body posted by WebhookNotifier.
+type webhookPayload struct {
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	Level     Level     `json:"level,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// SignWebhook returns the X-Signature header value for body: "sha256="
+// followed by the hex HMAC-SHA256 of body under secret. Receivers should
+// compute the same value and compare it with hmac.Equal.
+func SignWebhook(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Notify posts the payload, retrying until it is accepted, fails
+// permanently, runs out of attempts, or ctx is done. The same body and
+// signature are sent on every attempt.
+func (w *WebhookNotifier) Notify(ctx context.Context) error {
+	clock := w.Clock
+	if clock == nil {
+		clock = SystemClock
+	}
+
+	body, err := json.Marshal(webhookPayload{
+		Name:      w.To.Name,
+		Email:     w.To.Email,
+		Level:     w.Level,
+		Timestamp: clock.Now().UTC(),
+	})
+	if err != nil {
+		return w.fail(err)
+	}
+	if len(body) > webhookMaxBodyBytes {
+		return w.fail(fmt.Errorf("%w: payload is %d bytes, limit is %d", ErrPayloadTooLarge, len(body), webhookMaxBodyBytes))
+	}
+	signature := SignWebhook(w.Secret, body)
+
+	attempts := w.MaxAttempts
+	if attempts <= 0 {
+		attempts = 3
+	}
+	base := w.BaseDelay
+	if base <= 0 {
+		base = 100 * time.Millisecond
+	}
+	maxDelay := w.MaxDelay
+	if maxDelay <= 0 {
+		maxDelay = 5 * time.Second
+	}
+	backoff := ExponentialBackoff(base, false)
+
+	for attempt := 1; ; attempt++ {
+		countAttempt(ctx)
+		err := w.post(ctx, body, signature)
+		if err == nil || attempt == attempts || !IsRetryable(err) {
+			return w.fail(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return w.fail(errors.Join(err, ctx.Err()))
+		case <-clock.After(min(backoff(attempt), maxDelay)):
+		}
+	}
+}
+
+// Address reports the user's email address and the webhook channel.
+func (w *WebhookNotifier) Address() (recipient, channel string) {
+	return w.To.Email, "webhook"
+}
+
+// post makes one delivery attempt. 4xx responses are marked Permanent.
+// Errors for non-2xx responses quote the start of the response body, which
+// usually says what the receiver objected to.
+func (w *WebhookNotifier) post(ctx context.Context, body []byte, signature string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return Permanent(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Signature", signature)
+
+	client := w.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, webhookSnippetSize))
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		return nil
+	}
+	err = fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	if s := strings.TrimSpace(string(snippet)); s != "" {
+		err = fmt.Errorf("webhook responded with status %d: %q", resp.StatusCode, s)
+	}
+	if resp.StatusCode < 500 {
+		return Permanent(err)
+	}
+
+	return err
+}
+
+// fail wraps a non-nil err in a NotificationError for the recipient,
+// retryable unless err says otherwise.
+func (w *WebhookNotifier) fail(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &NotificationError{Recipient: w.To.Email, Channel: "webhook", Retryable: IsRetryable(err), Err: err}
+}
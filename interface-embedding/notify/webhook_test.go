@@ -0,0 +1,215 @@
+package notify_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+// webhookServer answers with statuses in turn, repeating the last, and
+// counts the requests it gets.
+func webhookServer(t *testing.T, statuses ...int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(hits.Add(1))
+		w.WriteHeader(statuses[min(n, len(statuses))-1])
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, &hits
+}
+
+func TestWebhookNotifierSigns(t *testing.T) {
+	secret := []byte("shared secret")
+	var body []byte
+	var header http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	w := &notify.WebhookNotifier{
+		URL:    srv.URL,
+		To:     &notify.User{Name: "Lisa Smith", Email: "lisa@email.com"},
+		Level:  notify.LevelRoot,
+		Secret: secret,
+		Client: srv.Client(),
+		Clock:  newFakeClock(),
+	}
+	if err := w.Notify(context.Background()); err != nil {
+		t.Fatalf("Notify() = %v", err)
+	}
+
+	if got, want := string(body), `{"name":"Lisa Smith","email":"lisa@email.com","level":"root","timestamp":"2026-01-01T09:00:00Z"}`; got != want {
+		t.Errorf("body = %s, want %s", got, want)
+	}
+	if ct := header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", ct)
+	}
+	// Verify the way a receiver would, from the body as received.
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	sig := header.Get("X-Signature")
+	if !hmac.Equal([]byte(sig), []byte(want)) {
+		t.Errorf("X-Signature = %q, want the HMAC-SHA256 of the body %q", sig, want)
+	}
+	if got := notify.SignWebhook(secret, body); got != want {
+		t.Errorf("SignWebhook() = %q, want %q", got, want)
+	}
+	if hmac.Equal([]byte(sig), []byte(notify.SignWebhook([]byte("other secret"), body))) {
+		t.Error("X-Signature verifies with the wrong secret")
+	}
+}
+
+func TestWebhookNotifierPayloadOmitsEmptyLevel(t *testing.T) {
+	var payload map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&payload)
+	}))
+	defer srv.Close()
+
+	w := &notify.WebhookNotifier{URL: srv.URL, To: &notify.User{Name: "Janet Jones", Email: "janet@email.com"}, Client: srv.Client()}
+	if err := w.Notify(context.Background()); err != nil {
+		t.Fatalf("Notify() = %v", err)
+	}
+	if _, ok := payload["level"]; ok {
+		t.Errorf("payload = %v, want no level for a user", payload)
+	}
+}
+
+func TestWebhookNotifierRetries(t *testing.T) {
+	tests := []struct {
+		name      string
+		statuses  []int
+		wantHits  int32
+		wantErr   bool
+		retryable bool
+		wantWait  time.Duration
+	}{
+		{"accepted", []int{http.StatusOK}, 1, false, false, 0},
+		{"recovers after 503s", []int{503, 503, 202}, 3, false, false, 300 * time.Millisecond},
+		{"503 until out of attempts", []int{503}, 5, true, true, 800 * time.Millisecond},
+		{"400 is permanent", []int{400}, 1, true, false, 0},
+		{"404 after a 500", []int{500, 404}, 2, true, false, 100 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, hits := webhookServer(t, tt.statuses...)
+			start := time.Date(2026, time.January, 1, 9, 0, 0, 0, time.UTC)
+			clock := notify.NewSimClock(start)
+			w := &notify.WebhookNotifier{
+				URL:         srv.URL,
+				To:          &notify.User{Name: "Janet Jones", Email: "janet@email.com"},
+				MaxAttempts: 5,
+				BaseDelay:   100 * time.Millisecond,
+				MaxDelay:    250 * time.Millisecond,
+				Client:      srv.Client(),
+				Clock:       clock,
+			}
+
+			err := w.Notify(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Notify() = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil && notify.IsRetryable(err) != tt.retryable {
+				t.Errorf("IsRetryable(%v) = %v, want %v", err, !tt.retryable, tt.retryable)
+			}
+			if got := hits.Load(); got != tt.wantHits {
+				t.Errorf("server got %d requests, want %d", got, tt.wantHits)
+			}
+			// The waits double from BaseDelay and are capped at MaxDelay.
+			if got := clock.Now().Sub(start); got != tt.wantWait {
+				t.Errorf("waited %v between attempts, want %v", got, tt.wantWait)
+			}
+		})
+	}
+}
+
+func TestWebhookNotifierErrorQuotesBody(t *testing.T) {
+	long := strings.Repeat("x", 600)
+
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"reason", "  invalid signature\n", `webhook responded with status 400: "invalid signature"`},
+		{"no body", "", "webhook responded with status 400"},
+		{"long body cut short", long, `webhook responded with status 400: "` + long[:512] + `"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadRequest)
+				io.WriteString(w, tt.body)
+			}))
+			defer srv.Close()
+
+			w := &notify.WebhookNotifier{URL: srv.URL, To: &notify.User{Name: "Janet Jones", Email: "janet@email.com"}, Client: srv.Client()}
+			err := w.Notify(context.Background())
+			if err == nil || !strings.HasSuffix(err.Error(), tt.want) {
+				t.Errorf("Notify() = %v, want it to end with %s", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestWebhookNotifierNetworkError(t *testing.T) {
+	srv, _ := webhookServer(t, http.StatusOK)
+	url := srv.URL
+	srv.Close()
+
+	w := &notify.WebhookNotifier{
+		URL:         url,
+		To:          &notify.User{Name: "Janet Jones", Email: "janet@email.com"},
+		MaxAttempts: 2,
+		Clock:       notify.NewSimClock(time.Time{}),
+	}
+	err := w.Notify(context.Background())
+	if err == nil || !notify.IsRetryable(err) {
+		t.Errorf("Notify() = %v, want a retryable error", err)
+	}
+}
+
+func TestWebhookNotifierCancelledBetweenRetries(t *testing.T) {
+	srv, hits := webhookServer(t, http.StatusServiceUnavailable)
+	clock := newFakeClock()
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &notify.WebhookNotifier{
+		URL:    srv.URL,
+		To:     &notify.User{Name: "Janet Jones", Email: "janet@email.com"},
+		Client: srv.Client(),
+		Clock:  clock,
+	}
+
+	errs := make(chan error, 1)
+	go func() { errs <- w.Notify(ctx) }()
+	if !clock.BlockUntil(1) {
+		t.Fatal("Notify never waited to retry")
+	}
+	cancel()
+
+	err := <-errs
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Notify() = %v, want it to match %v", err, context.Canceled)
+	}
+	if got := hits.Load(); got != 1 {
+		t.Errorf("server got %d requests, want 1 before the cancellation", got)
+	}
+}
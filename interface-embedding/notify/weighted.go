@@ -0,0 +1,68 @@
+package notify
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNoWeight is returned by a WeightedNotifier whose entries have no
+// positive weight to choose between.
+var ErrNoWeight = errors.New("notify: weighted notifier has no positive weights")
+
+// WeightedEntry is one choice of a WeightedNotifier. Entries with a weight
+// of zero or less are never chosen.
+type WeightedEntry struct {
+	Notifier Notifier
+	Weight   int
+}
+
+// WeightedNotifier delivers each notification through one of Entries,
+// picked at random in proportion to its weight, such as sending 90% of
+// notifications by email and 10% by SMS to compare the channels.
+type WeightedNotifier struct {
+	Entries []WeightedEntry
+
+	// Rand picks the entry. It defaults to SystemRand; use NewSeededRand
+	// so tests and simulations choose deterministically.
+	Rand Rand
+}
+
+// Notify picks an entry and delivers through it.
+func (w *WeightedNotifier) Notify(ctx context.Context) error {
+	n, err := w.Pick()
+	if err != nil {
+		return err
+	}
+
+	return n.Notify(ctx)
+}
+
+// Pick chooses the notifier the next notification would go to, or returns
+// ErrNoWeight.
+func (w *WeightedNotifier) Pick() (Notifier, error) {
+	total := 0
+	for _, e := range w.Entries {
+		total += max(e.Weight, 0)
+	}
+	if total == 0 {
+		return nil, ErrNoWeight
+	}
+
+	rnd := w.Rand
+	if rnd == nil {
+		rnd = SystemRand
+	}
+	r := int(rnd.Int64N(int64(total)))
+
+	for _, e := range w.Entries {
+		if e.Weight <= 0 {
+			continue
+		}
+		if r < e.Weight {
+			return e.Notifier, nil
+		}
+		r -= e.Weight
+	}
+
+	return nil, ErrNoWeight
+}
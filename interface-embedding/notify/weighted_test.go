@@ -0,0 +1,120 @@
+package notify_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+func TestWeightedNotifierDistribution(t *testing.T) {
+	const calls = 10000
+
+	tests := []struct {
+		name    string
+		weights []int
+	}{
+		{"ninety ten", []int{90, 10}},
+		{"even", []int{1, 1, 1}},
+		{"zero and negative never chosen", []int{3, 0, -5, 1}},
+		{"single", []int{7}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mocks := make([]*notify.MockNotifier, len(tt.weights))
+			w := &notify.WeightedNotifier{Rand: notify.NewSeededRand(1)}
+			total := 0
+			for i, weight := range tt.weights {
+				mocks[i] = &notify.MockNotifier{}
+				w.Entries = append(w.Entries, notify.WeightedEntry{Notifier: mocks[i], Weight: weight})
+				total += max(weight, 0)
+			}
+
+			for range calls {
+				if err := w.Notify(context.Background()); err != nil {
+					t.Fatalf("Notify() = %v", err)
+				}
+			}
+			for i, m := range mocks {
+				want := float64(calls) * float64(max(tt.weights[i], 0)) / float64(total)
+				// Allow 5% of all calls either side of the expected share.
+				if got := float64(m.Calls()); got < want-calls*0.05 || got > want+calls*0.05 {
+					t.Errorf("entry %d (weight %d) chosen %v times, want about %v", i, tt.weights[i], got, want)
+				}
+				if tt.weights[i] <= 0 && m.Calls() != 0 {
+					t.Errorf("entry %d (weight %d) chosen %d times, want never", i, tt.weights[i], m.Calls())
+				}
+			}
+		})
+	}
+}
+
+func TestWeightedNotifierDeterministic(t *testing.T) {
+	a, b, c := &notify.MockNotifier{Name: "a"}, &notify.MockNotifier{Name: "b"}, &notify.MockNotifier{Name: "c"}
+	picks := func(seed uint64) []notify.Notifier {
+		w := &notify.WeightedNotifier{
+			Entries: []notify.WeightedEntry{{Notifier: a, Weight: 1}, {Notifier: b, Weight: 2}, {Notifier: c, Weight: 3}},
+			Rand:    notify.NewSeededRand(seed),
+		}
+		var out []notify.Notifier
+		for range 50 {
+			n, err := w.Pick()
+			if err != nil {
+				t.Fatal(err)
+			}
+			out = append(out, n)
+		}
+		return out
+	}
+
+	first, second := picks(42), picks(42)
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("pick %d differs between runs with the same seed", i)
+		}
+	}
+}
+
+func TestWeightedNotifierNoWeight(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []notify.WeightedEntry
+	}{
+		{"no entries", nil},
+		{"all zero", []notify.WeightedEntry{{Notifier: &notify.MockNotifier{}, Weight: 0}, {Notifier: &notify.MockNotifier{}, Weight: 0}}},
+		{"negative", []notify.WeightedEntry{{Notifier: &notify.MockNotifier{}, Weight: -1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &notify.WeightedNotifier{Entries: tt.entries}
+			if err := w.Notify(context.Background()); !errors.Is(err, notify.ErrNoWeight) {
+				t.Errorf("Notify() = %v, want %v", err, notify.ErrNoWeight)
+			}
+		})
+	}
+}
+
+func TestWeightedNotifierConcurrent(t *testing.T) {
+	mock := &notify.MockNotifier{}
+	w := &notify.WeightedNotifier{
+		Entries: []notify.WeightedEntry{{Notifier: mock, Weight: 1}, {Notifier: mock, Weight: 1}},
+		Rand:    notify.NewSeededRand(1),
+	}
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				w.Notify(context.Background())
+			}
+		}()
+	}
+	wg.Wait()
+	if mock.Calls() != 800 {
+		t.Errorf("calls = %d, want 800", mock.Calls())
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"ultimate-golang-reference/interface-embedding/notify"
+)
+
+// simStart is when every simulation begins, and simQuiet when the
+// recipient's quiet hours start a few minutes later.
+var (
+	simStart = time.Date(2026, time.March, 2, 21, 55, 0, 0, time.UTC)
+	simQuiet = time.Date(2026, time.March, 2, 22, 0, 0, 0, time.UTC)
+)
+
+// simulation runs a scripted scenario against the notify wrappers on a
+// simulated clock and a seeded random source, logging each event with
+// its simulated time. Nothing waits in real time and nothing runs in the
+// background, so the same seed always produces the same log.
+type simulation struct {
+	out   io.Writer
+	clock *notify.SimClock
+	rng   notify.Rand
+
+	sent, failed, skipped int
+}
+
+// runSimulation runs the -simulate scenario with seed.
+func runSimulation(out io.Writer, seed uint64) error {
+	s := &simulation{out: out, clock: notify.NewSimClock(simStart), rng: notify.NewSeededRand(seed)}
+
+	to, err := notify.NewUser("Janet Jones", "janet@email.com",
+		notify.WithWriter(io.Discard),
+		notify.WithLogger(slog.New(slog.DiscardHandler)),
+	)
+	if err != nil {
+		return err
+	}
+	gateway := &simGateway{sim: s, to: to, failPercent: 30}
+
+	// The pipeline from the outside in: quiet hours, circuit breaker, rate
+	// limit, retry with seeded jitter, then the flaky gateway.
+	var policy notify.Policy
+	quiet, err := notify.ParseQuietHours("22:00-07:00")
+	if err != nil {
+		return err
+	}
+	policy.SetQuietHours(to.Email, quiet)
+	pipeline := notify.NewPolicyNotifier(
+		notify.NewCircuitBreaker(
+			notify.NewRateLimitedNotifier(
+				&notify.RetryNotifier{
+					Notifier:    gateway,
+					MaxAttempts: 3,
+					Backoff:     notify.JitterBackoff(200*time.Millisecond, s.rng),
+					Clock:       s.clock,
+				},
+				2, 3, notify.WithRateLimitClock(s.clock)),
+			notify.WithFailureThreshold(3),
+			notify.WithCooldown(time.Minute),
+			notify.WithBreakerClock(s.clock)),
+		&policy, notify.QuietReject, s.clock)
+
+	s.logf("seed %d", seed)
+
+	s.logf("phase: burst of 6, rate limited to 2/s with bursts of 3")
+	for range 6 {
+		s.send(pipeline)
+	}
+
+	s.logf("phase: gateway outage")
+	gateway.failPercent = 100
+	for range 4 {
+		s.send(pipeline)
+	}
+
+	s.logf("phase: recovery after the breaker cooldown")
+	gateway.failPercent = 30
+	s.clock.Advance(time.Minute)
+	s.send(pipeline)
+
+	s.logf("phase: duplicates within a 2m window")
+	dedup := notify.NewDedupNotifier(pipeline, nil, 2*time.Minute,
+		notify.ReportDuplicates(), notify.WithDedupClock(s.clock))
+	s.send(dedup)
+	s.send(dedup)
+	s.clock.Advance(2 * time.Minute)
+	s.send(dedup)
+
+	s.logf("phase: quiet hours")
+	s.clock.Advance(simQuiet.Sub(s.clock.Now()))
+	s.send(pipeline)
+	if until, ok := policy.QuietUntil(pipeline, s.clock.Now()); ok {
+		s.clock.Advance(until.Sub(s.clock.Now()))
+	}
+	s.send(pipeline)
+
+	s.logf("done: %d sent, %d failed, %d skipped", s.sent, s.failed, s.skipped)
+	return nil
+}
+
+// send delivers through n and logs the outcome.
+func (s *simulation) send(n notify.Notifier) {
+	err := n.Notify(context.Background())
+	if errors.Is(err, notify.ErrDuplicateSuppressed) {
+		s.skipped++
+		s.logf("  skipped: duplicate")
+		return
+	}
+	if err != nil {
+		s.failed++
+		s.logf("  failed: %v", err)
+		return
+	}
+	s.sent++
+	s.logf("  delivered")
+}
+
+// logf writes one event, stamped with the simulated time.
+func (s *simulation) logf(format string, args ...any) {
+	fmt.Fprintf(s.out, "%s %s\n", s.clock.Now().Format("2006-01-02 15:04:05.000"), fmt.Sprintf(format, args...))
+}
+
+// simGateway is a delivery channel that takes a random 20-80ms and fails
+// failPercent of the time with a retryable error.
+type simGateway struct {
+	sim         *simulation
+	to          *notify.User
+	failPercent int64
+}
+
+func (g *simGateway) Notify(ctx context.Context) error {
+	g.sim.clock.Advance(time.Duration(20+g.sim.rng.Int64N(61)) * time.Millisecond)
+	if g.sim.rng.Int64N(100) < g.failPercent {
+		g.sim.logf("  attempt: gateway timeout")
+		return &notify.NotificationError{Recipient: g.to.Email, Channel: "email", Retryable: true, Err: errors.New("gateway timeout")}
+	}
+	g.sim.logf("  attempt: accepted")
+
+	return nil
+}
+
+func (g *simGateway) Address() (recipient, channel string) {
+	return g.to.Address()
+}
+
+// NotificationKey identifies the gateway's notification by recipient, so
+// repeats can be deduplicated.
+func (g *simGateway) NotificationKey() string {
+	return g.to.NotificationKey()
+}